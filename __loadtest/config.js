@@ -3,6 +3,11 @@ import { textSummary } from 'https://jslib.k6.io/k6-summary/0.0.1/index.js';
 
 export const summaryTrendStats = ['avg', 'min', 'med', 'max', 'p(50)', 'p(90)', 'p(95)', 'p(99)', 'p(99.9)', 'count'];
 export const baseURL = 'http://localhost:3300';
+// API key with the packs:read, packs:write and events:write scopes, e.g.: API_KEY=pmk_... k6 run packs.js
+export const headers = {
+  'Content-Type': 'application/json',
+  'X-API-Key': __ENV.API_KEY || '',
+};
 
 export function defaultSummary(data) {
   return {
@@ -2,7 +2,7 @@ import { check, group } from 'k6';
 import http from 'k6/http';
 import { Trend } from 'k6/metrics';
 import { generateRandomString, generateRandomDate } from './helpers.js';
-import { defaultSummary, summaryTrendStats, baseURL } from './config.js';
+import { defaultSummary, summaryTrendStats, baseURL, headers } from './config.js';
 
 function createPack() {
   const payload = {
@@ -16,9 +16,7 @@ function createPack() {
     baseURL + '/packs',
     JSON.stringify(payload),
     {
-      headers: {
-        'Content-Type': 'application/json',
-      },
+      headers: headers,
     }
   );
 
@@ -77,9 +75,7 @@ export default function (data) {
       baseURL + '/pack_events',
       JSON.stringify(payload),
       {
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: headers,
       }
     );
 
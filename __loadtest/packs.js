@@ -2,7 +2,7 @@ import { check, group } from 'k6';
 import http from 'k6/http';
 import { Trend } from 'k6/metrics';
 import { generateRandomString, generateRandomDate } from './helpers.js';
-import { defaultSummary, summaryTrendStats, baseURL } from './config.js';
+import { defaultSummary, summaryTrendStats, baseURL, headers } from './config.js';
 
 const createPackThread = new Trend('_create_pack_duration');
 const updatePackStatusThread = new Trend('_update_pack_status_duration');
@@ -58,9 +58,7 @@ export default function (data) {
       baseURL + '/packs',
       JSON.stringify(payload),
       {
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: headers,
       }
     );
 
@@ -92,9 +90,7 @@ export default function (data) {
       baseURL + '/packs/' + packId + '/cancel',
       null,
       {
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: headers,
       }
     );
 
@@ -118,9 +114,7 @@ export default function (data) {
       baseURL + '/packs/' + packId,
       JSON.stringify({ status: 'IN_TRANSIT' }),
       {
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: headers,
       }
     );
 
@@ -135,9 +129,7 @@ export default function (data) {
       baseURL + '/packs/' + packId,
       JSON.stringify({ status: 'DELIVERED' }),
       {
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: headers,
       }
     );
 
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/domain/carrier"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/config"
+	"pack-management/internal/pkg/logger"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	apiKeyCommand = "apikey"
+
+	apiKeyUsage = `Usage:
+  main apikey create --tenant <id> [--carrier <id>] --name <name> --scopes <scope,...> [--config <file>]
+  main apikey list [--tenant <id>] [--config <file>]
+  main apikey revoke --id <id> [--config <file>]
+
+Scopes: packs:read, packs:write, events:write, metrics:read, admin, the carrier keys only events:write
+`
+)
+
+// runAPIKeyCommand mints, lists and revokes the API keys, returning the exit
+// code. The minted key is printed once, only its hash is saved.
+func runAPIKeyCommand(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, apiKeyUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet(apiKeyCommand+" "+args[0], flag.ContinueOnError)
+	configFile := flags.String("config", "", "YAML or TOML config file, env variables override its values")
+	tenantID := flags.String("tenant", "", "tenant id")
+	carrierID := flags.String("carrier", "", "carrier id of the tenant, the key may only post the events of its packs")
+	name := flags.String("name", "", "key name, e.g.: the client using it")
+	scopes := flags.String("scopes", "", "scopes separated by commas")
+	id := flags.String("id", "", "key id")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.NewConfig(&config.Params{
+		File: *configFile,
+	})
+	if err != nil {
+		slog.Error("Config error", logger.Error(err))
+		return 1
+	}
+
+	db, err := connectDatabase(cfg)
+	if err != nil {
+		slog.Error("Database connection error", logger.Error(err))
+		return 1
+	}
+	defer db.Close()
+
+	authSvc := newAuthService(db, cfg.Auth)
+
+	switch args[0] {
+	case "create":
+		_, err = tenant.NewService(&tenant.ServiceParams{
+			Repo: tenant.NewMysqlRepository(&tenant.RepositoryParams{
+				DB: db,
+			}),
+		}).GetByID(ctx, *tenantID)
+		if err != nil {
+			break
+		}
+
+		if *carrierID != "" {
+			_, err = carrier.NewService(&carrier.ServiceParams{
+				Repo: carrier.NewMysqlRepository(&carrier.RepositoryParams{
+					DB: db,
+				}),
+			}).GetCarrierByID(tenant.WithContext(ctx, *tenantID), *carrierID)
+			if err != nil {
+				break
+			}
+		}
+
+		var parsed []auth.Scope
+
+		parsed, err = auth.ParseScopes(*scopes)
+		if err != nil {
+			break
+		}
+
+		var (
+			apiKey *auth.APIKey
+			key    string
+		)
+
+		apiKey, key, err = authSvc.CreateAPIKey(ctx, &auth.CreateAPIKeyParams{
+			TenantID:  *tenantID,
+			CarrierID: *carrierID,
+			Name:      *name,
+			Scopes:    parsed,
+		})
+		if err != nil {
+			break
+		}
+
+		fmt.Printf("id: %s\ntenant: %s\nscopes: %s\nkey: %s\n", apiKey.ID, apiKey.TenantID, auth.JoinScopes(apiKey.Scopes), key)
+		fmt.Fprintln(os.Stderr, "Save the key now, it can't be shown again.")
+	case "list":
+		var keys []*auth.APIKey
+
+		keys, err = authSvc.ListAPIKeys(ctx, *tenantID)
+		if err != nil {
+			break
+		}
+
+		printAPIKeys(os.Stdout, keys)
+	case "revoke":
+		err = authSvc.RevokeAPIKey(ctx, *id)
+		if err != nil {
+			break
+		}
+
+		fmt.Printf("revoked: %s\n", *id)
+	default:
+		fmt.Fprint(os.Stderr, apiKeyUsage)
+		return 2
+	}
+
+	if err != nil {
+		slog.Error("API key command error", logger.Error(err))
+		return 1
+	}
+
+	return 0
+}
+
+func printAPIKeys(w io.Writer, keys []*auth.APIKey) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer table.Flush()
+
+	fmt.Fprintln(table, "ID\tTENANT\tCARRIER\tNAME\tSCOPES\tCREATED AT\tREVOKED AT")
+
+	for _, key := range keys {
+		carrierID := "-"
+		if key.CarrierID != nil {
+			carrierID = *key.CarrierID
+		}
+
+		revokedAt := "-"
+		if key.RevokedAt != nil {
+			revokedAt = key.RevokedAt.UTC().Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			key.ID, key.TenantID, carrierID, key.Name, auth.JoinScopes(key.Scopes), key.CreatedAt.UTC().Format(time.RFC3339), revokedAt)
+	}
+}
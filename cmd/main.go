@@ -2,58 +2,159 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/domain/carrier"
+	"pack-management/internal/domain/health"
 	"pack-management/internal/domain/holiday"
+	"pack-management/internal/domain/job"
 	"pack-management/internal/domain/metric"
 	"pack-management/internal/domain/pack"
 	"pack-management/internal/domain/packevent"
 	"pack-management/internal/domain/person"
+	"pack-management/internal/domain/search"
+	"pack-management/internal/domain/stats"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/alert"
 	"pack-management/internal/pkg/config"
 	"pack-management/internal/pkg/database"
+	"pack-management/internal/pkg/helpers"
 	"pack-management/internal/pkg/http/client"
 	"pack-management/internal/pkg/http/dogapi"
 	"pack-management/internal/pkg/http/nagerdateapi"
+	"pack-management/internal/pkg/idempotency"
+	"pack-management/internal/pkg/lifecycle"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/pagination"
+	"pack-management/internal/pkg/ratelimit"
+	"pack-management/internal/pkg/scheduler"
 	"pack-management/internal/pkg/setup"
+	"pack-management/internal/pkg/tracing"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/uptrace/bun"
 )
 
-const appPort = "3300"
+const (
+	migrationsTable = "migrations"
+)
 
 func main() {
 	ctx := context.Background()
 
-	cfg, err := config.NewConfig()
+	if len(os.Args) > 1 && os.Args[1] == apiKeyCommand {
+		os.Exit(runAPIKeyCommand(ctx, os.Args[2:]))
+	}
+
+	configFile := flag.String("config", "", "YAML or TOML config file, env variables override its values")
+	printConfig := flag.Bool("print-config", false, "print the effective config with the secrets redacted and exit")
+	flag.Parse()
+
+	cfg, err := config.NewConfig(&config.Params{
+		File: *configFile,
+	})
 	if err != nil {
-		log.Fatalf("Config error: %v", err)
+		slog.Error("Config error", logger.Error(err))
+		os.Exit(1)
 	}
 
-	db, err := database.NewDatabase(&database.Params{
-		DBHost:         cfg.DBHost,
-		DBPort:         cfg.DBPort,
-		DBName:         cfg.DBName,
-		DBUser:         cfg.DBUser,
-		DBPassword:     cfg.DBPassword,
-		ConnectionPool: database.WithPoolConfigHigh(),
-	}).Connect()
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			slog.Error("Config print error", logger.Error(err))
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	logger.NewLogger(&logger.Params{
+		Format: cfg.Logger.Format,
+		Level:  cfg.Logger.Level,
+	})
+
+	tracingProvider, err := tracing.NewProvider(ctx, &tracing.Params{
+		ServiceName: "pack-management",
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		slog.Error("Tracing setup error", logger.Error(err))
+		os.Exit(1)
+	}
+
+	db, err := connectDatabase(cfg)
 	if err != nil {
-		log.Fatalf("Database connection error: %v", err)
+		slog.Error("Database connection error", logger.Error(err))
+		os.Exit(1)
 	}
 
 	baseAPP := setup.NewApp()
 	fiberAPP := baseAPP.FiberApp()
 
+	rateLimitStore := newRateLimitStore(db, cfg.RateLimit)
+	if cfg.RateLimit.Enabled {
+		// before the authentication, so the guessed credentials and the
+		// unknown keys, each one a database query, are limited too
+		fiberAPP.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats", "/admin", "/metrics"}, ratelimit.Middleware(&ratelimit.MiddlewareParams{
+			Store:   rateLimitStore,
+			Name:    "ip",
+			Default: rateLimit(cfg.RateLimit.IP),
+			Key:     ratelimit.KeyByIP,
+		}))
+	}
+
+	fiberAPP.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats", "/admin", "/metrics"}, auth.Middleware(&auth.MiddlewareParams{
+		Service:  newAuthService(db, cfg.Auth),
+		Required: cfg.Auth.Required,
+	}))
+
+	if cfg.RateLimit.Enabled {
+		fiberAPP.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats", "/admin", "/metrics", "/track"}, ratelimit.Middleware(&ratelimit.MiddlewareParams{
+			Store:   rateLimitStore,
+			Default: rateLimit(cfg.RateLimit.Default),
+			Rules: []*ratelimit.Rule{
+				{Name: "bulk", Method: fiber.MethodPost, Prefix: "/packs/bulk", Limit: rateLimit(cfg.RateLimit.Bulk)},
+				{Name: "bulk", Method: fiber.MethodPost, Prefix: "/packs/import", Limit: rateLimit(cfg.RateLimit.Bulk)},
+				{Name: "export", Method: fiber.MethodGet, Prefix: "/packs/export", Limit: rateLimit(cfg.RateLimit.Export)},
+				{Name: "track", Method: fiber.MethodGet, Prefix: "/track", Limit: rateLimit(cfg.RateLimit.Track)},
+			},
+			Key: auth.ClientKey,
+		}))
+	}
+
+	tenantSvc := tenant.NewService(&tenant.ServiceParams{
+		Repo: tenant.NewMysqlRepository(&tenant.RepositoryParams{
+			DB: db,
+		}),
+		CacheTTL: cfg.Tenant.CacheTTL,
+	})
+	fiberAPP.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats"}, tenant.Middleware(&tenant.MiddlewareParams{
+		Service:       tenantSvc,
+		DefaultTenant: cfg.Tenant.Default,
+		HeaderEnabled: cfg.Tenant.HeaderEnabled,
+	}))
+
 	metric.NewHTPPHandler(&metric.HandlerParams{
 		App: fiberAPP,
 		DB:  db,
 	})
+	pack.RegisterMetrics(prometheus.DefaultRegisterer)
 
 	baseClient := client.NewClient()
 	dogAPIClient := dogapi.NewDogAPIClient(
 		baseClient,
-		"https://dogapi.dog/api/v2",
+		cfg.APIs.DogAPIURL,
 	)
 	nagerDateAPIClient := nagerdateapi.NewHolidayAPIClient(
 		baseClient,
-		"https://date.nager.at/api/v3",
+		cfg.APIs.NagerDateAPIURL,
 	)
 
 	holidayRepo := holiday.NewMysqlRepository(&holiday.RepositoryParams{
@@ -64,6 +165,16 @@ func main() {
 		Client: nagerDateAPIClient,
 	})
 
+	carrierSvc := carrier.NewService(&carrier.ServiceParams{
+		Repo: carrier.NewMysqlRepository(&carrier.RepositoryParams{
+			DB: db,
+		}),
+	})
+	carrier.NewHTPPHandler(&carrier.HandlerParams{
+		Service: carrierSvc,
+		App:     fiberAPP,
+	})
+
 	personRepo := person.NewMysqlRepository(&person.RepositoryParams{
 		DB: db,
 	})
@@ -73,31 +184,399 @@ func main() {
 
 	packRepo := pack.NewMysqlRepository(&pack.RepositoryParams{
 		DB: db,
+		CursorSigner: pagination.NewSigner(&pagination.SignerParams{
+			Secret: cfg.Pagination.CursorSecret,
+			TTL:    cfg.Pagination.CursorTTL,
+		}),
+		TrackingCodePrefix: cfg.Pack.TrackingCodePrefix,
 	})
+	idempotencyStore := idempotency.NewMysqlStore(&idempotency.StoreParams{
+		DB: db,
+	})
+	idempotencyMiddleware := idempotency.Middleware(&idempotency.MiddlewareParams{
+		Store:             idempotencyStore,
+		TTL:               cfg.Idempotency.TTL,
+		ProcessingTimeout: cfg.Idempotency.ProcessingTimeout,
+		Scope:             tenant.ScopeKey,
+	})
+
 	packSvc := pack.NewService(&pack.ServiceParams{
-		Repo:           packRepo,
-		PersonService:  personSvc,
-		DogAPIClient:   dogAPIClient,
-		HolidayService: holidaySvc,
+		Repo:            packRepo,
+		PersonService:   personSvc,
+		DogAPIClient:    dogAPIClient,
+		HolidayService:  holidaySvc,
+		TenantService:   tenantSvc,
+		CarrierService:  carrierSvc,
+		DefaultPageSize: cfg.Pack.DefaultPageSize,
+		MaxPageSize:     cfg.Pack.MaxPageSize,
+		AlertSink:       alertSink(cfg.Alert),
+		OverdueBatch:    cfg.Pack.OverdueBatch,
+		ExportPageSize:  cfg.Pack.ExportPageSize,
 	})
 	pack.NewHTPPHandler(&pack.HandlerParams{
-		Service: packSvc,
-		App:     fiberAPP,
+		Service:             packSvc,
+		App:                 fiberAPP,
+		CacheMaxAgeCreated:  cfg.Pack.CacheMaxAgeCreated,
+		CacheMaxAgeInFlight: cfg.Pack.CacheMaxAgeInFlight,
+		Idempotency:         idempotencyMiddleware,
+		BulkMaxRows:         cfg.Pack.BulkMaxRows,
 	})
 
 	packEventRepo := packevent.NewMysqlRepository(&packevent.RepositoryParams{
 		DB: db,
 	})
-	packEventSvc := packevent.NewService(ctx, &packevent.ServiceParams{
+	workersCtx, cancelWorkers := context.WithCancel(ctx)
+
+	packEventSvc := packevent.NewService(workersCtx, &packevent.ServiceParams{
 		Repo:        packEventRepo,
 		PackService: packSvc,
+		QueueBuffer: cfg.PackEvent.QueueBuffer,
 	})
 	packevent.NewHTPPHandler(&packevent.HandlerParams{
-		Service: packEventSvc,
-		App:     fiberAPP,
+		Service:     packEventSvc,
+		App:         fiberAPP,
+		Idempotency: idempotencyMiddleware,
+	})
+
+	search.NewHTPPHandler(&search.HandlerParams{
+		App: fiberAPP,
+		Service: search.NewService(&search.ServiceParams{
+			Repo: search.NewMysqlRepository(&search.RepositoryParams{
+				DB: db,
+			}),
+			DefaultLimit: cfg.Search.DefaultLimit,
+			MaxLimit:     cfg.Search.MaxLimit,
+		}),
+	})
+
+	stats.NewHTPPHandler(&stats.HandlerParams{
+		App: fiberAPP,
+		Service: stats.NewService(&stats.ServiceParams{
+			Repo: stats.NewMysqlRepository(&stats.RepositoryParams{
+				DB: db,
+			}),
+			MaxRangeDays: cfg.Stats.MaxRangeDays,
+		}),
+	})
+
+	jobStore := scheduler.NewMysqlStore(&scheduler.StoreParams{
+		DB: db,
+	})
+
+	jobScheduler := scheduler.NewScheduler(&scheduler.Params{
+		Locker: scheduler.NewMysqlLocker(&scheduler.LockerParams{
+			DB: db,
+		}),
+		Store:          jobStore,
+		Instance:       cfg.Scheduler.Instance,
+		DefaultTimeout: cfg.Scheduler.JobTimeout,
+	})
+
+	err = registerJobs(jobScheduler, cfg, &jobServices{
+		jobStore:         jobStore,
+		pack:             packSvc,
+		packEvent:        packEventSvc,
+		holiday:          holidaySvc,
+		tenant:           tenantSvc,
+		idempotencyStore: idempotencyStore,
+		rateLimitStore:   rateLimitStore,
+	})
+	if err != nil {
+		slog.Error("Scheduler setup error", logger.Error(err))
+		os.Exit(1)
+	}
+
+	job.NewHTPPHandler(&job.HandlerParams{
+		App:       fiberAPP,
+		Scheduler: jobScheduler,
+	})
+
+	if cfg.Scheduler.Enabled {
+		jobScheduler.Start(workersCtx)
+	}
+
+	healthChecks := []*health.Check{
+		{
+			Name: "database",
+			Run:  health.DatabaseCheck(db),
+		},
+		{
+			Name: "migrations",
+			Run:  health.MigrationCheck(db, migrationsTable, migrationsDir()),
+		},
+		{
+			Name:     "packevent_worker",
+			Run:      health.WorkerCheck(packEventSvc.LastHeartbeat, cfg.Health.WorkerHeartbeatMaxAge),
+			Liveness: true,
+		},
+		{
+			Name: "packevent_queue",
+			Run: health.QueueCheck(func() health.QueueStats {
+				length, capacity := packEventSvc.QueueStats()
+				return health.QueueStats{Length: length, Capacity: capacity}
+			}, cfg.Health.QueueSaturationThreshold),
+		},
+	}
+
+	if cfg.Health.CheckExternalAPIs {
+		healthClient := &http.Client{Timeout: cfg.Health.CheckTimeout}
+		healthChecks = append(healthChecks,
+			&health.Check{
+				Name:     "dogapi",
+				Run:      health.HTTPCheck(healthClient, cfg.APIs.DogAPIURL+"/facts?limit=1"),
+				Optional: true,
+			},
+			&health.Check{
+				Name:     "nagerdateapi",
+				Run:      health.HTTPCheck(healthClient, cfg.APIs.NagerDateAPIURL+"/AvailableCountries"),
+				Optional: true,
+			},
+		)
+	}
+
+	health.NewHTPPHandler(&health.HandlerParams{
+		App: fiberAPP,
+		Service: health.NewService(&health.ServiceParams{
+			Checks:  healthChecks,
+			Timeout: cfg.Health.CheckTimeout,
+		}),
+	})
+
+	lifecycleManager := lifecycle.NewManager(&lifecycle.Params{
+		Timeout: cfg.App.ShutdownTimeout,
 	})
+	lifecycleManager.Register("http_server", baseAPP.Shutdown)
+	lifecycleManager.Register("packevent_queue", packEventSvc.Shutdown)
+	lifecycleManager.Register("pack_enrichment", packSvc.Shutdown)
+	lifecycleManager.Register("scheduler", jobScheduler.Shutdown)
+	lifecycleManager.Register("workers", func(ctx context.Context) error {
+		cancelWorkers()
+		return nil
+	})
+	lifecycleManager.Register("tracing", tracingProvider.Shutdown)
+	lifecycleManager.Register("database", func(ctx context.Context) error {
+		return db.Close()
+	})
+
+	go baseAPP.Start(cfg.App.Port)
+
+	err = lifecycleManager.Wait(ctx)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+type jobServices struct {
+	jobStore         scheduler.Store
+	pack             pack.Service
+	packEvent        packevent.Service
+	holiday          holiday.Service
+	tenant           tenant.Service
+	idempotencyStore idempotency.Store
+	rateLimitStore   ratelimit.Store
+}
+
+func registerJobs(s scheduler.Scheduler, cfg *config.Config, services *jobServices) error {
+	jobs := []*scheduler.Job{
+		{
+			Name:     "overdue_detection",
+			Schedule: "@every " + cfg.Pack.OverdueScanInterval.String(),
+			Run: func(ctx context.Context) error {
+				_, err := services.pack.DetectOverdue(ctx)
+				return err
+			},
+		},
+		{
+			Name:     "holiday_prefetch",
+			Schedule: cfg.Scheduler.HolidayPrefetch,
+			Run: func(ctx context.Context) error {
+				nextYear := strconv.Itoa(time.Now().UTC().Year() + 1)
+
+				countries, err := services.tenant.ListHolidayCountries(ctx)
+				if err != nil {
+					return err
+				}
+
+				for _, country := range countries {
+					fetched, err := services.holiday.PrefetchYear(ctx, country, nextYear)
+					if err != nil {
+						return err
+					}
+
+					logger.FromContext(ctx).InfoContext(ctx, "holidays prefetched", "country", country, "year", nextYear, "count", fetched)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:     "pack_enrichment_retry",
+			Schedule: cfg.Scheduler.EnrichmentRetry,
+			Run: func(ctx context.Context) error {
+				retried, err := services.pack.RetryEnrichment(ctx)
+				if err != nil {
+					return err
+				}
+
+				logger.FromContext(ctx).InfoContext(ctx, "pack enrichment retried", "count", retried)
+				return nil
+			},
+		},
+		{
+			Name:     "pack_event_purge",
+			Schedule: cfg.Scheduler.EventPurge,
+			Run: func(ctx context.Context) error {
+				_, err := services.packEvent.PurgeEvents(ctx, cfg.PackEvent.Retention)
+				return err
+			},
+		},
+		{
+			Name:     "idempotency_key_purge",
+			Schedule: cfg.Scheduler.IdempotencyPurge,
+			Run: func(ctx context.Context) error {
+				purged, err := idempotency.PurgeExpired(ctx, services.idempotencyStore)
+				if err != nil {
+					return err
+				}
+
+				logger.FromContext(ctx).InfoContext(ctx, "idempotency keys purged", "count", purged)
+				return nil
+			},
+		},
+		{
+			Name:     "job_run_purge",
+			Schedule: cfg.Scheduler.RunPurge,
+			Run: func(ctx context.Context) error {
+				purged, err := scheduler.PurgeRuns(ctx, services.jobStore, cfg.Scheduler.RunRetention)
+				if err != nil {
+					return err
+				}
+
+				logger.FromContext(ctx).InfoContext(ctx, "job runs purged", "count", purged)
+				return nil
+			},
+		},
+		{
+			Name:     "tracking_code_backfill",
+			Schedule: cfg.Scheduler.TrackingBackfill,
+			Run: func(ctx context.Context) error {
+				backfilled, err := services.pack.BackfillTrackingCodes(ctx)
+				if err != nil {
+					return err
+				}
+
+				if backfilled > 0 {
+					logger.FromContext(ctx).InfoContext(ctx, "tracking codes backfilled", "count", backfilled)
+				}
+
+				return nil
+			},
+		},
+	}
+
+	// the memory store sweeps itself, the job would only purge the replica
+	// holding the job lock
+	if cfg.RateLimit.Store == "mysql" {
+		jobs = append(jobs, &scheduler.Job{
+			Name:     "rate_limit_purge",
+			Schedule: cfg.Scheduler.RateLimitPurge,
+			Run: func(ctx context.Context) error {
+				purged, err := ratelimit.PurgeExpired(ctx, services.rateLimitStore)
+				if err != nil {
+					return err
+				}
 
-	go baseAPP.Start(appPort)
+				logger.FromContext(ctx).InfoContext(ctx, "rate limit buckets purged", "count", purged)
+				return nil
+			},
+		})
+	}
+
+	for _, job := range jobs {
+		run := job.Run
+		// the jobs run across the tenants, see tenant.Scoped
+		job.Run = func(ctx context.Context) error {
+			return run(tenant.WithSystem(ctx))
+		}
+
+		err := s.Register(job)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func alertSink(cfg config.AlertConfig) alert.Sink {
+	sinks := []alert.Sink{}
+
+	for _, sink := range cfg.Sinks {
+		switch sink {
+		case "log":
+			sinks = append(sinks, alert.NewLogSink())
+		case "webhook":
+			sinks = append(sinks, alert.NewWebhookSink(&alert.WebhookParams{
+				URL:     cfg.WebhookURL,
+				Timeout: cfg.WebhookTimeout,
+			}))
+		}
+	}
+
+	return alert.NewMultiSink(sinks...)
+}
+
+func connectDatabase(cfg *config.Config) (*bun.DB, error) {
+	return database.NewDatabase(&database.Params{
+		DBHost:     cfg.Database.Host,
+		DBPort:     cfg.Database.Port,
+		DBName:     cfg.Database.Name,
+		DBUser:     cfg.Database.User,
+		DBPassword: cfg.Database.Password,
+		ConnectionPool: &database.ConnectionPool{
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			IdleConnsFactor: cfg.Database.IdleConnsFactor,
+		},
+	}).Connect()
+}
+
+func newAuthService(db *bun.DB, cfg config.AuthConfig) auth.Service {
+	return auth.NewService(&auth.ServiceParams{
+		Repo: auth.NewMysqlRepository(&auth.RepositoryParams{
+			DB: db,
+		}),
+		JWTSecret:   cfg.JWTSecret,
+		JWTIssuer:   cfg.JWTIssuer,
+		JWTAudience: cfg.JWTAudience,
+		CacheTTL:    cfg.CacheTTL,
+	})
+}
+
+// newRateLimitStore returns the store of the rate limit buckets, the mysql
+// store shares them by the replicas.
+func newRateLimitStore(db *bun.DB, cfg config.RateLimitConfig) ratelimit.Store {
+	if cfg.Store == "mysql" {
+		return ratelimit.NewMysqlStore(&ratelimit.StoreParams{
+			DB: db,
+		})
+	}
+
+	return ratelimit.NewMemoryStore()
+}
+
+func rateLimit(rule config.RateLimitRule) ratelimit.Limit {
+	return ratelimit.Limit{
+		Requests: rule.Requests,
+		Period:   rule.Period,
+		Burst:    rule.Burst,
+	}
+}
+
+func migrationsDir() string {
+	rootDir, err := helpers.GetRootDirectory()
+	if err != nil {
+		return ""
+	}
 
-	baseAPP.Shutdown(ctx)
+	return rootDir + "scripts/db/migrations"
 }
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+)
+
+type (
+	contextKey struct{}
+)
+
+// WithPrincipal returns a copy of ctx with the authenticated principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of ctx or nil when the request
+// was not authenticated.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(contextKey{}).(*Principal)
+
+	return principal
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+type (
+	Scope string
+
+	// APIKey is a credential of a tenant, only the SHA-256 of the key is
+	// saved, the key itself is shown once when it's created. CarrierID is
+	// set on the keys of a carrier.
+	APIKey struct {
+		ID        string
+		TenantID  string
+		CarrierID *string
+		Name      string
+		Scopes    []Scope
+		CreatedAt time.Time
+		RevokedAt *time.Time
+	}
+
+	// Principal is the authenticated caller of a request. TenantID is empty
+	// for the tokens not bound to a tenant, CarrierID is set for the
+	// carriers, which may only post the events of their packs.
+	Principal struct {
+		Subject   string
+		TenantID  string
+		CarrierID string
+		Scopes    []Scope
+	}
+)
+
+var (
+	ScopePacksRead   Scope = "packs:read"
+	ScopePacksWrite  Scope = "packs:write"
+	ScopeEventsWrite Scope = "events:write"
+	// ScopeMetricsRead only reads the metrics, meant for the scrapers.
+	ScopeMetricsRead Scope = "metrics:read"
+	// ScopeAdmin grants every scope.
+	ScopeAdmin Scope = "admin"
+
+	scopes = []Scope{
+		ScopePacksRead,
+		ScopePacksWrite,
+		ScopeEventsWrite,
+		ScopeMetricsRead,
+		ScopeAdmin,
+	}
+
+	// carrierScopes are the only scopes a carrier may be granted.
+	carrierScopes = []Scope{
+		ScopeEventsWrite,
+	}
+)
+
+func (s Scope) IsValid() bool {
+	return slices.Contains(scopes, s)
+}
+
+// ParseScopes parses the scopes separated by commas or spaces, e.g.:
+// "packs:read,packs:write" or the "scope" claim "packs:read packs:write".
+func ParseScopes(value string) ([]Scope, error) {
+	parsed := []Scope{}
+
+	for _, name := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		scope := Scope(name)
+		if !scope.IsValid() {
+			return nil, ErrInvalidScope
+		}
+
+		if !slices.Contains(parsed, scope) {
+			parsed = append(parsed, scope)
+		}
+	}
+
+	if len(parsed) == 0 {
+		return nil, ErrInvalidScope
+	}
+
+	return parsed, nil
+}
+
+func JoinScopes(scopes []Scope) string {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// HasScope reports if the principal was granted the scope or admin, the
+// carriers only have the carrier scopes they were granted.
+func (p *Principal) HasScope(scope Scope) bool {
+	if p.IsCarrier() {
+		return slices.Contains(carrierScopes, scope) && slices.Contains(p.Scopes, scope)
+	}
+
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// IsCarrier reports if the principal is a carrier.
+func (p *Principal) IsCarrier() bool {
+	return p.CarrierID != ""
+}
+
+func (e *APIKey) ToModel() *Model {
+	if e == nil {
+		return nil
+	}
+
+	return &Model{
+		ID:        e.ID,
+		TenantID:  e.TenantID,
+		CarrierID: e.CarrierID,
+		Name:      e.Name,
+		Scopes:    JoinScopes(e.Scopes),
+		CreatedAt: e.CreatedAt,
+		RevokedAt: e.RevokedAt,
+	}
+}
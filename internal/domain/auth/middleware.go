@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"errors"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/validator"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	MiddlewareParams struct {
+		Service Service `validate:"required"`
+		// Required rejects the requests without credentials, when false they
+		// are let through as an anonymous principal granted every scope,
+		// meant for the local development only.
+		Required bool
+	}
+)
+
+const (
+	HeaderAPIKey = "X-API-Key"
+
+	bearerPrefix = "Bearer "
+)
+
+var (
+	anonymous = &Principal{
+		Subject: "anonymous",
+		Scopes:  []Scope{ScopeAdmin},
+	}
+)
+
+// Middleware authenticates the request by the X-API-Key header or the
+// Authorization bearer token and stores the principal in the fiber user
+// context, the routes check its scopes with RequireScope.
+func Middleware(params *MiddlewareParams) fiber.Handler {
+	params.validate()
+
+	return func(ctx *fiber.Ctx) error {
+		reqCtx := ctx.UserContext()
+
+		var (
+			principal *Principal
+			err       error
+		)
+
+		authorization := ctx.Get(fiber.HeaderAuthorization)
+
+		switch {
+		case ctx.Get(HeaderAPIKey) != "":
+			principal, err = params.Service.AuthenticateAPIKey(reqCtx, ctx.Get(HeaderAPIKey))
+		case strings.HasPrefix(authorization, bearerPrefix):
+			principal, err = params.Service.AuthenticateToken(reqCtx, strings.TrimPrefix(authorization, bearerPrefix))
+		case !params.Required:
+			principal = anonymous
+		default:
+			return errorHandler(ctx, ErrUnauthenticated)
+		}
+
+		if err != nil {
+			return errorHandler(ctx, err)
+		}
+
+		reqCtx = WithPrincipal(reqCtx, principal)
+		reqCtx = logger.With(reqCtx, logger.SubjectKey, principal.Subject)
+		ctx.SetUserContext(reqCtx)
+
+		return ctx.Next()
+	}
+}
+
+// RequireScope rejects the requests whose principal lacks any of the scopes.
+func RequireScope(scopes ...Scope) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		principal := PrincipalFromContext(ctx.UserContext())
+		if principal == nil {
+			return errorHandler(ctx, ErrUnauthenticated)
+		}
+
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				return errorHandler(ctx, ErrInsufficientScope)
+			}
+		}
+
+		return ctx.Next()
+	}
+}
+
+// ClientKey identifies the client of the request for the per client limits,
+// e.g.: the rate limiter, by the authenticated subject or the IP of the
+// anonymous requests.
+func ClientKey(ctx *fiber.Ctx) string {
+	principal := PrincipalFromContext(ctx.UserContext())
+	if principal == nil || principal == anonymous {
+		return "ip:" + ctx.IP()
+	}
+
+	return "subject:" + principal.Subject
+}
+
+func (p *MiddlewareParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// errorHandler sets the WWW-Authenticate header of the unauthenticated
+// requests, the error handler of the app writes the error.
+func errorHandler(ctx *fiber.Ctx, err error) error {
+	var cerr *cerrors.Error
+	if errors.As(err, &cerr) && cerr.Category == cerrors.CategoryUnauthenticated {
+		ctx.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="pack-management"`)
+	}
+
+	return err
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	Repository interface {
+		Create(ctx context.Context, key *APIKey, hash string) error
+		GetActiveByHash(ctx context.Context, hash string) (*APIKey, error)
+		List(ctx context.Context, tenantID string) ([]*APIKey, error)
+		Revoke(ctx context.Context, ID string) error
+	}
+
+	Model struct {
+		bun.BaseModel `bun:"table:api_key,alias:api_key"`
+		ID            string     `bun:"id,pk"`
+		TenantID      string     `bun:"tenant_id"`
+		CarrierID     *string    `bun:"carrier_id"`
+		Name          string     `bun:"name"`
+		KeyHash       string     `bun:"key_hash"`
+		Scopes        string     `bun:"scopes"`
+		CreatedAt     time.Time  `bun:"created_at"`
+		RevokedAt     *time.Time `bun:"revoked_at"`
+	}
+)
+
+var idPrefix = "apikey_"
+
+func (m *Model) ToEntity() *APIKey {
+	if m == nil {
+		return nil
+	}
+
+	scopes := []Scope{}
+	for _, name := range strings.Split(m.Scopes, ",") {
+		scopes = append(scopes, Scope(name))
+	}
+
+	return &APIKey{
+		ID:        m.ID,
+		TenantID:  m.TenantID,
+		CarrierID: m.CarrierID,
+		Name:      m.Name,
+		Scopes:    scopes,
+		CreatedAt: m.CreatedAt,
+		RevokedAt: m.RevokedAt,
+	}
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pack-management/internal/pkg/uuid"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	RepositoryParams struct {
+		DB *bun.DB `validate:"required"`
+	}
+
+	mysqlRepository struct {
+		db *bun.DB
+	}
+)
+
+func NewMysqlRepository(params *RepositoryParams) Repository {
+	params.validate()
+
+	return &mysqlRepository{
+		db: params.DB,
+	}
+}
+
+func (p *RepositoryParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (r *mysqlRepository) Create(ctx context.Context, key *APIKey, hash string) error {
+	key.ID = idPrefix + uuid.New().String()
+	key.CreatedAt = time.Now()
+
+	model := key.ToModel()
+	model.KeyHash = hash
+
+	_, err := r.db.NewInsert().Model(model).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetActiveByHash finds the not revoked key by the SHA-256 hex of the key.
+func (r *mysqlRepository) GetActiveByHash(ctx context.Context, hash string) (*APIKey, error) {
+	key := Model{}
+
+	err := r.db.NewSelect().
+		Model(&key).
+		Where("api_key.key_hash = ?", hash).
+		Where("api_key.revoked_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return key.ToEntity(), nil
+}
+
+// List lists the keys of the tenant, the revoked ones included, the oldest
+// first, all tenants when tenantID is empty.
+func (r *mysqlRepository) List(ctx context.Context, tenantID string) ([]*APIKey, error) {
+	models := []*Model{}
+
+	query := r.db.NewSelect().
+		Model(&models).
+		Order("api_key.created_at", "api_key.id")
+
+	if tenantID != "" {
+		query.Where("api_key.tenant_id = ?", tenantID)
+	}
+
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*APIKey, 0, len(models))
+	for _, model := range models {
+		keys = append(keys, model.ToEntity())
+	}
+
+	return keys, nil
+}
+
+// Revoke sets the revoked_at of the key, a key already revoked keeps its
+// date, failing with ErrAPIKeyNotFound when it doesn't exist.
+func (r *mysqlRepository) Revoke(ctx context.Context, ID string) error {
+	exists, err := r.db.NewSelect().
+		Model((*Model)(nil)).
+		Where("api_key.id = ?", ID).
+		Exists(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrAPIKeyNotFound
+	}
+
+	_, err = r.db.NewUpdate().
+		Model((*Model)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("id = ?", ID).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/validator"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type (
+	Service interface {
+		AuthenticateAPIKey(ctx context.Context, key string) (*Principal, error)
+		AuthenticateToken(ctx context.Context, token string) (*Principal, error)
+		CreateAPIKey(ctx context.Context, params *CreateAPIKeyParams) (*APIKey, string, error)
+		ListAPIKeys(ctx context.Context, tenantID string) ([]*APIKey, error)
+		RevokeAPIKey(ctx context.Context, ID string) error
+	}
+
+	service struct {
+		repo        Repository
+		jwtSecret   []byte
+		jwtIssuer   string
+		jwtAudience string
+		cacheTTL    time.Duration
+		mutex       sync.RWMutex
+		cache       map[string]*cachedPrincipal
+	}
+
+	ServiceParams struct {
+		Repo Repository `validate:"required"`
+		// JWTSecret is the HS256 key of the bearer tokens, optional, the
+		// tokens are rejected when it's empty.
+		JWTSecret   string `validate:"omitempty,min=32"`
+		JWTIssuer   string
+		JWTAudience string
+		// CacheTTL is how long an authenticated API key is kept in memory,
+		// so a revoked key may be accepted by the other replicas for up to
+		// it, optional, 1m by default.
+		CacheTTL time.Duration `validate:"gte=0"`
+	}
+
+	// CreateAPIKeyParams CarrierID is optional, the carrier keys may only
+	// have the events:write scope.
+	CreateAPIKeyParams struct {
+		TenantID  string `validate:"required"`
+		CarrierID string
+		Name      string  `validate:"required,max=255"`
+		Scopes    []Scope `validate:"required,min=1"`
+	}
+
+	tokenClaims struct {
+		TenantID  string `json:"tenant_id"`
+		CarrierID string `json:"carrier_id"`
+		Scope     string `json:"scope"`
+		jwt.RegisteredClaims
+	}
+
+	cachedPrincipal struct {
+		principal *Principal
+		expiresAt time.Time
+	}
+)
+
+var (
+	ErrUnauthenticated   = cerrors.New("the request is not authenticated, inform the X-API-Key or Authorization header", cerrors.CodeUnauthenticated)
+	ErrInvalidAPIKey     = cerrors.New("the informed api key is invalid or revoked", cerrors.CodeInvalidAPIKey)
+	ErrInvalidToken      = cerrors.New("the informed bearer token is invalid or expired", cerrors.CodeInvalidToken)
+	ErrInsufficientScope = cerrors.New("the credential lacks the scope required by this route", cerrors.CodeInsufficientScope)
+	ErrInvalidScope      = cerrors.New("the scopes are invalid, use packs:read, packs:write, events:write, metrics:read or admin", cerrors.CodeInvalidScope)
+	ErrAPIKeyNotFound    = cerrors.New("the informed api key was not found", cerrors.CodeAPIKeyNotFound)
+)
+
+const (
+	defaultCacheTTL = time.Minute
+
+	apiKeyPrefix = "pmk_"
+	apiKeyBytes  = 32
+)
+
+func NewService(params *ServiceParams) Service {
+	params.validate()
+
+	if params.CacheTTL == 0 {
+		params.CacheTTL = defaultCacheTTL
+	}
+
+	return &service{
+		repo:        params.Repo,
+		jwtSecret:   []byte(params.JWTSecret),
+		jwtIssuer:   params.JWTIssuer,
+		jwtAudience: params.JWTAudience,
+		cacheTTL:    params.CacheTTL,
+		cache:       map[string]*cachedPrincipal{},
+	}
+}
+
+func (p *ServiceParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// AuthenticateAPIKey finds the active key, only its SHA-256 is stored.
+func (s *service) AuthenticateAPIKey(ctx context.Context, key string) (*Principal, error) {
+	hash := HashAPIKey(key)
+	now := time.Now()
+
+	s.mutex.RLock()
+	cached, ok := s.cache[hash]
+	s.mutex.RUnlock()
+
+	if ok && cached.expiresAt.After(now) {
+		return cached.principal, nil
+	}
+
+	apiKey, err := s.repo.GetActiveByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	principal := &Principal{
+		Subject:  apiKey.ID,
+		TenantID: apiKey.TenantID,
+		Scopes:   apiKey.Scopes,
+	}
+
+	if apiKey.CarrierID != nil {
+		principal.CarrierID = *apiKey.CarrierID
+	}
+
+	s.mutex.Lock()
+	s.cache[hash] = &cachedPrincipal{principal: principal, expiresAt: now.Add(s.cacheTTL)}
+	s.mutex.Unlock()
+
+	return principal, nil
+}
+
+// AuthenticateToken validates the HS256 signature, expiration, issuer and
+// audience of the token, its "scope" claim holds the scopes separated by
+// spaces, the optional "tenant_id" claim binds it to a tenant and the
+// "carrier_id" one to a carrier of the tenant.
+func (s *service) AuthenticateToken(ctx context.Context, token string) (*Principal, error) {
+	if len(s.jwtSecret) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+
+	if s.jwtIssuer != "" {
+		options = append(options, jwt.WithIssuer(s.jwtIssuer))
+	}
+
+	if s.jwtAudience != "" {
+		options = append(options, jwt.WithAudience(s.jwtAudience))
+	}
+
+	claims := &tokenClaims{}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return s.jwtSecret, nil
+	}, options...)
+	if err != nil || claims.Subject == "" || (claims.CarrierID != "" && claims.TenantID == "") {
+		return nil, ErrInvalidToken
+	}
+
+	scopes, err := ParseScopes(claims.Scope)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{
+		Subject:   claims.Subject,
+		TenantID:  claims.TenantID,
+		CarrierID: claims.CarrierID,
+		Scopes:    scopes,
+	}, nil
+}
+
+// CreateAPIKey mints a key for the tenant, the returned key is not saved, so
+// it can't be shown again.
+func (s *service) CreateAPIKey(ctx context.Context, params *CreateAPIKeyParams) (*APIKey, string, error) {
+	err := validator.ValidateStruct(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, scope := range params.Scopes {
+		if !scope.IsValid() || (params.CarrierID != "" && !slices.Contains(carrierScopes, scope)) {
+			return nil, "", ErrInvalidScope
+		}
+	}
+
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+
+	apiKey := &APIKey{
+		TenantID: params.TenantID,
+		Name:     params.Name,
+		Scopes:   params.Scopes,
+	}
+
+	if params.CarrierID != "" {
+		apiKey.CarrierID = &params.CarrierID
+	}
+
+	err = s.repo.Create(ctx, apiKey, HashAPIKey(key))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+func (s *service) ListAPIKeys(ctx context.Context, tenantID string) ([]*APIKey, error) {
+	return s.repo.List(ctx, tenantID)
+}
+
+// RevokeAPIKey revokes the key and drops it from the cache of this replica.
+func (s *service) RevokeAPIKey(ctx context.Context, ID string) error {
+	err := s.repo.Revoke(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	for hash, cached := range s.cache {
+		if cached.principal.Subject == ID {
+			delete(s.cache, hash)
+		}
+	}
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// HashAPIKey returns the SHA-256 hex of the key, as saved in key_hash.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,31 @@
+package carrier
+
+import (
+	"time"
+)
+
+type (
+	// Entity is a delivery company of a tenant, its credentials may only post
+	// the events of the packs assigned to it.
+	Entity struct {
+		ID        string
+		TenantID  string
+		Name      string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+)
+
+func (e *Entity) ToModel() *Model {
+	if e == nil {
+		return nil
+	}
+
+	return &Model{
+		ID:        e.ID,
+		TenantID:  e.TenantID,
+		Name:      e.Name,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
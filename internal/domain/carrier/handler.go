@@ -0,0 +1,123 @@
+package carrier
+
+import (
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	handler struct {
+		service Service
+		app     *fiber.App
+	}
+
+	HandlerParams struct {
+		App     *fiber.App `validate:"required"`
+		Service Service    `validate:"required"`
+	}
+
+	CreateCarrierRequest struct {
+		Name string `json:"name" validate:"required,max=255"`
+	}
+
+	CarrierIDParam struct {
+		ID string `params:"id"`
+	}
+
+	CarrierJSON struct {
+		ID        string    `json:"id"`
+		Name      string    `json:"name"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	ListCarrierJSON struct {
+		Items []*CarrierJSON `json:"items"`
+	}
+)
+
+func NewHTPPHandler(params *HandlerParams) *handler {
+	params.validate()
+
+	h := &handler{
+		service: params.Service,
+		app:     params.App,
+	}
+
+	group := h.app.Group("/carriers")
+	group.Post("/", auth.RequireScope(auth.ScopeAdmin), h.createCarrier)
+	group.Get("/", auth.RequireScope(auth.ScopePacksRead), h.listCarriers)
+	group.Get("/:id", auth.RequireScope(auth.ScopePacksRead), h.getCarrierByID)
+
+	return h
+}
+
+func (p *HandlerParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (h *handler) createCarrier(ctx *fiber.Ctx) error {
+	payload := &CreateCarrierRequest{}
+	if err := ctx.BodyParser(payload); err != nil {
+		return cerrors.ErrInvalidBody
+	}
+
+	err := validator.ValidateStruct(payload)
+	if err != nil {
+		return err
+	}
+
+	carrier, err := h.service.CreateCarrier(ctx.UserContext(), &Entity{Name: payload.Name})
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(carrierEntityToJSON(carrier))
+}
+
+func (h *handler) listCarriers(ctx *fiber.Ctx) error {
+	carriers, err := h.service.ListCarriers(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	resp := &ListCarrierJSON{
+		Items: make([]*CarrierJSON, 0, len(carriers)),
+	}
+
+	for _, carrier := range carriers {
+		resp.Items = append(resp.Items, carrierEntityToJSON(carrier))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *handler) getCarrierByID(ctx *fiber.Ctx) error {
+	params := &CarrierIDParam{}
+	if err := ctx.ParamsParser(params); err != nil {
+		return cerrors.ErrInvalidParams
+	}
+
+	carrier, err := h.service.GetCarrierByID(ctx.UserContext(), params.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(carrierEntityToJSON(carrier))
+}
+
+func carrierEntityToJSON(carrier *Entity) *CarrierJSON {
+	return &CarrierJSON{
+		ID:        carrier.ID,
+		Name:      carrier.Name,
+		CreatedAt: carrier.CreatedAt,
+		UpdatedAt: carrier.UpdatedAt,
+	}
+}
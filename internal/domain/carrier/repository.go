@@ -0,0 +1,43 @@
+package carrier
+
+import (
+	"context"
+	"pack-management/internal/domain/tenant"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	Repository interface {
+		Create(ctx context.Context, carrier *Entity) error
+		GetByID(ctx context.Context, ID string) (*Entity, error)
+		List(ctx context.Context) ([]*Entity, error)
+	}
+
+	Model struct {
+		bun.BaseModel `bun:"table:carrier,alias:carrier"`
+		ID            string    `bun:"id,pk"`
+		TenantID      string    `bun:"tenant_id"`
+		Name          string    `bun:"name"`
+		CreatedAt     time.Time `bun:"created_at"`
+		UpdatedAt     time.Time `bun:"updated_at"`
+		tenant.Scoped
+	}
+)
+
+var idPrefix = "carrier_"
+
+func (m *Model) ToEntity() *Entity {
+	if m == nil {
+		return nil
+	}
+
+	return &Entity{
+		ID:        m.ID,
+		TenantID:  m.TenantID,
+		Name:      m.Name,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
@@ -0,0 +1,113 @@
+package carrier
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/uuid"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/uptrace/bun"
+)
+
+type (
+	RepositoryParams struct {
+		DB *bun.DB `validate:"required"`
+	}
+
+	mysqlRepository struct {
+		db *bun.DB
+	}
+)
+
+const (
+	mysqlDuplicateEntry = 1062
+)
+
+func NewMysqlRepository(params *RepositoryParams) Repository {
+	params.validate()
+
+	return &mysqlRepository{
+		db: params.DB,
+	}
+}
+
+func (p *RepositoryParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Create relies on the tenant and name unique index to detect a carrier
+// already created.
+func (r *mysqlRepository) Create(ctx context.Context, carrier *Entity) error {
+	tenantID, err := tenant.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	carrier.ID = idPrefix + uuid.New().String()
+	carrier.TenantID = tenantID
+	carrier.CreatedAt = now
+	carrier.UpdatedAt = now
+
+	_, err = r.db.NewInsert().Model(carrier.ToModel()).Exec(ctx)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return cerrors.Wrap(err, ErrCarrierAlreadyExists)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *mysqlRepository) GetByID(ctx context.Context, ID string) (*Entity, error) {
+	carrier := Model{}
+
+	query := r.db.NewSelect().Model(&carrier).Where("carrier.id = ?", ID)
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "carrier.tenant_id"); err != nil {
+		return nil, err
+	}
+
+	err := query.Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return carrier.ToEntity(), nil
+}
+
+func (r *mysqlRepository) List(ctx context.Context) ([]*Entity, error) {
+	models := []*Model{}
+
+	query := r.db.NewSelect().Model(&models).Order("carrier.name")
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "carrier.tenant_id"); err != nil {
+		return nil, err
+	}
+
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	carriers := make([]*Entity, 0, len(models))
+	for _, model := range models {
+		carriers = append(carriers, model.ToEntity())
+	}
+
+	return carriers, nil
+}
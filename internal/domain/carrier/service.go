@@ -0,0 +1,70 @@
+package carrier
+
+import (
+	"context"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/validator"
+)
+
+type (
+	Service interface {
+		CreateCarrier(ctx context.Context, carrier *Entity) (*Entity, error)
+		GetCarrierByID(ctx context.Context, id string) (*Entity, error)
+		ListCarriers(ctx context.Context) ([]*Entity, error)
+	}
+
+	service struct {
+		repo Repository
+	}
+
+	ServiceParams struct {
+		Repo Repository `validate:"required"`
+	}
+)
+
+var (
+	ErrCarrierNotFound      = cerrors.New("carrier not found", cerrors.CodeCarrierNotFound)
+	ErrCarrierAlreadyExists = cerrors.New("a carrier with the informed name already exists", cerrors.CodeCarrierAlreadyExists)
+)
+
+func NewService(params *ServiceParams) Service {
+	params.validate()
+
+	return &service{
+		repo: params.Repo,
+	}
+}
+
+func (p *ServiceParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// CreateCarrier creates the carrier in the tenant of ctx.
+func (s *service) CreateCarrier(ctx context.Context, carrier *Entity) (*Entity, error) {
+	err := s.repo.Create(ctx, carrier)
+	if err != nil {
+		return nil, err
+	}
+
+	return carrier, nil
+}
+
+func (s *service) GetCarrierByID(ctx context.Context, id string) (*Entity, error) {
+	carrier, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if carrier == nil {
+		return nil, ErrCarrierNotFound
+	}
+
+	return carrier, nil
+}
+
+func (s *service) ListCarriers(ctx context.Context) ([]*Entity, error) {
+	return s.repo.List(ctx)
+}
@@ -0,0 +1,149 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/uptrace/bun"
+)
+
+type (
+	QueueStats struct {
+		Length   int `json:"length"`
+		Capacity int `json:"capacity"`
+	}
+)
+
+var (
+	ErrNoMigrationsApplied = errors.New("no migrations applied")
+	ErrPendingMigrations   = errors.New("there are pending migrations")
+	ErrWorkerStalled       = errors.New("worker heartbeat is too old")
+	ErrQueueSaturated      = errors.New("queue is saturated")
+	ErrUnreachable         = errors.New("endpoint is unreachable")
+)
+
+// DatabaseCheck pings the database connection pool.
+func DatabaseCheck(db *bun.DB) CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		return nil, db.PingContext(ctx)
+	}
+}
+
+// MigrationCheck reports the last applied sql-migrate migration and, when the
+// migrations directory is available, fails if any of its files is not applied.
+func MigrationCheck(db *bun.DB, table string, migrationsDir string) CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		applied := []string{}
+
+		err := db.NewSelect().
+			Table(table).
+			Column("id").
+			Order("id ASC").
+			Scan(ctx, &applied)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(applied) == 0 {
+			return nil, ErrNoMigrationsApplied
+		}
+
+		details := map[string]any{
+			"version": applied[len(applied)-1],
+		}
+
+		if migrationsDir == "" {
+			return details, nil
+		}
+
+		migrations, err := migrate.FileMigrationSource{Dir: migrationsDir}.FindMigrations()
+		if err != nil {
+			return details, nil //nolint:nilerr // the files are not shipped with every deployment
+		}
+
+		appliedSet := make(map[string]struct{}, len(applied))
+		for _, id := range applied {
+			appliedSet[id] = struct{}{}
+		}
+
+		pending := []string{}
+		for _, migration := range migrations {
+			if _, ok := appliedSet[migration.Id]; !ok {
+				pending = append(pending, migration.Id)
+			}
+		}
+
+		if len(pending) > 0 {
+			details["pending"] = pending
+			return details, ErrPendingMigrations
+		}
+
+		return details, nil
+	}
+}
+
+// WorkerCheck fails when the worker did not report a heartbeat within maxAge.
+func WorkerCheck(heartbeat func() time.Time, maxAge time.Duration) CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		lastBeat := heartbeat()
+		age := time.Since(lastBeat)
+
+		details := map[string]any{
+			"last_heartbeat": lastBeat,
+			"age_ms":         age.Milliseconds(),
+		}
+
+		if lastBeat.IsZero() || age > maxAge {
+			return details, ErrWorkerStalled
+		}
+
+		return details, nil
+	}
+}
+
+// QueueCheck fails when the queue usage reaches the threshold (0-1).
+func QueueCheck(stats func() QueueStats, threshold float64) CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		queueStats := stats()
+		if queueStats.Capacity == 0 {
+			return queueStats, nil
+		}
+
+		usage := float64(queueStats.Length) / float64(queueStats.Capacity)
+		if usage >= threshold {
+			return queueStats, fmt.Errorf("%w: %.0f%% used", ErrQueueSaturated, usage*100)
+		}
+
+		return queueStats, nil
+	}
+}
+
+// HTTPCheck sends a GET request to the URL and fails on network errors or 5xx.
+func HTTPCheck(client *http.Client, url string) CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		details := map[string]any{
+			"status_code": resp.StatusCode,
+		}
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return details, ErrUnreachable
+		}
+
+		return details, nil
+	}
+}
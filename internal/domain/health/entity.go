@@ -0,0 +1,31 @@
+package health
+
+import "time"
+
+type (
+	Report struct {
+		Status    Status                  `json:"status"`
+		Checks    map[string]*CheckResult `json:"checks"`
+		CheckedAt time.Time               `json:"checked_at"`
+	}
+
+	CheckResult struct {
+		Status     Status `json:"status"`
+		Optional   bool   `json:"optional,omitempty"`
+		DurationMS int64  `json:"duration_ms"`
+		Details    any    `json:"details,omitempty"`
+		Error      string `json:"error,omitempty"`
+	}
+
+	Status string
+)
+
+var (
+	StatusUp       Status = "UP"
+	StatusDown     Status = "DOWN"
+	StatusDegraded Status = "DEGRADED"
+)
+
+func (r *Report) IsHealthy() bool {
+	return r != nil && r.Status != StatusDown
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"pack-management/internal/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	HandlerParams struct {
+		App     *fiber.App `validate:"required"`
+		Service Service    `validate:"required"`
+	}
+
+	handler struct {
+		app     *fiber.App
+		service Service
+	}
+)
+
+func NewHTPPHandler(params *HandlerParams) *handler {
+	params.validate()
+
+	h := &handler{
+		app:     params.App,
+		service: params.Service,
+	}
+
+	h.app.Get("/livez", h.live)
+	h.app.Get("/readyz", h.ready)
+
+	return h
+}
+
+func (p *HandlerParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (h *handler) live(ctx *fiber.Ctx) error {
+	return h.sendReport(ctx, h.service.Live(ctx.UserContext()))
+}
+
+func (h *handler) ready(ctx *fiber.Ctx) error {
+	return h.sendReport(ctx, h.service.Ready(ctx.UserContext()))
+}
+
+func (h *handler) sendReport(ctx *fiber.Ctx, report *Report) error {
+	ctx.Response().Header.Set("Cache-Control", "no-store")
+
+	if !report.IsHealthy() {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(report)
+}
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"pack-management/internal/pkg/validator"
+	"sync"
+	"time"
+)
+
+type (
+	Service interface {
+		Live(ctx context.Context) *Report
+		Ready(ctx context.Context) *Report
+	}
+
+	// Check is a named probe. Liveness checks run on both endpoints, the
+	// others only on readiness. A failing optional check degrades the report
+	// without taking the instance out of rotation.
+	Check struct {
+		Name     string    `validate:"required"`
+		Run      CheckFunc `validate:"required"`
+		Liveness bool
+		Optional bool
+	}
+
+	CheckFunc func(ctx context.Context) (any, error)
+
+	service struct {
+		checks  []*Check
+		timeout time.Duration
+	}
+
+	ServiceParams struct {
+		Checks  []*Check `validate:"dive,required"`
+		Timeout time.Duration
+	}
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+func NewService(params *ServiceParams) Service {
+	params.validate()
+
+	if params.Timeout <= 0 {
+		params.Timeout = defaultCheckTimeout
+	}
+
+	return &service{
+		checks:  params.Checks,
+		timeout: params.Timeout,
+	}
+}
+
+func (p *ServiceParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (s *service) Live(ctx context.Context) *Report {
+	checks := make([]*Check, 0, len(s.checks))
+	for _, check := range s.checks {
+		if check.Liveness {
+			checks = append(checks, check)
+		}
+	}
+
+	return s.run(ctx, checks)
+}
+
+func (s *service) Ready(ctx context.Context) *Report {
+	return s.run(ctx, s.checks)
+}
+
+func (s *service) run(ctx context.Context, checks []*Check) *Report {
+	report := &Report{
+		Status:    StatusUp,
+		Checks:    make(map[string]*CheckResult, len(checks)),
+		CheckedAt: time.Now(),
+	}
+
+	results := make([]*CheckResult, len(checks))
+
+	wg := sync.WaitGroup{}
+	for i, check := range checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			results[i] = s.runCheck(ctx, check)
+		}()
+	}
+
+	wg.Wait()
+
+	for i, check := range checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+
+		if result.Status == StatusUp {
+			continue
+		}
+
+		if check.Optional {
+			if report.Status == StatusUp {
+				report.Status = StatusDegraded
+			}
+
+			continue
+		}
+
+		report.Status = StatusDown
+	}
+
+	return report
+}
+
+func (s *service) runCheck(ctx context.Context, check *Check) *CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Run(ctx)
+
+	result := &CheckResult{
+		Status:     StatusUp,
+		Optional:   check.Optional,
+		DurationMS: time.Since(start).Milliseconds(),
+		Details:    details,
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -7,6 +7,7 @@ import (
 type (
 	Entity struct {
 		ID        string
+		Country   string
 		Name      string
 		Date      string
 		CreatedAt time.Time
@@ -21,6 +22,7 @@ func (e *Entity) ToModel() *Model {
 
 	model := &Model{
 		ID:        e.ID,
+		Country:   e.Country,
 		Name:      e.Name,
 		Date:      e.Date,
 		CreatedAt: e.CreatedAt,
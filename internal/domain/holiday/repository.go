@@ -11,12 +11,13 @@ type (
 	Repository interface {
 		Create(ctx context.Context, holiday *Entity) error
 		BulkCreate(ctx context.Context, holidays []*Entity) error
-		ListByYear(ctx context.Context, year string) ([]*Entity, error)
+		ListByYear(ctx context.Context, country string, year string) ([]*Entity, error)
 	}
 
 	Model struct {
 		bun.BaseModel `bun:"table:holiday,alias:holiday"`
 		ID            string    `bun:"id,pk"`
+		Country       string    `bun:"country"`
 		Name          string    `bun:"name"`
 		Date          string    `bun:"date"`
 		CreatedAt     time.Time `bun:"created_at"`
@@ -35,6 +36,7 @@ func (m *Model) ToEntity() *Entity {
 
 	return &Entity{
 		ID:        m.ID,
+		Country:   m.Country,
 		Name:      m.Name,
 		Date:      m.Date,
 		CreatedAt: m.CreatedAt,
@@ -69,13 +69,14 @@ func (r *mysqlRepository) BulkCreate(ctx context.Context, holidays []*Entity) er
 	return nil
 }
 
-func (r *mysqlRepository) ListByYear(ctx context.Context, year string) ([]*Entity, error) {
+func (r *mysqlRepository) ListByYear(ctx context.Context, country string, year string) ([]*Entity, error) {
 	holidays := make([]*Model, 0)
 	dateGte := year + "-01-01"
 	dateLte := year + "-12-31"
 
 	err := r.db.NewSelect().
 		Model(&holidays).
+		Where("country = ?", country).
 		Where("date BETWEEN ? and ?", dateGte, dateLte).
 		Scan(ctx)
 	if err != nil {
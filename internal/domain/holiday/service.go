@@ -9,7 +9,8 @@ import (
 
 type (
 	Service interface {
-		IsHoliday(ctx context.Context, date string) (bool, error)
+		IsHoliday(ctx context.Context, country string, date string) (bool, error)
+		PrefetchYear(ctx context.Context, country string, year string) (int, error)
 	}
 
 	service struct {
@@ -23,10 +24,6 @@ type (
 	}
 )
 
-const (
-	countryCode = "BR"
-)
-
 func NewService(params *ServiceParams) Service {
 	params.validate()
 
@@ -43,16 +40,18 @@ func (p *ServiceParams) validate() {
 	}
 }
 
-func (s *service) IsHoliday(ctx context.Context, date string) (bool, error) {
+// IsHoliday checks the date in the holidays of the country, the ISO 3166-1
+// alpha-2 code, e.g.: BR.
+func (s *service) IsHoliday(ctx context.Context, country string, date string) (bool, error) {
 	year := date[:4]
 
-	holidays, err := s.repo.ListByYear(ctx, year)
+	holidays, err := s.repo.ListByYear(ctx, country, year)
 	if err != nil {
 		return false, err
 	}
 
 	if len(holidays) <= 0 {
-		holidays, err = s.getHolidaysFromProvider(ctx, year)
+		holidays, err = s.getHolidaysFromProvider(ctx, country, year)
 		if err != nil {
 			return false, err
 		}
@@ -65,8 +64,29 @@ func (s *service) IsHoliday(ctx context.Context, date string) (bool, error) {
 	return isHoliday, nil
 }
 
-func (s *service) getHolidaysFromProvider(ctx context.Context, year string) ([]*Entity, error) {
-	holidayResponse, err := s.client.GetHolidays(ctx, countryCode, year)
+// PrefetchYear caches the holidays of the country and year, when not cached
+// yet, so the first packs of the year don't wait for the provider. It returns
+// how many holidays were fetched.
+func (s *service) PrefetchYear(ctx context.Context, country string, year string) (int, error) {
+	holidays, err := s.repo.ListByYear(ctx, country, year)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(holidays) > 0 {
+		return 0, nil
+	}
+
+	holidays, err = s.getHolidaysFromProvider(ctx, country, year)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(holidays), nil
+}
+
+func (s *service) getHolidaysFromProvider(ctx context.Context, country string, year string) ([]*Entity, error) {
+	holidayResponse, err := s.client.GetHolidays(ctx, country, year)
 	if err != nil {
 		return nil, err
 	}
@@ -74,8 +94,9 @@ func (s *service) getHolidaysFromProvider(ctx context.Context, year string) ([]*
 	holidays := make([]*Entity, 0, len(holidayResponse))
 	for _, holiday := range holidayResponse {
 		holidayEntity := &Entity{
-			Name: holiday.Name,
-			Date: holiday.Date,
+			Country: country,
+			Name:    holiday.Name,
+			Date:    holiday.Date,
 		}
 
 		holidays = append(holidays, holidayEntity)
@@ -0,0 +1,175 @@
+package job
+
+import (
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/scheduler"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	handler struct {
+		scheduler scheduler.Scheduler
+		app       *fiber.App
+	}
+
+	HandlerParams struct {
+		App       *fiber.App          `validate:"required"`
+		Scheduler scheduler.Scheduler `validate:"required"`
+	}
+
+	JobNameParam struct {
+		Name string `params:"name"`
+	}
+
+	ListRunsQuery struct {
+		Limit int `query:"limit" validate:"gte=0,lte=100"`
+	}
+
+	ListJobsJSON struct {
+		Items []*JobJSON `json:"items"`
+	}
+
+	JobJSON struct {
+		Name      string     `json:"name"`
+		Schedule  string     `json:"schedule"`
+		NextRunAt *time.Time `json:"next_run_at,omitempty"`
+		LastRun   *RunJSON   `json:"last_run,omitempty"`
+	}
+
+	ListRunsJSON struct {
+		Items []*RunJSON `json:"items"`
+	}
+
+	RunJSON struct {
+		ID          string            `json:"id"`
+		Job         string            `json:"job"`
+		Trigger     scheduler.Trigger `json:"trigger"`
+		Instance    string            `json:"instance"`
+		Status      scheduler.Status  `json:"status"`
+		Error       *string           `json:"error,omitempty"`
+		ScheduledAt *time.Time        `json:"scheduled_at,omitempty"`
+		StartedAt   time.Time         `json:"started_at"`
+		FinishedAt  *time.Time        `json:"finished_at,omitempty"`
+	}
+)
+
+const (
+	defaultRunsLimit = 20
+)
+
+func NewHTPPHandler(params *HandlerParams) *handler {
+	params.validate()
+
+	h := &handler{
+		scheduler: params.Scheduler,
+		app:       params.App,
+	}
+
+	group := h.app.Group("/admin/jobs", auth.RequireScope(auth.ScopeAdmin))
+	group.Get("/", h.listJobs)
+	group.Get("/:name/runs", h.listRuns)
+	group.Post("/:name/run", h.triggerJob)
+
+	return h
+}
+
+func (p *HandlerParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (h *handler) listJobs(ctx *fiber.Ctx) error {
+	jobs, err := h.scheduler.Jobs(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	resp := &ListJobsJSON{
+		Items: make([]*JobJSON, 0, len(jobs)),
+	}
+
+	for _, job := range jobs {
+		resp.Items = append(resp.Items, &JobJSON{
+			Name:      job.Name,
+			Schedule:  job.Schedule,
+			NextRunAt: job.NextRunAt,
+			LastRun:   h.runToJSON(job.LastRun),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *handler) listRuns(ctx *fiber.Ctx) error {
+	params := &JobNameParam{}
+	if err := ctx.ParamsParser(params); err != nil {
+		return cerrors.ErrInvalidParams
+	}
+
+	queries := &ListRunsQuery{}
+	if err := ctx.QueryParser(queries); err != nil {
+		return cerrors.ErrInvalidQuery
+	}
+
+	err := validator.ValidateStruct(queries)
+	if err != nil {
+		return err
+	}
+
+	if queries.Limit == 0 {
+		queries.Limit = defaultRunsLimit
+	}
+
+	runs, err := h.scheduler.Runs(ctx.UserContext(), params.Name, queries.Limit)
+	if err != nil {
+		return err
+	}
+
+	resp := &ListRunsJSON{
+		Items: make([]*RunJSON, 0, len(runs)),
+	}
+
+	for _, run := range runs {
+		resp.Items = append(resp.Items, h.runToJSON(run))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *handler) triggerJob(ctx *fiber.Ctx) error {
+	params := &JobNameParam{}
+	if err := ctx.ParamsParser(params); err != nil {
+		return cerrors.ErrInvalidParams
+	}
+
+	run, err := h.scheduler.Trigger(ctx.UserContext(), params.Name)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(h.runToJSON(run))
+}
+
+func (h *handler) runToJSON(run *scheduler.Run) *RunJSON {
+	if run == nil {
+		return nil
+	}
+
+	return &RunJSON{
+		ID:          run.ID,
+		Job:         run.Job,
+		Trigger:     run.Trigger,
+		Instance:    run.Instance,
+		Status:      run.Status,
+		Error:       run.Error,
+		ScheduledAt: run.ScheduledAt,
+		StartedAt:   run.StartedAt,
+		FinishedAt:  run.FinishedAt,
+	}
+}
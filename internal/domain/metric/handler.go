@@ -1,6 +1,7 @@
 package metric
 
 import (
+	"pack-management/internal/domain/auth"
 	"pack-management/internal/pkg/validator"
 
 	"github.com/gofiber/fiber/v2"
@@ -93,7 +94,7 @@ func NewHTPPHandler(params *HandlerParams) *handler {
 		dbMaxLifetimeClosed,
 	)
 
-	h.app.Get("/metrics", h.listMetrics)
+	h.app.Get("/metrics", auth.RequireScope(auth.ScopeMetricsRead), h.listMetrics)
 
 	return h
 }
@@ -3,12 +3,17 @@ package pack
 import (
 	"pack-management/internal/domain/person"
 	"pack-management/internal/pkg/cerrors"
+	"slices"
 	"time"
 )
 
 type (
 	Entity struct {
-		ID                    string
+		ID       string
+		TenantID string
+		// TrackingCode is the public code of the pack, e.g.: BR12ABC34566,
+		// shown to the customers instead of the ID.
+		TrackingCode          *string
 		Description           string
 		FunFact               *string
 		IsHoliday             *bool
@@ -18,9 +23,36 @@ type (
 		EstimatedDeliveryDate string
 		DeliveredAt           *time.Time
 		CanceledAt            *time.Time
-		CreatedAt             time.Time
-		UpdatedAt             time.Time
-		Events                []*EventEntity
+		OverdueSince          *time.Time
+		// CarrierID is the carrier delivering the pack, its credentials may
+		// post the pack events, TrackingNumber is unique per carrier.
+		CarrierID      *string
+		TrackingNumber *string
+		// Version is increased on every change made by the clients, the
+		// enrichment and the overdue mark don't change it.
+		Version   int
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		Events    []*EventEntity
+	}
+
+	// Tracking is the customer view of a pack, without the fun fact, the
+	// persons nor the internal IDs.
+	Tracking struct {
+		Code                  string
+		Status                Status
+		EstimatedDeliveryDate string
+		DeliveredAt           *time.Time
+		CanceledAt            *time.Time
+		// Carrier is set when the pack is assigned to a carrier.
+		Carrier   *TrackingCarrier
+		UpdatedAt time.Time
+		Events    []*EventEntity
+	}
+
+	TrackingCarrier struct {
+		Name           string
+		TrackingNumber string
 	}
 
 	EventEntity struct {
@@ -31,7 +63,39 @@ type (
 		Date        time.Time
 	}
 
-	Status string
+	// Aggregates are the counts of the packs matching a filter set, the
+	// facets are only set when asked.
+	Aggregates struct {
+		Total    int
+		Statuses map[Status]int
+		Senders  []*FacetCount
+	}
+
+	FacetCount struct {
+		Value string
+		Count int
+	}
+
+	// Details are the pack fields editable after the creation, the nil ones
+	// are kept.
+	Details struct {
+		Description           *string
+		ReceiverName          *string
+		EstimatedDeliveryDate *string
+		// Version is the pack version expected by the client, 0 accepts any.
+		Version int
+	}
+
+	// CarrierAssignment assigns the pack to the carrier, Version is the pack
+	// version expected by the client, 0 accepts any.
+	CarrierAssignment struct {
+		CarrierID      string
+		TrackingNumber string
+		Version        int
+	}
+
+	Status       string
+	DetailsField string
 )
 
 var (
@@ -40,9 +104,32 @@ var (
 	StatusDelivered Status = "DELIVERED"
 	StatusCanceled  Status = "CANCELED"
 
-	ErrPackNotFound  = cerrors.New("pack not found", "pack_not_found")
-	ErrStatusInvalid = cerrors.New("the informed status is invalid", "status_invalid")
-	ErrCannotCancel  = cerrors.New("cannot cancel pack is already sent", "cannot_cancel")
+	ErrPackNotFound   = cerrors.New("pack not found", cerrors.CodePackNotFound)
+	ErrStatusInvalid  = cerrors.New("the informed status is invalid", cerrors.CodeStatusInvalid)
+	ErrCannotCancel   = cerrors.New("cannot cancel pack is already sent", cerrors.CodeCannotCancel)
+	ErrInvalidFilters = cerrors.New("the informed filters are invalid", cerrors.CodeInvalidFilters)
+
+	ErrVersionConflict = cerrors.New("the pack was changed by another request", cerrors.CodePackVersionConflict)
+
+	ErrInvalidDetails              = cerrors.New("the informed details are invalid", cerrors.CodeInvalidDetails)
+	ErrDetailsNotEditable          = cerrors.New("the informed fields are not editable in the pack status", cerrors.CodeDetailsNotEditable)
+	ErrEstimatedDeliveryDateInPast = cerrors.New("the estimated delivery date cannot be in the past", cerrors.CodeEstimatedDeliveryDateInPast)
+
+	ErrCarrierNotAssignable = cerrors.New("the carrier of a delivered or canceled pack cannot be changed", cerrors.CodeCarrierNotAssignable)
+	ErrTrackingNumberInUse  = cerrors.New("the tracking number is already used by another pack of the carrier", cerrors.CodeTrackingNumberInUse)
+
+	ErrInvalidTrackingCode = cerrors.New("the informed tracking code is invalid, check it for typos", cerrors.CodeInvalidTrackingCode)
+
+	DetailsFieldDescription           DetailsField = "description"
+	DetailsFieldRecipient             DetailsField = "recipient"
+	DetailsFieldEstimatedDeliveryDate DetailsField = "estimated_delivery_date"
+
+	// editableDetails are the fields editable in each status, the delivered
+	// and canceled packs are not editable.
+	editableDetails = map[Status][]DetailsField{
+		StatusCreated:   {DetailsFieldDescription, DetailsFieldRecipient, DetailsFieldEstimatedDeliveryDate},
+		StatusInTransit: {DetailsFieldDescription, DetailsFieldEstimatedDeliveryDate},
+	}
 )
 
 func (e *Entity) ToModel() *Model {
@@ -58,6 +145,8 @@ func (e *Entity) ToModel() *Model {
 
 	model := &Model{
 		ID:                    e.ID,
+		TenantID:              e.TenantID,
+		TrackingCode:          e.TrackingCode,
 		Description:           e.Description,
 		FunFact:               e.FunFact,
 		IsHoliday:             e.IsHoliday,
@@ -65,6 +154,10 @@ func (e *Entity) ToModel() *Model {
 		EstimatedDeliveryDate: estimatedDeliveryDate,
 		DeliveredAt:           e.DeliveredAt,
 		CanceledAt:            e.CanceledAt,
+		OverdueSince:          e.OverdueSince,
+		CarrierID:             e.CarrierID,
+		TrackingNumber:        e.TrackingNumber,
+		Version:               e.Version,
 		CreatedAt:             e.CreatedAt,
 		UpdatedAt:             e.UpdatedAt,
 	}
@@ -104,6 +197,49 @@ func (s *Status) String() string {
 	return string(*s)
 }
 
+// ValidateVersion checks the version expected by the client, 0 accepts any.
+func (e *Entity) ValidateVersion(version int) error {
+	if version != 0 && version != e.Version {
+		return ErrVersionConflict.WithMetadata("current_version", e.Version)
+	}
+
+	return nil
+}
+
+// ChangedDetails returns the fields the details change, the ones equal to the
+// current values are not changes.
+func (e *Entity) ChangedDetails(details *Details) []DetailsField {
+	changed := []DetailsField{}
+
+	if details.Description != nil && *details.Description != e.Description {
+		changed = append(changed, DetailsFieldDescription)
+	}
+
+	if details.ReceiverName != nil && (e.Receiver == nil || *details.ReceiverName != e.Receiver.Name) {
+		changed = append(changed, DetailsFieldRecipient)
+	}
+
+	if details.EstimatedDeliveryDate != nil && *details.EstimatedDeliveryDate != e.EstimatedDeliveryDate {
+		changed = append(changed, DetailsFieldEstimatedDeliveryDate)
+	}
+
+	return changed
+}
+
+func (s *Status) ValidateChangeDetails(fields []DetailsField) error {
+	if s == nil {
+		return nil
+	}
+
+	for _, field := range fields {
+		if !slices.Contains(editableDetails[*s], field) {
+			return ErrDetailsNotEditable
+		}
+	}
+
+	return nil
+}
+
 func (s *Status) ValidateChangeStatus(newStatus Status) error {
 	if s == nil {
 		return nil
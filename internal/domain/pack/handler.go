@@ -1,11 +1,19 @@
 package pack
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"pack-management/internal/domain/auth"
 	"pack-management/internal/domain/person"
 	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/export"
+	"pack-management/internal/pkg/logger"
 	"pack-management/internal/pkg/pagination"
+	"pack-management/internal/pkg/spreadsheet"
 	"pack-management/internal/pkg/validator"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,13 +21,27 @@ import (
 
 type (
 	handler struct {
-		service Service
-		app     *fiber.App
+		service             Service
+		app                 *fiber.App
+		cacheMaxAgeCreated  time.Duration
+		cacheMaxAgeInFlight time.Duration
+		bulkMaxRows         int
 	}
 
 	HandlerParams struct {
 		App     *fiber.App `validate:"required"`
 		Service Service    `validate:"required"`
+		// CacheMaxAgeCreated is the GET /packs/:id max-age of packs in the
+		// CREATED status, CacheMaxAgeInFlight of the others. Optional, 1m
+		// and 1h by default.
+		CacheMaxAgeCreated  time.Duration `validate:"gte=0"`
+		CacheMaxAgeInFlight time.Duration `validate:"gte=0"`
+		// Idempotency is the Idempotency-Key middleware of the create and
+		// cancel routes, optional, the keys are ignored by default.
+		Idempotency fiber.Handler
+		// BulkMaxRows limits the rows of the bulk creation and the import,
+		// optional, 1000 by default.
+		BulkMaxRows int `validate:"gte=0"`
 	}
 
 	CreatePackRequest struct {
@@ -37,16 +59,76 @@ type (
 		ID string `params:"id"`
 	}
 
+	// ListPackQuery accepts the status filter repeated or comma separated,
+	// the dates are YYYY-MM-DD.
 	ListPackQuery struct {
-		SenderName   *string `query:"sender_name"`
-		ReceiverName *string `query:"recipient_name"`
-		PageSize     int     `query:"page_size"`
-		PageCursor   *string `query:"page_cursor"`
+		SenderName            *string  `query:"sender_name"`
+		ReceiverName          *string  `query:"recipient_name"`
+		Statuses              []string `query:"status"`
+		CreatedFrom           *string  `query:"created_from" validate:"omitempty,datetime=2006-01-02"`
+		CreatedTo             *string  `query:"created_to" validate:"omitempty,datetime=2006-01-02"`
+		DeliveredFrom         *string  `query:"delivered_from" validate:"omitempty,datetime=2006-01-02"`
+		DeliveredTo           *string  `query:"delivered_to" validate:"omitempty,datetime=2006-01-02"`
+		EstimatedDeliveryFrom *string  `query:"estimated_delivery_from" validate:"omitempty,datetime=2006-01-02"`
+		EstimatedDeliveryTo   *string  `query:"estimated_delivery_to" validate:"omitempty,datetime=2006-01-02"`
+		IsHoliday             *bool    `query:"is_holiday"`
+		Overdue               *bool    `query:"overdue"`
+		Text                  *string  `query:"q"`
+		Sort                  string   `query:"sort" validate:"omitempty,oneof=created_at estimated_delivery_date"`
+		Order                 string   `query:"order" validate:"omitempty,oneof=asc desc"`
+		PageSize              int      `query:"page_size"`
+		PageCursor            *string  `query:"page_cursor"`
+		Include               []string `query:"include"`
+	}
+
+	// ExportPackQuery has the GET /packs filters, the page ones are ignored.
+	// Format is csv by default.
+	ExportPackQuery struct {
+		ListPackQuery
+		Format     string `query:"format"`
+		WithEvents bool   `query:"with_events"`
+	}
+
+	// PackExportRow is a pack of the export. On CSV the events are a JSON
+	// array column, empty when they are not included.
+	PackExportRow struct {
+		ID                    string           `json:"id" parquet:"id"`
+		TrackingCode          *string          `json:"tracking_code,omitempty" parquet:"tracking_code,optional"`
+		Description           string           `json:"description" parquet:"description"`
+		Status                Status           `json:"status" parquet:"status"`
+		SenderName            string           `json:"sender" parquet:"sender"`
+		ReceiverName          string           `json:"recipient" parquet:"recipient"`
+		EstimatedDeliveryDate string           `json:"estimated_delivery_date" parquet:"estimated_delivery_date"`
+		IsHoliday             *bool            `json:"is_holiday,omitempty" parquet:"is_holiday,optional"`
+		CreatedAt             time.Time        `json:"created_at" parquet:"created_at"`
+		UpdatedAt             time.Time        `json:"updated_at" parquet:"updated_at"`
+		DeliveredAt           *time.Time       `json:"delivered_at,omitempty" parquet:"delivered_at,optional"`
+		CanceledAt            *time.Time       `json:"canceled_at,omitempty" parquet:"canceled_at,optional"`
+		OverdueSince          *time.Time       `json:"overdue_since,omitempty" parquet:"overdue_since,optional"`
+		Events                []EventExportRow `json:"events,omitempty" parquet:"events,list"`
+	}
+
+	EventExportRow struct {
+		ID          string    `json:"id" parquet:"id"`
+		Description string    `json:"description" parquet:"description"`
+		Location    string    `json:"location" parquet:"location"`
+		Date        time.Time `json:"date" parquet:"date"`
 	}
 
 	ListPackJSON struct {
 		Items    []*PackJSON         `json:"items"`
 		Metadata pagination.Metadata `json:"metadata"`
+		Facets   *FacetsJSON         `json:"facets,omitempty"`
+	}
+
+	FacetsJSON struct {
+		Status map[Status]int    `json:"status"`
+		Sender []*FacetCountJSON `json:"sender"`
+	}
+
+	FacetCountJSON struct {
+		Value string `json:"value"`
+		Count int    `json:"count"`
 	}
 
 	UpdatePackStatusRequest struct {
@@ -54,17 +136,71 @@ type (
 		Status Status `json:"status" validate:"required,oneof=CREATED IN_TRANSIT DELIVERED"`
 	}
 
+	// UpdatePackDetailsRequest is the PATCH /packs/:id/details payload, the
+	// missing fields are kept.
+	UpdatePackDetailsRequest struct {
+		Description           *string `json:"description" validate:"omitnil,min=1"`
+		ReceiverName          *string `json:"recipient" validate:"omitnil,min=1,max=255"`
+		EstimatedDeliveryDate *string `json:"estimated_delivery_date" validate:"omitnil,datetime=2006-01-02"`
+	}
+
+	// ReplacePackDetailsRequest is the PUT /packs/:id/details payload, all
+	// fields are required.
+	ReplacePackDetailsRequest struct {
+		Description           string `json:"description" validate:"required"`
+		ReceiverName          string `json:"recipient" validate:"required,max=255"`
+		EstimatedDeliveryDate string `json:"estimated_delivery_date" validate:"required,datetime=2006-01-02"`
+	}
+
+	// AssignCarrierRequest is the PUT /packs/:id/carrier payload.
+	AssignCarrierRequest struct {
+		CarrierID      string `json:"carrier_id" validate:"required"`
+		TrackingNumber string `json:"tracking_number" validate:"required,max=64"`
+	}
+
+	// BulkReportJSON is the result of each row of a bulk creation or import,
+	// the valid rows are created and the invalid ones have their errors.
+	BulkReportJSON struct {
+		Total   int            `json:"total"`
+		Created int            `json:"created"`
+		Invalid int            `json:"invalid"`
+		Rows    []*BulkRowJSON `json:"rows"`
+	}
+
+	// BulkRowJSON Row is the position in the JSON array, or the line in the
+	// imported file, starting at 1. Errors has the shape of the problem ones.
+	BulkRowJSON struct {
+		Row    int                     `json:"row"`
+		Status BulkRowStatus           `json:"status"`
+		Pack   *PackJSON               `json:"pack,omitempty"`
+		Errors []*validator.FieldError `json:"errors,omitempty"`
+	}
+
+	BulkRowStatus string
+
+	bulkRow struct {
+		row     int
+		request *CreatePackRequest
+	}
+
 	PackJSON struct {
-		ID           string      `json:"id"`
-		Description  string      `json:"description"`
-		Status       Status      `json:"status"`
-		ReceiverName string      `json:"recipient"`
-		SenderName   string      `json:"sender"`
-		CreatedAt    time.Time   `json:"created_at"`
-		UpdateAt     time.Time   `json:"updated_at"`
-		DeliveredAt  *time.Time  `json:"delivered_at,omitempty"`
-		CanceledAt   *time.Time  `json:"canceled_at,omitempty"`
-		Events       []EventJSON `json:"events,omitempty"`
+		ID                    string      `json:"id"`
+		TrackingCode          *string     `json:"tracking_code,omitempty"`
+		Description           string      `json:"description"`
+		Status                Status      `json:"status"`
+		ReceiverName          string      `json:"recipient"`
+		SenderName            string      `json:"sender"`
+		EstimatedDeliveryDate string      `json:"estimated_delivery_date,omitempty"`
+		IsHoliday             *bool       `json:"is_holiday,omitempty"`
+		CreatedAt             time.Time   `json:"created_at"`
+		UpdateAt              time.Time   `json:"updated_at"`
+		DeliveredAt           *time.Time  `json:"delivered_at,omitempty"`
+		CanceledAt            *time.Time  `json:"canceled_at,omitempty"`
+		OverdueSince          *time.Time  `json:"overdue_since,omitempty"`
+		CarrierID             *string     `json:"carrier_id,omitempty"`
+		TrackingNumber        *string     `json:"tracking_number,omitempty"`
+		Version               int         `json:"version"`
+		Events                []EventJSON `json:"events,omitempty"`
 	}
 
 	EventJSON struct {
@@ -74,26 +210,113 @@ type (
 		Location    string    `json:"location"`
 		Date        time.Time `json:"date"`
 	}
+
+	TrackingCodeParam struct {
+		Code string `params:"code"`
+	}
+
+	// TrackingJSON is the GET /track/:code customer view, it has no internal
+	// IDs, persons nor fun fact.
+	TrackingJSON struct {
+		TrackingCode          string               `json:"tracking_code"`
+		Status                Status               `json:"status"`
+		EstimatedDeliveryDate string               `json:"estimated_delivery_date"`
+		DeliveredAt           *time.Time           `json:"delivered_at,omitempty"`
+		CanceledAt            *time.Time           `json:"canceled_at,omitempty"`
+		Carrier               *TrackingCarrierJSON `json:"carrier,omitempty"`
+		UpdatedAt             time.Time            `json:"updated_at"`
+		Events                []TrackingEventJSON  `json:"events"`
+	}
+
+	TrackingCarrierJSON struct {
+		Name           string `json:"name"`
+		TrackingNumber string `json:"tracking_number"`
+	}
+
+	TrackingEventJSON struct {
+		Description string    `json:"description"`
+		Location    string    `json:"location"`
+		Date        time.Time `json:"date"`
+	}
+)
+
+var (
+	BulkRowCreated BulkRowStatus = "created"
+	BulkRowInvalid BulkRowStatus = "invalid"
+
+	ErrTooManyRows         = cerrors.New("the request has more rows than allowed", cerrors.CodeTooManyRows)
+	ErrInvalidExportFormat = cerrors.New("the export format is invalid, use csv, ndjson or parquet", cerrors.CodeInvalidExportFormat)
+	ErrInvalidImportFile   = cerrors.New("the file must be a .csv or .xlsx with the description, sender, recipient and estimated_delivery_date columns", cerrors.CodeInvalidImportFile)
+
+	// importColumns are the required columns of the imported files.
+	importColumns = []string{"description", "sender", "recipient", "estimated_delivery_date"}
+)
+
+const (
+	defaultBulkMaxRows = 1000
+	exportRowGroupSize = 500
+	importFileField    = "file"
+
+	defaultCacheMaxAgeCreated  = time.Minute
+	defaultCacheMaxAgeInFlight = time.Hour
+
+	includeTotalValue  = "total"
+	includeFacetsValue = "facets"
 )
 
 func NewHTPPHandler(params *HandlerParams) *handler {
 	params.validate()
 
+	if params.CacheMaxAgeCreated == 0 {
+		params.CacheMaxAgeCreated = defaultCacheMaxAgeCreated
+	}
+
+	if params.CacheMaxAgeInFlight == 0 {
+		params.CacheMaxAgeInFlight = defaultCacheMaxAgeInFlight
+	}
+
+	if params.Idempotency == nil {
+		params.Idempotency = skipIdempotency
+	}
+
+	if params.BulkMaxRows == 0 {
+		params.BulkMaxRows = defaultBulkMaxRows
+	}
+
 	h := &handler{
-		service: params.Service,
-		app:     params.App,
+		service:             params.Service,
+		app:                 params.App,
+		cacheMaxAgeCreated:  params.CacheMaxAgeCreated,
+		cacheMaxAgeInFlight: params.CacheMaxAgeInFlight,
+		bulkMaxRows:         params.BulkMaxRows,
 	}
 
 	group := h.app.Group("/packs")
-	group.Post("/", h.createPack)
-	group.Get("/", h.listPacks)
-	group.Get("/:id", h.getPackByID)
-	group.Patch("/:id", h.updatePackStatusByID)
-	group.Post("/:id/cancel", h.cancelPackStatusByID)
+	read := auth.RequireScope(auth.ScopePacksRead)
+	write := auth.RequireScope(auth.ScopePacksWrite)
+
+	group.Post("/", write, params.Idempotency, h.createPack)
+	group.Post("/bulk", write, params.Idempotency, h.createPacksBulk)
+	group.Post("/import", write, params.Idempotency, h.importPacks)
+	group.Get("/", read, h.listPacks)
+	group.Get("/export", read, h.exportPacks)
+	group.Get("/:id", read, h.getPackByID)
+	group.Patch("/:id", write, h.updatePackStatusByID)
+	group.Put("/:id/details", write, h.replacePackDetailsByID)
+	group.Patch("/:id/details", write, h.updatePackDetailsByID)
+	group.Post("/:id/cancel", write, params.Idempotency, h.cancelPackStatusByID)
+	group.Put("/:id/carrier", write, h.assignCarrierByID)
+
+	// the customers track the packs without credentials
+	h.app.Get("/track/:code", h.getTracking)
 
 	return h
 }
 
+func skipIdempotency(ctx *fiber.Ctx) error {
+	return ctx.Next()
+}
+
 func (p *HandlerParams) validate() {
 	err := validator.ValidateStruct(p)
 	if err != nil {
@@ -104,38 +327,180 @@ func (p *HandlerParams) validate() {
 func (h *handler) createPack(ctx *fiber.Ctx) error {
 	payload := &CreatePackRequest{}
 	if err := ctx.BodyParser(payload); err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return cerrors.ErrInvalidBody
 	}
 
 	err := validator.ValidateStruct(payload)
 	if err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return err
 	}
 
-	pack, err := h.service.CreatePack(ctx.Context(), payload.ToEntity())
+	pack, err := h.service.CreatePack(ctx.UserContext(), payload.ToEntity())
 	if err != nil {
-		return h.errorHandler(ctx, err)
+		return err
 	}
 
+	h.setETag(ctx, pack)
+
 	return ctx.Status(fiber.StatusCreated).JSON(h.packEntityToJSON(pack))
 }
 
+func (h *handler) createPacksBulk(ctx *fiber.Ctx) error {
+	payload := []*CreatePackRequest{}
+	if err := ctx.BodyParser(&payload); err != nil {
+		return cerrors.ErrInvalidBody
+	}
+
+	rows := make([]*bulkRow, 0, len(payload))
+	for i, request := range payload {
+		if request == nil {
+			request = &CreatePackRequest{}
+		}
+
+		rows = append(rows, &bulkRow{row: i + 1, request: request})
+	}
+
+	return h.createRows(ctx, rows)
+}
+
+// importPacks creates the packs of a CSV or XLSX file uploaded in the file
+// field, the first row is the header with the column names.
+func (h *handler) importPacks(ctx *fiber.Ctx) error {
+	fileHeader, err := ctx.FormFile(importFileField)
+	if err != nil {
+		return ErrInvalidImportFile
+	}
+
+	format, err := spreadsheet.FormatFromFilename(fileHeader.Filename)
+	if err != nil {
+		return ErrInvalidImportFile
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	lines, err := spreadsheet.Read(file, format)
+	if err != nil || len(lines) == 0 {
+		return ErrInvalidImportFile
+	}
+
+	columns := map[string]int{}
+	for i, name := range lines[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			return ErrInvalidImportFile
+		}
+	}
+
+	rows := make([]*bulkRow, 0, len(lines)-1)
+	for i, line := range lines[1:] {
+		cell := func(name string) string {
+			index := columns[name]
+			if index >= len(line) {
+				return ""
+			}
+
+			return strings.TrimSpace(line[index])
+		}
+
+		request := &CreatePackRequest{
+			Description:           cell("description"),
+			SenderName:            cell("sender"),
+			ReceiverName:          cell("recipient"),
+			EstimatedDeliveryDate: cell("estimated_delivery_date"),
+		}
+
+		if *request == (CreatePackRequest{}) {
+			continue
+		}
+
+		rows = append(rows, &bulkRow{row: i + 2, request: request})
+	}
+
+	return h.createRows(ctx, rows)
+}
+
+// createRows validates the rows and creates the valid ones at once.
+func (h *handler) createRows(ctx *fiber.Ctx, rows []*bulkRow) error {
+	if len(rows) > h.bulkMaxRows {
+		return ErrTooManyRows
+	}
+
+	report := &BulkReportJSON{
+		Total: len(rows),
+		Rows:  make([]*BulkRowJSON, 0, len(rows)),
+	}
+
+	packs := []*Entity{}
+	created := []*BulkRowJSON{}
+
+	for _, row := range rows {
+		rowJSON := &BulkRowJSON{Row: row.row}
+		report.Rows = append(report.Rows, rowJSON)
+
+		err := validator.ValidateStruct(row.request)
+		if err != nil {
+			rowJSON.Status = BulkRowInvalid
+			rowJSON.Errors = validator.Fields(err)
+			report.Invalid++
+
+			continue
+		}
+
+		packs = append(packs, row.request.ToEntity())
+		created = append(created, rowJSON)
+	}
+
+	packs, err := h.service.CreatePacks(ctx.UserContext(), packs)
+	if err != nil {
+		return err
+	}
+
+	for i, pack := range packs {
+		created[i].Status = BulkRowCreated
+		created[i].Pack = h.packEntityToJSON(pack)
+	}
+
+	report.Created = len(packs)
+
+	return ctx.Status(fiber.StatusOK).JSON(report)
+}
+
 func (h *handler) listPacks(ctx *fiber.Ctx) error {
 	queries := &ListPackQuery{}
 	if err := ctx.QueryParser(queries); err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return cerrors.ErrInvalidQuery
 	}
 
-	filters := &ListFilters{
-		SenderName:   queries.SenderName,
-		ReceiverName: queries.ReceiverName,
-		PageSize:     queries.PageSize,
-		PageCursor:   queries.PageCursor,
+	err := validator.ValidateStruct(queries)
+	if err != nil {
+		return err
+	}
+
+	includeTotal, includeFacets, ok := queries.includes()
+	if !ok {
+		return ErrInvalidFilters
 	}
 
-	packs, metadata, err := h.service.ListPacks(ctx.Context(), filters)
+	filters := queries.ToFilters()
+
+	packs, metadata, err := h.service.ListPacks(ctx.UserContext(), filters)
 	if err != nil {
-		return h.errorHandler(ctx, err)
+		return err
+	}
+
+	var aggregates *Aggregates
+	if includeTotal || includeFacets {
+		aggregates, err = h.service.AggregatePacks(ctx.UserContext(), filters, includeFacets)
+		if err != nil {
+			return err
+		}
 	}
 
 	packsJSON := make([]*PackJSON, 0, len(packs))
@@ -152,86 +517,393 @@ func (h *handler) listPacks(ctx *fiber.Ctx) error {
 		},
 	}
 
+	if includeTotal {
+		resp.Metadata.Total = &aggregates.Total
+	}
+
+	if includeFacets {
+		resp.Facets = h.aggregatesToFacetsJSON(aggregates)
+	}
+
 	return ctx.Status(fiber.StatusOK).JSON(resp)
 }
 
+// exportPacks streams the packs matching the filters, the status is sent
+// before the packs are read, so a failure while streaming truncates the file
+// and is only logged.
+func (h *handler) exportPacks(ctx *fiber.Ctx) error {
+	queries := &ExportPackQuery{}
+	if err := ctx.QueryParser(queries); err != nil {
+		return cerrors.ErrInvalidQuery
+	}
+
+	err := validator.ValidateStruct(queries)
+	if err != nil {
+		return err
+	}
+
+	format, err := export.ParseFormat(queries.Format)
+	if err != nil {
+		return ErrInvalidExportFormat
+	}
+
+	filters := queries.ToFilters()
+	filters.WithEvents = queries.WithEvents
+
+	userCtx := ctx.UserContext()
+
+	packs, err := h.service.ExportPacks(userCtx, filters)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, format.ContentType())
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="packs.%s"`, format))
+	ctx.Status(fiber.StatusOK)
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer, err := export.NewWriter[PackExportRow](w, format, exportRowGroupSize)
+		if err != nil {
+			logger.FromContext(userCtx).ErrorContext(userCtx, "error exporting packs", logger.Error(err))
+			return
+		}
+
+		for pack, err := range packs {
+			if err == nil {
+				err = writer.Write(packEntityToExportRow(pack, filters.WithEvents))
+			}
+
+			if err != nil {
+				logger.FromContext(userCtx).ErrorContext(userCtx, "error exporting packs", logger.Error(err))
+				return
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			logger.FromContext(userCtx).ErrorContext(userCtx, "error exporting packs", logger.Error(err))
+		}
+	})
+
+	return nil
+}
+
 func (h handler) getPackByID(ctx *fiber.Ctx) error {
 	params := &PackIDParam{}
 	if err := ctx.ParamsParser(params); err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return cerrors.ErrInvalidParams
 	}
 
 	queries := &GetPackQuery{}
 	if err := ctx.QueryParser(queries); err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return cerrors.ErrInvalidQuery
 	}
 
-	pack, err := h.service.GetPackByID(ctx.Context(), params.ID, queries.WithEvents)
+	pack, err := h.service.GetPackByID(ctx.UserContext(), params.ID, queries.WithEvents)
 	if err != nil {
-		return h.errorHandler(ctx, err)
+		return err
 	}
 
-	cacheMaxAge := 60
+	cacheMaxAge := h.cacheMaxAgeCreated
 	if pack.Status != StatusCreated {
-		cacheMaxAge = 3600
+		cacheMaxAge = h.cacheMaxAgeInFlight
 	}
 
-	ctx.Response().Header.Set("Cache-Control", fmt.Sprintf("max-age=%d", cacheMaxAge))
+	ctx.Response().Header.Set("Cache-Control", fmt.Sprintf("max-age=%d", int(cacheMaxAge.Seconds())))
+	h.setETag(ctx, pack)
+
+	if ctx.Get(fiber.HeaderIfNoneMatch) == packETag(pack.Version) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
 
 	return ctx.
 		Status(fiber.StatusOK).
 		JSON(h.packEntityToJSON(pack))
 }
 
+func (h *handler) getTracking(ctx *fiber.Ctx) error {
+	params := &TrackingCodeParam{}
+	if err := ctx.ParamsParser(params); err != nil {
+		return cerrors.ErrInvalidParams
+	}
+
+	tracking, err := h.service.GetTracking(ctx.UserContext(), params.Code)
+	if err != nil {
+		return err
+	}
+
+	cacheMaxAge := h.cacheMaxAgeCreated
+	if tracking.Status != StatusCreated {
+		cacheMaxAge = h.cacheMaxAgeInFlight
+	}
+
+	ctx.Response().Header.Set("Cache-Control", fmt.Sprintf("max-age=%d", int(cacheMaxAge.Seconds())))
+
+	return ctx.
+		Status(fiber.StatusOK).
+		JSON(h.trackingToJSON(tracking))
+}
+
 func (h *handler) updatePackStatusByID(ctx *fiber.Ctx) error {
 	params := &UpdatePackStatusRequest{}
 	if err := ctx.ParamsParser(params); err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return cerrors.ErrInvalidParams
 	}
 
 	payload := &UpdatePackStatusRequest{}
 	if err := ctx.BodyParser(payload); err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return cerrors.ErrInvalidBody
+	}
+
+	err := validator.ValidateStruct(payload)
+	if err != nil {
+		return err
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return ErrVersionConflict
+	}
+
+	entity := payload.ToEntity()
+	entity.Version = version
+
+	pack, err := h.service.UpdatePackStatusByID(ctx.UserContext(), params.ID, entity)
+	if err != nil {
+		return err
+	}
+
+	h.setETag(ctx, pack)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.packEntityToJSON(pack))
+}
+
+func (h *handler) replacePackDetailsByID(ctx *fiber.Ctx) error {
+	params := &PackIDParam{}
+	if err := ctx.ParamsParser(params); err != nil {
+		return cerrors.ErrInvalidParams
+	}
+
+	payload := &ReplacePackDetailsRequest{}
+	if err := ctx.BodyParser(payload); err != nil {
+		return cerrors.ErrInvalidBody
+	}
+
+	err := validator.ValidateStruct(payload)
+	if err != nil {
+		return cerrors.NewValidationError(ErrInvalidDetails, err)
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return ErrVersionConflict
+	}
+
+	details := payload.ToDetails()
+	details.Version = version
+
+	pack, err := h.service.UpdatePackDetailsByID(ctx.UserContext(), params.ID, details)
+	if err != nil {
+		return err
+	}
+
+	h.setETag(ctx, pack)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.packEntityToJSON(pack))
+}
+
+func (h *handler) updatePackDetailsByID(ctx *fiber.Ctx) error {
+	params := &PackIDParam{}
+	if err := ctx.ParamsParser(params); err != nil {
+		return cerrors.ErrInvalidParams
+	}
+
+	payload := &UpdatePackDetailsRequest{}
+	if err := ctx.BodyParser(payload); err != nil {
+		return cerrors.ErrInvalidBody
 	}
 
 	err := validator.ValidateStruct(payload)
 	if err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return cerrors.NewValidationError(ErrInvalidDetails, err)
+	}
+
+	if payload.Description == nil && payload.ReceiverName == nil && payload.EstimatedDeliveryDate == nil {
+		return ErrInvalidDetails
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return ErrVersionConflict
 	}
 
-	pack, err := h.service.UpdatePackStatusByID(ctx.Context(), params.ID, payload.ToEntity())
+	details := payload.ToDetails()
+	details.Version = version
+
+	pack, err := h.service.UpdatePackDetailsByID(ctx.UserContext(), params.ID, details)
 	if err != nil {
-		return h.errorHandler(ctx, err)
+		return err
 	}
 
+	h.setETag(ctx, pack)
+
 	return ctx.Status(fiber.StatusOK).JSON(h.packEntityToJSON(pack))
 }
 
 func (h *handler) cancelPackStatusByID(ctx *fiber.Ctx) error {
 	params := &PackIDParam{}
 	if err := ctx.ParamsParser(params); err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return cerrors.ErrInvalidParams
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return ErrVersionConflict
+	}
+
+	pack, err := h.service.CancelPackStatusByID(ctx.UserContext(), params.ID, version)
+	if err != nil {
+		return err
+	}
+
+	h.setETag(ctx, pack)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.packEntityToJSON(pack))
+}
+
+func (h *handler) assignCarrierByID(ctx *fiber.Ctx) error {
+	params := &PackIDParam{}
+	if err := ctx.ParamsParser(params); err != nil {
+		return cerrors.ErrInvalidParams
+	}
+
+	payload := &AssignCarrierRequest{}
+	if err := ctx.BodyParser(payload); err != nil {
+		return cerrors.ErrInvalidBody
+	}
+
+	err := validator.ValidateStruct(payload)
+	if err != nil {
+		return err
 	}
 
-	pack, err := h.service.CancelPackStatusByID(ctx.Context(), params.ID)
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return ErrVersionConflict
+	}
+
+	pack, err := h.service.AssignCarrier(ctx.UserContext(), params.ID, &CarrierAssignment{
+		CarrierID:      payload.CarrierID,
+		TrackingNumber: payload.TrackingNumber,
+		Version:        version,
+	})
 	if err != nil {
-		return h.errorHandler(ctx, err)
+		return err
 	}
 
+	h.setETag(ctx, pack)
+
 	return ctx.Status(fiber.StatusOK).JSON(h.packEntityToJSON(pack))
 }
 
-func (h *handler) errorHandler(ctx *fiber.Ctx, err error) error {
-	if cerrors.Is(err, ErrPackNotFound) {
-		return ctx.Status(fiber.StatusNotFound).JSON(err)
+func (h *handler) setETag(ctx *fiber.Ctx, pack *Entity) {
+	ctx.Set(fiber.HeaderETag, packETag(pack.Version))
+}
+
+// packETag is the strong ETag of the pack version.
+func packETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion parses the If-Match header, the version is 0 when it is
+// missing or "*". It is not ok when the header isn't a pack ETag.
+func ifMatchVersion(ctx *fiber.Ctx) (int, bool) {
+	ifMatch := strings.TrimSpace(ctx.Get(fiber.HeaderIfMatch))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, true
+	}
+
+	unquoted, found := strings.CutPrefix(ifMatch, `"`)
+	if !found {
+		return 0, false
+	}
+
+	unquoted, found = strings.CutSuffix(unquoted, `"`)
+	if !found {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+func (q *ListPackQuery) ToFilters() *ListFilters {
+	filters := &ListFilters{
+		SenderName:            q.SenderName,
+		ReceiverName:          q.ReceiverName,
+		CreatedFrom:           parseDate(q.CreatedFrom),
+		CreatedTo:             parseDate(q.CreatedTo),
+		DeliveredFrom:         parseDate(q.DeliveredFrom),
+		DeliveredTo:           parseDate(q.DeliveredTo),
+		EstimatedDeliveryFrom: parseDate(q.EstimatedDeliveryFrom),
+		EstimatedDeliveryTo:   parseDate(q.EstimatedDeliveryTo),
+		IsHoliday:             q.IsHoliday,
+		Overdue:               q.Overdue,
+		Text:                  q.Text,
+		Sort:                  ListSort(q.Sort),
+		SortDirection:         strings.ToUpper(q.Order),
+		PageSize:              q.PageSize,
+		PageCursor:            q.PageCursor,
+	}
+
+	for _, statuses := range q.Statuses {
+		for _, status := range strings.Split(statuses, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filters.Statuses = append(filters.Statuses, Status(strings.ToUpper(status)))
+			}
+		}
+	}
+
+	return filters
+}
+
+// includes parses the include query, repeated or comma separated, it is not
+// ok when an unknown value is informed.
+func (q *ListPackQuery) includes() (bool, bool, bool) {
+	includeTotal, includeFacets := false, false
+
+	for _, includes := range q.Include {
+		for _, include := range strings.Split(includes, ",") {
+			switch strings.TrimSpace(include) {
+			case includeTotalValue:
+				includeTotal = true
+			case includeFacetsValue:
+				includeFacets = true
+			case "":
+			default:
+				return false, false, false
+			}
+		}
+	}
+
+	return includeTotal, includeFacets, true
+}
+
+// parseDate parses an already validated YYYY-MM-DD date.
+func parseDate(date *string) *time.Time {
+	if date == nil || *date == "" {
+		return nil
 	}
 
-	if cerrors.Is(err, ErrStatusInvalid) ||
-		cerrors.Is(err, ErrCannotCancel) {
-		return ctx.Status(fiber.StatusBadRequest).JSON(err)
+	parsed, err := time.ParseInLocation(time.DateOnly, *date, time.Local)
+	if err != nil {
+		return nil
 	}
 
-	return ctx.SendStatus(fiber.StatusInternalServerError)
+	return &parsed
 }
 
 func (r *UpdatePackStatusRequest) ToEntity() *Entity {
@@ -240,6 +912,22 @@ func (r *UpdatePackStatusRequest) ToEntity() *Entity {
 	}
 }
 
+func (r *UpdatePackDetailsRequest) ToDetails() *Details {
+	return &Details{
+		Description:           r.Description,
+		ReceiverName:          r.ReceiverName,
+		EstimatedDeliveryDate: r.EstimatedDeliveryDate,
+	}
+}
+
+func (r *ReplacePackDetailsRequest) ToDetails() *Details {
+	return &Details{
+		Description:           &r.Description,
+		ReceiverName:          &r.ReceiverName,
+		EstimatedDeliveryDate: &r.EstimatedDeliveryDate,
+	}
+}
+
 func (r *CreatePackRequest) ToEntity() *Entity {
 	return &Entity{
 		Description:           r.Description,
@@ -259,13 +947,19 @@ func (h *handler) packEntityToJSON(pack *Entity) *PackJSON {
 	}
 
 	resp := &PackJSON{
-		ID:           pack.ID,
-		Description:  pack.Description,
-		Status:       pack.Status,
-		ReceiverName: pack.Receiver.Name,
-		SenderName:   pack.Sender.Name,
-		CreatedAt:    pack.CreatedAt,
-		UpdateAt:     pack.UpdatedAt,
+		ID:                    pack.ID,
+		TrackingCode:          pack.TrackingCode,
+		Description:           pack.Description,
+		Status:                pack.Status,
+		ReceiverName:          pack.Receiver.Name,
+		SenderName:            pack.Sender.Name,
+		EstimatedDeliveryDate: pack.EstimatedDeliveryDate,
+		IsHoliday:             pack.IsHoliday,
+		CarrierID:             pack.CarrierID,
+		TrackingNumber:        pack.TrackingNumber,
+		Version:               pack.Version,
+		CreatedAt:             pack.CreatedAt,
+		UpdateAt:              pack.UpdatedAt,
 	}
 
 	if pack.DeliveredAt != nil {
@@ -276,6 +970,10 @@ func (h *handler) packEntityToJSON(pack *Entity) *PackJSON {
 		resp.CanceledAt = pack.CanceledAt
 	}
 
+	if pack.OverdueSince != nil {
+		resp.OverdueSince = pack.OverdueSince
+	}
+
 	if len(pack.Events) > 0 {
 		for _, event := range pack.Events {
 			resp.Events = append(resp.Events, EventJSON{
@@ -290,3 +988,120 @@ func (h *handler) packEntityToJSON(pack *Entity) *PackJSON {
 
 	return resp
 }
+
+func (h *handler) trackingToJSON(tracking *Tracking) *TrackingJSON {
+	resp := &TrackingJSON{
+		TrackingCode:          tracking.Code,
+		Status:                tracking.Status,
+		EstimatedDeliveryDate: tracking.EstimatedDeliveryDate,
+		DeliveredAt:           tracking.DeliveredAt,
+		CanceledAt:            tracking.CanceledAt,
+		UpdatedAt:             tracking.UpdatedAt,
+		Events:                make([]TrackingEventJSON, 0, len(tracking.Events)),
+	}
+
+	if tracking.Carrier != nil {
+		resp.Carrier = &TrackingCarrierJSON{
+			Name:           tracking.Carrier.Name,
+			TrackingNumber: tracking.Carrier.TrackingNumber,
+		}
+	}
+
+	for _, event := range tracking.Events {
+		resp.Events = append(resp.Events, TrackingEventJSON{
+			Description: event.Description,
+			Location:    event.Location,
+			Date:        event.Date,
+		})
+	}
+
+	return resp
+}
+
+func packEntityToExportRow(pack *Entity, withEvents bool) PackExportRow {
+	row := PackExportRow{
+		ID:                    pack.ID,
+		TrackingCode:          pack.TrackingCode,
+		Description:           pack.Description,
+		Status:                pack.Status,
+		SenderName:            pack.Sender.Name,
+		ReceiverName:          pack.Receiver.Name,
+		EstimatedDeliveryDate: pack.EstimatedDeliveryDate,
+		IsHoliday:             pack.IsHoliday,
+		CreatedAt:             pack.CreatedAt,
+		UpdatedAt:             pack.UpdatedAt,
+		DeliveredAt:           pack.DeliveredAt,
+		CanceledAt:            pack.CanceledAt,
+		OverdueSince:          pack.OverdueSince,
+	}
+
+	if withEvents {
+		row.Events = make([]EventExportRow, 0, len(pack.Events))
+
+		for _, event := range pack.Events {
+			row.Events = append(row.Events, EventExportRow{
+				ID:          event.ID,
+				Description: event.Description,
+				Location:    event.Location,
+				Date:        event.Date,
+			})
+		}
+	}
+
+	return row
+}
+
+func (r PackExportRow) CSVHeader() []string {
+	return []string{
+		"id", "description", "status", "sender", "recipient", "estimated_delivery_date", "is_holiday",
+		"created_at", "updated_at", "delivered_at", "canceled_at", "overdue_since", "events", "tracking_code",
+	}
+}
+
+func (r PackExportRow) CSVRecord() []string {
+	isHoliday := ""
+	if r.IsHoliday != nil {
+		isHoliday = strconv.FormatBool(*r.IsHoliday)
+	}
+
+	trackingCode := ""
+	if r.TrackingCode != nil {
+		trackingCode = *r.TrackingCode
+	}
+
+	events := ""
+	if r.Events != nil {
+		encoded, _ := json.Marshal(r.Events)
+		events = string(encoded)
+	}
+
+	return []string{
+		r.ID, r.Description, string(r.Status), r.SenderName, r.ReceiverName, r.EstimatedDeliveryDate, isHoliday,
+		formatExportTime(&r.CreatedAt), formatExportTime(&r.UpdatedAt), formatExportTime(r.DeliveredAt),
+		formatExportTime(r.CanceledAt), formatExportTime(r.OverdueSince), events, trackingCode,
+	}
+}
+
+func formatExportTime(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+
+	return date.Format(time.RFC3339)
+}
+
+func (h *handler) aggregatesToFacetsJSON(aggregates *Aggregates) *FacetsJSON {
+	facets := &FacetsJSON{
+		Status: aggregates.Statuses,
+		Sender: make([]*FacetCountJSON, 0, len(aggregates.Senders)),
+	}
+
+	for _, sender := range aggregates.Senders {
+		facets.Sender = append(facets.Sender, &FacetCountJSON{
+			Value: sender.Value,
+			Count: sender.Count,
+		})
+	}
+
+	return facets
+}
@@ -3,6 +3,7 @@ package pack
 import (
 	"context"
 	"pack-management/internal/domain/person"
+	"pack-management/internal/domain/tenant"
 	"pack-management/internal/pkg/pagination"
 	"time"
 
@@ -12,16 +13,28 @@ import (
 type (
 	Repository interface {
 		Create(ctx context.Context, pack *Entity) error
+		CreateMany(ctx context.Context, packs []*Entity) error
 		List(ctx context.Context, filters *ListFilters) ([]*Entity, *pagination.Metadata, error)
+		Aggregate(ctx context.Context, filters *ListFilters, withFacets bool) (*Aggregates, error)
 		UpdateByID(ctx context.Context, ID string, pack *Entity) error
+		UpdateDetailsByID(ctx context.Context, ID string, pack *Entity, dateChanged bool) error
 		UpdateFunFactByID(ctx context.Context, ID string, funFact string) error
-		UpdateIsHolidayByID(ctx context.Context, ID string, isHoliday bool) error
+		UpdateIsHolidayByID(ctx context.Context, ID string, estimatedDeliveryDate string, isHoliday bool) error
 		GetByID(ctx context.Context, ID string, withEvents bool) (*Entity, error)
+		GetByTrackingCode(ctx context.Context, code string) (*Entity, error)
+		MarkOverdue(ctx context.Context, today time.Time, limit int) ([]*Entity, error)
+		ClearOverdue(ctx context.Context, today time.Time) (int, error)
+		CountOverdue(ctx context.Context) (int, error)
+		ListMissingEnrichment(ctx context.Context, createdBefore time.Time, limit int) ([]*Entity, error)
+		AssignMissingTrackingCodes(ctx context.Context, limit int) (int, error)
+		RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
 	}
 
 	Model struct {
 		bun.BaseModel         `bun:"table:pack,alias:pack"`
 		ID                    string        `bun:"id,pk"`
+		TenantID              string        `bun:"tenant_id"`
+		TrackingCode          *string       `bun:"tracking_code"`
 		Description           string        `bun:"description"`
 		FunFact               *string       `bun:"fun_fact"`
 		IsHoliday             *bool         `bun:"is_holiday"`
@@ -29,6 +42,10 @@ type (
 		EstimatedDeliveryDate time.Time     `bun:"estimated_delivery_date"`
 		DeliveredAt           *time.Time    `bun:"delivered_at"`
 		CanceledAt            *time.Time    `bun:"canceled_at"`
+		OverdueSince          *time.Time    `bun:"overdue_since"`
+		CarrierID             *string       `bun:"carrier_id"`
+		TrackingNumber        *string       `bun:"tracking_number"`
+		Version               int           `bun:"version"`
 		CreatedAt             time.Time     `bun:"created_at"`
 		UpdatedAt             time.Time     `bun:"updated_at"`
 		ReceiverID            *string       `bun:"receiver_id"`
@@ -36,6 +53,12 @@ type (
 		SenderID              *string       `bun:"sender_id"`
 		Sender                *person.Model `bun:"rel:belongs-to"`
 		Events                []*EventModel `bun:"rel:has-many,join:id=pack_id"`
+		tenant.Scoped
+	}
+
+	countModel struct {
+		Value string `bun:"value"`
+		Count int    `bun:"count"`
 	}
 
 	EventModel struct {
@@ -45,6 +68,7 @@ type (
 		Description   string    `bun:"description"`
 		Location      string    `bun:"location"`
 		Date          time.Time `bun:"date"`
+		tenant.Scoped
 	}
 )
 
@@ -66,6 +90,8 @@ func (m *Model) ToEntity() *Entity {
 
 	return &Entity{
 		ID:                    m.ID,
+		TenantID:              m.TenantID,
+		TrackingCode:          m.TrackingCode,
 		Description:           m.Description,
 		FunFact:               m.FunFact,
 		IsHoliday:             m.IsHoliday,
@@ -73,6 +99,10 @@ func (m *Model) ToEntity() *Entity {
 		EstimatedDeliveryDate: estimatedDeliveryDate,
 		DeliveredAt:           m.DeliveredAt,
 		CanceledAt:            m.CanceledAt,
+		OverdueSince:          m.OverdueSince,
+		CarrierID:             m.CarrierID,
+		TrackingNumber:        m.TrackingNumber,
+		Version:               m.Version,
 		CreatedAt:             m.CreatedAt,
 		UpdatedAt:             m.UpdatedAt,
 		Receiver:              m.Receiver.ToEntity(),
@@ -4,34 +4,88 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/database"
+	"pack-management/internal/pkg/fulltext"
 	"pack-management/internal/pkg/pagination"
+	"pack-management/internal/pkg/trackingcode"
 	"pack-management/internal/pkg/uuid"
 	"pack-management/internal/pkg/validator"
+	"slices"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/uptrace/bun"
 )
 
 type (
 	RepositoryParams struct {
-		DB *bun.DB `validate:"required"`
+		DB           *bun.DB            `validate:"required"`
+		CursorSigner *pagination.Signer `validate:"required"`
+		// TrackingCodePrefix is the prefix of the tracking codes, optional,
+		// BR by default.
+		TrackingCodePrefix string `validate:"omitempty,len=2,alpha,uppercase"`
 	}
 
 	mysqlRepository struct {
-		db *bun.DB
+		db                 *bun.DB
+		cursorSigner       *pagination.Signer
+		trackingCodePrefix string
+	}
+
+	// listCursorFilters are the filters a list cursor is bound to.
+	listCursorFilters struct {
+		SenderName            *string    `json:"sender_name,omitempty"`
+		ReceiverName          *string    `json:"recipient_name,omitempty"`
+		Statuses              []Status   `json:"status,omitempty"`
+		CreatedFrom           *time.Time `json:"created_from,omitempty"`
+		CreatedTo             *time.Time `json:"created_to,omitempty"`
+		DeliveredFrom         *time.Time `json:"delivered_from,omitempty"`
+		DeliveredTo           *time.Time `json:"delivered_to,omitempty"`
+		EstimatedDeliveryFrom *time.Time `json:"estimated_delivery_from,omitempty"`
+		EstimatedDeliveryTo   *time.Time `json:"estimated_delivery_to,omitempty"`
+		IsHoliday             *bool      `json:"is_holiday,omitempty"`
+		Overdue               *bool      `json:"overdue,omitempty"`
+		Text                  *string    `json:"q,omitempty"`
 	}
 )
 
 var (
-	paginationDefaultOrder = pagination.DescDirection
-	paginationCursorField  = "ID"
+	allStatuses = []Status{StatusCreated, StatusInTransit, StatusDelivered, StatusCanceled}
+
+	// openStatuses are the statuses of packs not delivered nor canceled yet.
+	openStatuses = []Status{StatusCreated, StatusInTransit}
+
+	listSortColumns = map[ListSort]pagination.SortField{
+		ListSortCreatedAt:             {Column: "created_at", Field: "CreatedAt"},
+		ListSortEstimatedDeliveryDate: {Column: "estimated_delivery_date", Field: "EstimatedDeliveryDate"},
+	}
+)
+
+const (
+	senderFacetLimit = 10
+	createChunkSize  = 200
+
+	mysqlDuplicateEntry = 1062
+
+	defaultTrackingCodePrefix = "BR"
+	// trackingCodeAttempts is how many codes are tried before giving up, a
+	// collision is already unlikely.
+	trackingCodeAttempts = 5
 )
 
 func NewMysqlRepository(params *RepositoryParams) Repository {
 	params.validate()
 
+	if params.TrackingCodePrefix == "" {
+		params.TrackingCodePrefix = defaultTrackingCodePrefix
+	}
+
 	return &mysqlRepository{
-		db: params.DB,
+		db:                 params.DB,
+		cursorSigner:       params.CursorSigner,
+		trackingCodePrefix: params.TrackingCodePrefix,
 	}
 }
 
@@ -43,16 +97,81 @@ func (p *RepositoryParams) validate() {
 }
 
 func (r *mysqlRepository) Create(ctx context.Context, pack *Entity) error {
+	tenantID, err := tenant.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
 	pack.ID = r.newID()
+	pack.TenantID = tenantID
+	pack.Version = 1
 	pack.CreatedAt = time.Now()
 	pack.UpdatedAt = time.Now()
 
-	_, err := r.db.NewInsert().Model(pack.ToModel()).Exec(ctx)
+	for attempt := 1; ; attempt++ {
+		pack.TrackingCode, err = r.newTrackingCode()
+		if err != nil {
+			return err
+		}
+
+		_, err = r.db.NewInsert().Model(pack.ToModel()).Exec(ctx)
+		if isTrackingCodeCollision(err) && attempt < trackingCodeAttempts {
+			continue
+		}
+
+		return err
+	}
+}
+
+// CreateMany inserts the packs in chunks within a transaction, joining the
+// one of ctx if any, so either all of them are created or none. A chunk
+// colliding with a tracking code is retried with new codes, the failed
+// statement doesn't abort the transaction.
+func (r *mysqlRepository) CreateMany(ctx context.Context, packs []*Entity) error {
+	tenantID, err := tenant.FromContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	now := time.Now()
+
+	for _, pack := range packs {
+		pack.ID = r.newID()
+		pack.TenantID = tenantID
+		pack.Version = 1
+		pack.CreatedAt = now
+		pack.UpdatedAt = now
+	}
+
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx bun.Tx) error {
+		for chunk := range slices.Chunk(packs, createChunkSize) {
+			for attempt := 1; ; attempt++ {
+				models := make([]*Model, 0, len(chunk))
+
+				for _, pack := range chunk {
+					pack.TrackingCode, err = r.newTrackingCode()
+					if err != nil {
+						return err
+					}
+
+					models = append(models, pack.ToModel())
+				}
+
+				_, err = tx.NewInsert().Model(&models).Exec(ctx)
+				if isTrackingCodeCollision(err) && attempt < trackingCodeAttempts {
+					continue
+				}
+
+				if err != nil {
+					return err
+				}
+
+				break
+			}
+		}
+
+		return nil
+	})
 }
 
 func (r *mysqlRepository) List(ctx context.Context, filters *ListFilters) ([]*Entity, *pagination.Metadata, error) {
@@ -63,21 +182,39 @@ func (r *mysqlRepository) List(ctx context.Context, filters *ListFilters) ([]*En
 		Relation("Receiver").
 		Limit(filters.PageSize + 1)
 
-	if filters.SenderName != nil {
-		query.Where("sender.name = ?", *filters.SenderName)
+	if filters.WithEvents {
+		query.Relation("Events")
 	}
 
-	if filters.ReceiverName != nil {
-		query.Where("receiver.name = ?", *filters.ReceiverName)
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "pack.tenant_id"); err != nil {
+		return nil, nil, err
 	}
 
-	query, cursorDirection, err := pagination.BuildCursorQuery(
-		pagination.CursorConfig{
-			PageSize:      filters.PageSize,
-			PageCursor:    filters.PageCursor,
-			CursorField:   paginationCursorField,
-			OrderStrategy: paginationDefaultOrder,
-		}, query)
+	applyListFilters(query, filters)
+
+	cursorConfig := pagination.CursorConfig{
+		PageSize:   filters.PageSize,
+		PageCursor: filters.PageCursor,
+		TableAlias: "pack",
+		SortFields: listSortFields(filters),
+		Signer:     r.cursorSigner,
+		Filters: listCursorFilters{
+			SenderName:            filters.SenderName,
+			ReceiverName:          filters.ReceiverName,
+			Statuses:              filters.Statuses,
+			CreatedFrom:           filters.CreatedFrom,
+			CreatedTo:             filters.CreatedTo,
+			DeliveredFrom:         filters.DeliveredFrom,
+			DeliveredTo:           filters.DeliveredTo,
+			EstimatedDeliveryFrom: filters.EstimatedDeliveryFrom,
+			EstimatedDeliveryTo:   filters.EstimatedDeliveryTo,
+			IsHoliday:             filters.IsHoliday,
+			Overdue:               filters.Overdue,
+			Text:                  filters.Text,
+		},
+	}
+
+	query, cursor, err := pagination.BuildCursorQuery(cursorConfig, query)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -86,16 +223,7 @@ func (r *mysqlRepository) List(ctx context.Context, filters *ListFilters) ([]*En
 		return nil, nil, err
 	}
 
-	items, metadata, err := pagination.BuildMetadata(
-		pagination.CursorConfig{
-			PageSize:        filters.PageSize,
-			PageCursor:      filters.PageCursor,
-			CursorField:     paginationCursorField,
-			CursorDirection: cursorDirection,
-			OrderStrategy:   paginationDefaultOrder,
-		},
-		packs,
-	)
+	items, metadata, err := pagination.BuildMetadata(cursorConfig, cursor, packs)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -108,14 +236,333 @@ func (r *mysqlRepository) List(ctx context.Context, filters *ListFilters) ([]*En
 	return entities, metadata, nil
 }
 
+// MarkOverdue sets overdue_since of the open packs with the estimated
+// delivery date before today that are not marked yet, returning them. It
+// runs across all tenants, as the overdue methods below. The packs are locked
+// until they are marked, so a concurrent run waits and then skips them, MySQL
+// 5.7 has no SKIP LOCKED.
+func (r *mysqlRepository) MarkOverdue(ctx context.Context, today time.Time, limit int) ([]*Entity, error) {
+	packs := make([]*Model, 0)
+
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		ids := make([]string, 0)
+
+		// only the pack rows are locked, the relations are loaded after
+		err := tx.NewSelect().
+			Model((*Model)(nil)).
+			Column("pack.id").
+			Where("pack.status IN (?)", bun.In(openStatuses)).
+			Where("pack.estimated_delivery_date < ?", today).
+			Where("pack.overdue_since IS NULL").
+			OrderExpr("pack.estimated_delivery_date ASC").
+			Limit(limit).
+			For("UPDATE").
+			Scan(ctx, &ids)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*Model)(nil)).
+			Set("overdue_since = ?", time.Now()).
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		return tx.NewSelect().
+			Model(&packs).
+			Relation("Sender").
+			Relation("Receiver").
+			Where("pack.id IN (?)", bun.In(ids)).
+			OrderExpr("pack.estimated_delivery_date ASC").
+			Scan(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*Entity, 0, len(packs))
+	for _, pack := range packs {
+		entities = append(entities, pack.ToEntity())
+	}
+
+	return entities, nil
+}
+
+// ClearOverdue unsets overdue_since of the open packs that are not overdue
+// anymore, e.g.: the estimated delivery date was postponed.
+func (r *mysqlRepository) ClearOverdue(ctx context.Context, today time.Time) (int, error) {
+	result, err := r.db.NewUpdate().
+		Model((*Model)(nil)).
+		Set("overdue_since = NULL").
+		Where("status IN (?)", bun.In(openStatuses)).
+		Where("overdue_since IS NOT NULL").
+		Where("estimated_delivery_date >= ?", today).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cleared, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(cleared), nil
+}
+
+func (r *mysqlRepository) CountOverdue(ctx context.Context) (int, error) {
+	return r.db.NewSelect().
+		Model((*Model)(nil)).
+		Where("pack.status IN (?)", bun.In(openStatuses)).
+		Where("pack.overdue_since IS NOT NULL").
+		Count(ctx)
+}
+
+// ListMissingEnrichment lists the not canceled packs created before the date
+// without the fun fact or the holiday flag, the oldest first, of all tenants.
+func (r *mysqlRepository) ListMissingEnrichment(ctx context.Context, createdBefore time.Time, limit int) ([]*Entity, error) {
+	packs := make([]*Model, 0)
+
+	err := r.db.NewSelect().
+		Model(&packs).
+		Relation("Sender").
+		Relation("Receiver").
+		Where("pack.fun_fact IS NULL OR pack.is_holiday IS NULL").
+		Where("pack.status != ?", StatusCanceled).
+		Where("pack.created_at < ?", createdBefore).
+		OrderExpr("pack.created_at ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*Entity, 0, len(packs))
+	for _, pack := range packs {
+		entities = append(entities, pack.ToEntity())
+	}
+
+	return entities, nil
+}
+
+// Aggregate counts the packs matching the filters grouped by status, the
+// total is their sum. The sender facet has the top senders only.
+func (r *mysqlRepository) Aggregate(ctx context.Context, filters *ListFilters, withFacets bool) (*Aggregates, error) {
+	statusCounts := []*countModel{}
+
+	query := r.aggregateQuery().
+		ColumnExpr("pack.status AS value").
+		ColumnExpr("COUNT(*) AS count").
+		GroupExpr("pack.status")
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "pack.tenant_id"); err != nil {
+		return nil, err
+	}
+
+	applyListFilters(query, filters)
+
+	if err := query.Scan(ctx, &statusCounts); err != nil {
+		return nil, err
+	}
+
+	aggregates := &Aggregates{}
+	statuses := make(map[Status]int, len(allStatuses))
+
+	for _, status := range allStatuses {
+		statuses[status] = 0
+	}
+
+	for _, statusCount := range statusCounts {
+		aggregates.Total += statusCount.Count
+		statuses[Status(statusCount.Value)] = statusCount.Count
+	}
+
+	if !withFacets {
+		return aggregates, nil
+	}
+
+	senderCounts := []*countModel{}
+
+	query = r.aggregateQuery().
+		ColumnExpr("sender.name AS value").
+		ColumnExpr("COUNT(*) AS count").
+		GroupExpr("sender.id, sender.name").
+		OrderExpr("count DESC, sender.name ASC").
+		Limit(senderFacetLimit)
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "pack.tenant_id"); err != nil {
+		return nil, err
+	}
+
+	applyListFilters(query, filters)
+
+	if err := query.Scan(ctx, &senderCounts); err != nil {
+		return nil, err
+	}
+
+	aggregates.Statuses = statuses
+	aggregates.Senders = make([]*FacetCount, 0, len(senderCounts))
+
+	for _, senderCount := range senderCounts {
+		aggregates.Senders = append(aggregates.Senders, &FacetCount{
+			Value: senderCount.Value,
+			Count: senderCount.Count,
+		})
+	}
+
+	return aggregates, nil
+}
+
+// aggregateQuery joins the persons with the same aliases of the List
+// relations, so applyListFilters works on both.
+func (r *mysqlRepository) aggregateQuery() *bun.SelectQuery {
+	return r.db.NewSelect().
+		TableExpr("pack AS pack").
+		Join("JOIN person AS sender ON sender.id = pack.sender_id").
+		Join("JOIN person AS receiver ON receiver.id = pack.receiver_id")
+}
+
+// applyListFilters adds the WHERE clauses of the filters, the "to" dates are
+// inclusive so they are compared with the start of the next day.
+func applyListFilters(query *bun.SelectQuery, filters *ListFilters) {
+	if filters.SenderName != nil {
+		query.Where("sender.name = ?", *filters.SenderName)
+	}
+
+	if filters.ReceiverName != nil {
+		query.Where("receiver.name = ?", *filters.ReceiverName)
+	}
+
+	if len(filters.Statuses) > 0 {
+		query.Where("pack.status IN (?)", bun.In(filters.Statuses))
+	}
+
+	if filters.CreatedFrom != nil {
+		query.Where("pack.created_at >= ?", *filters.CreatedFrom)
+	}
+
+	if filters.CreatedTo != nil {
+		query.Where("pack.created_at < ?", nextDay(*filters.CreatedTo))
+	}
+
+	if filters.DeliveredFrom != nil {
+		query.Where("pack.delivered_at >= ?", *filters.DeliveredFrom)
+	}
+
+	if filters.DeliveredTo != nil {
+		query.Where("pack.delivered_at < ?", nextDay(*filters.DeliveredTo))
+	}
+
+	if filters.EstimatedDeliveryFrom != nil {
+		query.Where("pack.estimated_delivery_date >= ?", *filters.EstimatedDeliveryFrom)
+	}
+
+	if filters.EstimatedDeliveryTo != nil {
+		query.Where("pack.estimated_delivery_date < ?", nextDay(*filters.EstimatedDeliveryTo))
+	}
+
+	if filters.IsHoliday != nil {
+		query.Where("pack.is_holiday = ?", *filters.IsHoliday)
+	}
+
+	if filters.Overdue != nil {
+		// the packs marked by the overdue detection, as the alerts and the
+		// packs_overdue gauge
+		if *filters.Overdue {
+			query.Where("pack.status IN (?) AND pack.overdue_since IS NOT NULL", bun.In(openStatuses))
+		} else {
+			query.Where("(pack.status NOT IN (?) OR pack.overdue_since IS NULL)", bun.In(openStatuses))
+		}
+	}
+
+	if filters.Text != nil {
+		if search := fulltext.BooleanQuery(*filters.Text); search != "" {
+			query.Where("MATCH (pack.description) AGAINST (? IN BOOLEAN MODE)", search)
+		}
+	}
+}
+
+// listSortFields returns the keyset of the sort, the id breaks the ties.
+func listSortFields(filters *ListFilters) []pagination.SortField {
+	sortField := listSortColumns[filters.Sort]
+	sortField.Direction = filters.SortDirection
+
+	return []pagination.SortField{
+		sortField,
+		{Column: "id", Field: "ID", Direction: filters.SortDirection},
+	}
+}
+
+func startOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+}
+
+func nextDay(date time.Time) time.Time {
+	return startOfDay(date).AddDate(0, 0, 1)
+}
+
+// UpdateByID saves the pack when its version wasn't changed since it was
+// read, failing with ErrVersionConflict otherwise, and increases the
+// version. The tracking code, the enrichment columns and the overdue mark are
+// kept, they are set by their own updates.
 func (r *mysqlRepository) UpdateByID(ctx context.Context, ID string, pack *Entity) error {
-	pack.UpdatedAt = time.Now()
+	return r.update(ctx, ID, pack, false)
+}
+
+// UpdateDetailsByID is UpdateByID, also saving the overdue mark and the
+// holiday flag of the pack when the estimated delivery date changed, both
+// cleared for the new date, so the enrichment retry re-runs the holiday check
+// when it fails.
+func (r *mysqlRepository) UpdateDetailsByID(ctx context.Context, ID string, pack *Entity, dateChanged bool) error {
+	return r.update(ctx, ID, pack, dateChanged)
+}
+
+// update keeps the overdue mark and the holiday flag, set without changing
+// the version, unless the date changed, so a pack read before they were set
+// doesn't clear them, e.g.: a cleared mark gets a second overdue alert.
+func (r *mysqlRepository) update(ctx context.Context, ID string, pack *Entity, dateChanged bool) error {
+	model := pack.ToModel()
+	model.Version = pack.Version + 1
+	model.UpdatedAt = time.Now()
+
+	excluded := []string{"id", "tenant_id", "tracking_code", "fun_fact", "created_at"}
+	if !dateChanged {
+		excluded = append(excluded, "is_holiday", "overdue_since")
+	}
+
+	query := r.db.NewUpdate().
+		Model(model).
+		ExcludeColumn(excluded...).
+		Where("pack.id = ?", ID).
+		Where("pack.version = ?", pack.Version)
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "pack.tenant_id"); err != nil {
+		return err
+	}
+
+	result, err := query.Exec(ctx)
+	if err != nil {
+		// the carrier and tracking number unique index
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return cerrors.Wrap(err, ErrTrackingNumberInUse)
+		}
 
-	_, err := r.db.NewUpdate().Model(pack.ToModel()).Where("id = ?", ID).Exec(ctx)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+
+	pack.Version = model.Version
+	pack.UpdatedAt = model.UpdatedAt
+
 	return nil
 }
 
@@ -125,9 +572,15 @@ func (r *mysqlRepository) UpdateFunFactByID(ctx context.Context, ID string, funF
 		UpdatedAt: time.Now(),
 	}
 
-	_, err := r.db.NewUpdate().
+	query := r.db.NewUpdate().
 		Model(&model).
-		Where("id = ?", ID).Exec(ctx)
+		Column("fun_fact", "updated_at").
+		Where("pack.id = ?", ID)
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "pack.tenant_id"); err != nil {
+		return err
+	}
+
+	_, err := query.Exec(ctx)
 	if err != nil {
 		return err
 	}
@@ -135,15 +588,30 @@ func (r *mysqlRepository) UpdateFunFactByID(ctx context.Context, ID string, funF
 	return nil
 }
 
-func (r *mysqlRepository) UpdateIsHolidayByID(ctx context.Context, ID string, isHoliday bool) error {
+// UpdateIsHolidayByID sets the holiday flag checked for the estimated
+// delivery date only while the pack has that date, so the check of a previous
+// date finishing last doesn't overwrite the flag of the new one.
+func (r *mysqlRepository) UpdateIsHolidayByID(ctx context.Context, ID string, estimatedDeliveryDate string, isHoliday bool) error {
+	checkedDate, err := time.Parse(time.DateOnly, estimatedDeliveryDate)
+	if err != nil {
+		return err
+	}
+
 	model := Model{
 		IsHoliday: &isHoliday,
 		UpdatedAt: time.Now(),
 	}
 
-	_, err := r.db.NewUpdate().
+	query := r.db.NewUpdate().
 		Model(&model).
-		Where("id = ?", ID).Exec(ctx)
+		Column("is_holiday", "updated_at").
+		Where("pack.id = ?", ID).
+		Where("pack.estimated_delivery_date = ?", checkedDate)
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "pack.tenant_id"); err != nil {
+		return err
+	}
+
+	_, err = query.Exec(ctx)
 	if err != nil {
 		return err
 	}
@@ -165,6 +633,10 @@ func (r *mysqlRepository) GetByID(ctx context.Context, ID string, withEvents boo
 		query.Relation("Events")
 	}
 
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "pack.tenant_id"); err != nil {
+		return nil, err
+	}
+
 	if err := query.Scan(ctx); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -176,6 +648,101 @@ func (r *mysqlRepository) GetByID(ctx context.Context, ID string, withEvents boo
 	return pack.ToEntity(), nil
 }
 
+// GetByTrackingCode finds the pack with its events by the tracking code, of
+// any tenant, as the codes are unique.
+func (r *mysqlRepository) GetByTrackingCode(ctx context.Context, code string) (*Entity, error) {
+	pack := Model{}
+
+	err := r.db.NewSelect().
+		Model(&pack).
+		Relation("Events").
+		Where("pack.tracking_code = ?", code).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return pack.ToEntity(), nil
+}
+
+// AssignMissingTrackingCodes sets the tracking code of up to limit packs
+// created before the codes, of all tenants, returning how many were set.
+func (r *mysqlRepository) AssignMissingTrackingCodes(ctx context.Context, limit int) (int, error) {
+	ids := []string{}
+
+	err := r.db.NewSelect().
+		Model((*Model)(nil)).
+		Column("pack.id").
+		Where("pack.tracking_code IS NULL").
+		Limit(limit).
+		Scan(ctx, &ids)
+	if err != nil {
+		return 0, err
+	}
+
+	assigned := 0
+
+	for _, id := range ids {
+		for attempt := 1; ; attempt++ {
+			code, err := r.newTrackingCode()
+			if err != nil {
+				return assigned, err
+			}
+
+			_, err = r.db.NewUpdate().
+				Model((*Model)(nil)).
+				Set("tracking_code = ?", code).
+				Where("id = ?", id).
+				Where("tracking_code IS NULL").
+				Exec(ctx)
+			if isTrackingCodeCollision(err) && attempt < trackingCodeAttempts {
+				continue
+			}
+
+			if err != nil {
+				return assigned, err
+			}
+
+			break
+		}
+
+		assigned++
+	}
+
+	return assigned, nil
+}
+
+// RunInTx runs fn in a transaction the repositories join, see
+// database.RunInTx.
+func (r *mysqlRepository) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, _ bun.Tx) error {
+		return fn(ctx)
+	})
+}
+
 func (r *mysqlRepository) newID() string {
 	return idPrefix + uuid.New().String()
 }
+
+func (r *mysqlRepository) newTrackingCode() (*string, error) {
+	code, err := trackingcode.New(r.trackingCodePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// isTrackingCodeCollision reports if the error is a duplicate entry, the
+// tracking code is the only unique column set by the inserts and the backfill
+// besides the random ids.
+func isTrackingCodeCollision(err error) bool {
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
@@ -2,36 +2,85 @@ package pack
 
 import (
 	"context"
-	"log"
+	"iter"
+	"pack-management/internal/domain/carrier"
 	"pack-management/internal/domain/holiday"
 	"pack-management/internal/domain/person"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/alert"
+	"pack-management/internal/pkg/cerrors"
 	"pack-management/internal/pkg/http/dogapi"
+	"pack-management/internal/pkg/logger"
 	"pack-management/internal/pkg/pagination"
+	"pack-management/internal/pkg/tracing"
+	"pack-management/internal/pkg/trackingcode"
 	"pack-management/internal/pkg/validator"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type (
 	Service interface {
 		CreatePack(ctx context.Context, pack *Entity) (*Entity, error)
+		CreatePacks(ctx context.Context, packs []*Entity) ([]*Entity, error)
 		ListPacks(ctx context.Context, filters *ListFilters) ([]*Entity, *pagination.Metadata, error)
+		ExportPacks(ctx context.Context, filters *ListFilters) (iter.Seq2[*Entity, error], error)
+		AggregatePacks(ctx context.Context, filters *ListFilters, withFacets bool) (*Aggregates, error)
+		DetectOverdue(ctx context.Context) (int, error)
+		RetryEnrichment(ctx context.Context) (int, error)
 		GetPackByID(ctx context.Context, id string, withEvents bool) (*Entity, error)
+		GetTracking(ctx context.Context, code string) (*Tracking, error)
+		BackfillTrackingCodes(ctx context.Context) (int, error)
 		UpdatePackStatusByID(ctx context.Context, id string, pack *Entity) (*Entity, error)
-		CancelPackStatusByID(ctx context.Context, id string) (*Entity, error)
+		UpdatePackDetailsByID(ctx context.Context, id string, details *Details) (*Entity, error)
+		CancelPackStatusByID(ctx context.Context, id string, version int) (*Entity, error)
+		AssignCarrier(ctx context.Context, id string, assignment *CarrierAssignment) (*Entity, error)
+		Shutdown(ctx context.Context) error
 	}
 
+	// ListFilters are the GET /packs filters, the date ranges are inclusive
+	// and Text is a full-text search on the description.
 	ListFilters struct {
-		SenderName   *string
-		ReceiverName *string
-		PageSize     int
-		PageCursor   *string
+		SenderName            *string
+		ReceiverName          *string
+		Statuses              []Status
+		CreatedFrom           *time.Time
+		CreatedTo             *time.Time
+		DeliveredFrom         *time.Time
+		DeliveredTo           *time.Time
+		EstimatedDeliveryFrom *time.Time
+		EstimatedDeliveryTo   *time.Time
+		IsHoliday             *bool
+		Overdue               *bool
+		Text                  *string
+		Sort                  ListSort
+		SortDirection         string
+		PageSize              int
+		PageCursor            *string
+		// WithEvents loads the events of each pack.
+		WithEvents bool
 	}
 
+	ListSort string
+
 	service struct {
-		repo           Repository
-		personService  person.Service
-		dogAPIClient   dogapi.Client
-		holidayService holiday.Service
+		repo            Repository
+		personService   person.Service
+		dogAPIClient    dogapi.Client
+		holidayService  holiday.Service
+		tenantService   tenant.Service
+		carrierService  carrier.Service
+		enrichmentJobs  sync.WaitGroup
+		defaultPageSize int
+		maxPageSize     int
+		exportPageSize  int
+		alertSink       alert.Sink
+		overdueBatch    int
 	}
 
 	ServiceParams struct {
@@ -39,17 +88,90 @@ type (
 		PersonService  person.Service  `validate:"required"`
 		DogAPIClient   dogapi.Client   `validate:"required"`
 		HolidayService holiday.Service `validate:"required"`
+		// TenantService gives the holiday country of the packs.
+		TenantService  tenant.Service  `validate:"required"`
+		CarrierService carrier.Service `validate:"required"`
+		// DefaultPageSize and MaxPageSize are optional, 100 and 1000 by default.
+		DefaultPageSize int `validate:"gte=0"`
+		MaxPageSize     int `validate:"gte=0"`
+		// AlertSink receives the overdue pack alerts, optional, the logs by
+		// default. OverdueBatch is optional, 100 by default.
+		AlertSink    alert.Sink
+		OverdueBatch int `validate:"gte=0"`
+		// ExportPageSize is the page the export reads at a time, optional,
+		// 500 by default.
+		ExportPageSize int `validate:"gte=0"`
 	}
 )
 
+var (
+	ListSortCreatedAt             ListSort = "created_at"
+	ListSortEstimatedDeliveryDate ListSort = "estimated_delivery_date"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+	overdueBatch    = 100
+	exportPageSize  = 500
+
+	bulkEnrichmentConcurrency = 4
+
+	enrichmentRetryAfter = 10 * time.Minute
+	enrichmentRetryBatch = 100
+
+	trackingCodeBackfillBatch = 500
+
+	overdueAlertName = "pack_overdue"
+)
+
+var (
+	overduePacks = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "packs_overdue",
+		Help: "The number of open packs past the estimated delivery date.",
+	})
+)
+
+// RegisterMetrics registers the pack metrics, e.g.: the packs_overdue gauge.
+func RegisterMetrics(registerer prometheus.Registerer) {
+	registerer.MustRegister(overduePacks)
+}
+
 func NewService(params *ServiceParams) Service {
 	params.validate()
 
+	if params.DefaultPageSize == 0 {
+		params.DefaultPageSize = defaultPageSize
+	}
+
+	if params.MaxPageSize == 0 {
+		params.MaxPageSize = maxPageSize
+	}
+
+	if params.AlertSink == nil {
+		params.AlertSink = alert.NewLogSink()
+	}
+
+	if params.OverdueBatch == 0 {
+		params.OverdueBatch = overdueBatch
+	}
+
+	if params.ExportPageSize == 0 {
+		params.ExportPageSize = exportPageSize
+	}
+
 	return &service{
-		repo:           params.Repo,
-		personService:  params.PersonService,
-		dogAPIClient:   params.DogAPIClient,
-		holidayService: params.HolidayService,
+		repo:            params.Repo,
+		personService:   params.PersonService,
+		dogAPIClient:    params.DogAPIClient,
+		holidayService:  params.HolidayService,
+		tenantService:   params.TenantService,
+		carrierService:  params.CarrierService,
+		defaultPageSize: params.DefaultPageSize,
+		maxPageSize:     params.MaxPageSize,
+		exportPageSize:  params.ExportPageSize,
+		alertSink:       params.AlertSink,
+		overdueBatch:    params.OverdueBatch,
 	}
 }
 
@@ -61,12 +183,17 @@ func (p *ServiceParams) validate() {
 }
 
 func (s *service) ListPacks(ctx context.Context, filters *ListFilters) ([]*Entity, *pagination.Metadata, error) {
-	if filters.PageSize == 0 {
-		filters.PageSize = 100
+	if filters.PageSize <= 0 {
+		filters.PageSize = s.defaultPageSize
 	}
 
-	if filters.PageSize > 1000 {
-		filters.PageSize = 1000
+	if filters.PageSize > s.maxPageSize {
+		filters.PageSize = s.maxPageSize
+	}
+
+	err := filters.validate()
+	if err != nil {
+		return nil, nil, err
 	}
 
 	packs, metadata, err := s.repo.List(ctx, filters)
@@ -77,7 +204,58 @@ func (s *service) ListPacks(ctx context.Context, filters *ListFilters) ([]*Entit
 	return packs, metadata, nil
 }
 
+// ExportPacks validates the filters and returns the packs matching them,
+// read page by page with the list cursor so only one page is in memory. The
+// page filters are ignored.
+func (s *service) ExportPacks(ctx context.Context, filters *ListFilters) (iter.Seq2[*Entity, error], error) {
+	filters.PageSize = s.exportPageSize
+	filters.PageCursor = nil
+
+	err := filters.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(*Entity, error) bool) {
+		pageFilters := *filters
+
+		for {
+			packs, metadata, err := s.repo.List(ctx, &pageFilters)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, pack := range packs {
+				if !yield(pack, nil) {
+					return
+				}
+			}
+
+			if metadata.NextCursor == "" {
+				return
+			}
+
+			pageFilters.PageCursor = &metadata.NextCursor
+		}
+	}, nil
+}
+
+// AggregatePacks counts the packs matching the filters, the page and sort
+// filters are ignored.
+func (s *service) AggregatePacks(ctx context.Context, filters *ListFilters, withFacets bool) (*Aggregates, error) {
+	err := filters.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Aggregate(ctx, filters, withFacets)
+}
+
 func (s *service) CreatePack(ctx context.Context, pack *Entity) (*Entity, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "pack.CreatePack")
+	defer span.End()
+
 	var err error
 	pack.Sender, err = s.personService.GetOrCreateByName(ctx, pack.Sender.Name)
 	if err != nil {
@@ -93,15 +271,69 @@ func (s *service) CreatePack(ctx context.Context, pack *Entity) (*Entity, error)
 
 	err = s.repo.Create(ctx, pack)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
+	span.SetAttributes(attribute.String("pack.id", pack.ID))
+
+	s.enrichmentJobs.Add(2)
 	go s.setFunFact(ctx, pack)
 	go s.setIsHoliday(ctx, pack)
 
 	return pack, nil
 }
 
+// CreatePacks creates the packs all at once, resolving the persons in batch
+// within the same transaction, and enriches them in background with bounded
+// concurrency.
+func (s *service) CreatePacks(ctx context.Context, packs []*Entity) ([]*Entity, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "pack.CreatePacks")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("pack.count", len(packs)))
+
+	if len(packs) == 0 {
+		return packs, nil
+	}
+
+	names := make([]string, 0, 2*len(packs))
+	for _, pack := range packs {
+		names = append(names, pack.Sender.Name, pack.Receiver.Name)
+	}
+
+	// a failed import must not leave the persons it created behind
+	err := s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		persons, err := s.personService.GetOrCreateByNames(ctx, names)
+		if err != nil {
+			return err
+		}
+
+		for _, pack := range packs {
+			pack.Sender = persons[pack.Sender.Name]
+			pack.Receiver = persons[pack.Receiver.Name]
+			pack.Status = StatusCreated
+		}
+
+		return s.repo.CreateMany(ctx, packs)
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	enrichedPacks := make([]*Entity, 0, len(packs))
+	for _, pack := range packs {
+		enrichedPack := *pack
+		enrichedPacks = append(enrichedPacks, &enrichedPack)
+	}
+
+	s.enrichmentJobs.Add(2 * len(enrichedPacks))
+	go s.enrichPacks(ctx, enrichedPacks)
+
+	return packs, nil
+}
+
 func (s *service) GetPackByID(ctx context.Context, id string, withEvents bool) (*Entity, error) {
 	pack, err := s.repo.GetByID(ctx, id, withEvents)
 	if err != nil {
@@ -115,12 +347,79 @@ func (s *service) GetPackByID(ctx context.Context, id string, withEvents bool) (
 	return pack, nil
 }
 
+// GetTracking returns the customer view of the pack with the tracking code,
+// the code is checked before the lookup, so most typos fail with
+// ErrInvalidTrackingCode.
+func (s *service) GetTracking(ctx context.Context, code string) (*Tracking, error) {
+	code = trackingcode.Normalize(code)
+	if !trackingcode.Valid(code) {
+		return nil, ErrInvalidTrackingCode
+	}
+
+	// the tracking codes are unique across the tenants and the public
+	// tracking has no tenant, so the code is looked up in every tenant
+	pack, err := s.repo.GetByTrackingCode(tenant.WithSystem(ctx), code)
+	if err != nil {
+		return nil, err
+	}
+
+	if pack == nil {
+		return nil, ErrPackNotFound
+	}
+
+	tracking := &Tracking{
+		Code:                  code,
+		Status:                pack.Status,
+		EstimatedDeliveryDate: pack.EstimatedDeliveryDate,
+		DeliveredAt:           pack.DeliveredAt,
+		CanceledAt:            pack.CanceledAt,
+		UpdatedAt:             pack.UpdatedAt,
+		Events:                pack.Events,
+	}
+
+	if pack.CarrierID != nil {
+		carrier, err := s.carrierService.GetCarrierByID(tenant.WithContext(ctx, pack.TenantID), *pack.CarrierID)
+		if err != nil {
+			return nil, err
+		}
+
+		tracking.Carrier = &TrackingCarrier{
+			Name:           carrier.Name,
+			TrackingNumber: *pack.TrackingNumber,
+		}
+	}
+
+	return tracking, nil
+}
+
+// BackfillTrackingCodes sets the tracking code of the packs created before
+// the codes, of all tenants, returning how many were set.
+func (s *service) BackfillTrackingCodes(ctx context.Context) (int, error) {
+	backfilled := 0
+
+	for {
+		assigned, err := s.repo.AssignMissingTrackingCodes(ctx, trackingCodeBackfillBatch)
+		backfilled += assigned
+
+		if err != nil || assigned < trackingCodeBackfillBatch {
+			return backfilled, err
+		}
+	}
+}
+
+// UpdatePackStatusByID changes the status when the pack is in the version
+// informed in pack, any version when it is 0.
 func (s *service) UpdatePackStatusByID(ctx context.Context, id string, pack *Entity) (*Entity, error) {
 	currentPack, err := s.GetPackByID(ctx, id, false)
 	if err != nil {
 		return nil, err
 	}
 
+	err = currentPack.ValidateVersion(pack.Version)
+	if err != nil {
+		return nil, err
+	}
+
 	err = currentPack.Status.ValidateChangeStatus(pack.Status)
 	if err != nil {
 		return nil, err
@@ -141,12 +440,90 @@ func (s *service) UpdatePackStatusByID(ctx context.Context, id string, pack *Ent
 	return currentPack, nil
 }
 
-func (s *service) CancelPackStatusByID(ctx context.Context, id string) (*Entity, error) {
+// UpdatePackDetailsByID changes the informed details allowed by the pack
+// status, when the pack is in details.Version. A new estimated delivery date
+// clears the overdue mark and re-runs the holiday check in background.
+func (s *service) UpdatePackDetailsByID(ctx context.Context, id string, details *Details) (*Entity, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "pack.UpdatePackDetailsByID")
+	defer span.End()
+
 	currentPack, err := s.GetPackByID(ctx, id, false)
 	if err != nil {
 		return nil, err
 	}
 
+	err = currentPack.ValidateVersion(details.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := currentPack.ChangedDetails(details)
+	if len(changed) == 0 {
+		return currentPack, nil
+	}
+
+	err = currentPack.Status.ValidateChangeDetails(changed)
+	if err != nil {
+		return nil, err
+	}
+
+	dateChanged := slices.Contains(changed, DetailsFieldEstimatedDeliveryDate)
+
+	if dateChanged {
+		estimatedDeliveryDate, err := time.ParseInLocation(time.DateOnly, *details.EstimatedDeliveryDate, time.Local)
+		if err != nil {
+			return nil, err
+		}
+
+		if estimatedDeliveryDate.Before(startOfDay(time.Now())) {
+			return nil, ErrEstimatedDeliveryDateInPast
+		}
+
+		currentPack.EstimatedDeliveryDate = *details.EstimatedDeliveryDate
+		currentPack.IsHoliday = nil
+		currentPack.OverdueSince = nil
+	}
+
+	if slices.Contains(changed, DetailsFieldDescription) {
+		currentPack.Description = *details.Description
+	}
+
+	if slices.Contains(changed, DetailsFieldRecipient) {
+		currentPack.Receiver, err = s.personService.GetOrCreateByName(ctx, *details.ReceiverName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.repo.UpdateDetailsByID(ctx, id, currentPack, dateChanged)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if dateChanged {
+		enrichedPack := *currentPack
+
+		s.enrichmentJobs.Add(1)
+		go s.setIsHoliday(ctx, &enrichedPack)
+	}
+
+	return currentPack, nil
+}
+
+// CancelPackStatusByID cancels the pack when it is in the version, any
+// version when it is 0.
+func (s *service) CancelPackStatusByID(ctx context.Context, id string, version int) (*Entity, error) {
+	currentPack, err := s.GetPackByID(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = currentPack.ValidateVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
 	if currentPack.Status != StatusCreated {
 		return nil, ErrCannotCancel
 	}
@@ -163,15 +540,240 @@ func (s *service) CancelPackStatusByID(ctx context.Context, id string) (*Entity,
 	return currentPack, nil
 }
 
+// AssignCarrier assigns the open pack to the carrier of its tenant with the
+// tracking number, when the pack is in the assignment version, any version
+// when it is 0. Reassigning replaces the previous carrier.
+func (s *service) AssignCarrier(ctx context.Context, id string, assignment *CarrierAssignment) (*Entity, error) {
+	currentPack, err := s.GetPackByID(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = currentPack.ValidateVersion(assignment.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if currentPack.Status == StatusDelivered || currentPack.Status == StatusCanceled {
+		return nil, ErrCarrierNotAssignable
+	}
+
+	// the informed carrier is part of the payload, so it's invalid
+	assignedCarrier, err := s.carrierService.GetCarrierByID(ctx, assignment.CarrierID)
+	if cerrors.Is(err, carrier.ErrCarrierNotFound) {
+		return nil, carrier.ErrCarrierNotFound.WithCategory(cerrors.CategoryInvalid)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	currentPack.CarrierID = &assignedCarrier.ID
+	currentPack.TrackingNumber = &assignment.TrackingNumber
+
+	err = s.repo.UpdateByID(ctx, id, currentPack)
+	if err != nil {
+		return nil, err
+	}
+
+	return currentPack, nil
+}
+
+// validate sets the default sort and checks the filters.
+func (f *ListFilters) validate() error {
+	if f.Sort == "" {
+		f.Sort = ListSortCreatedAt
+	}
+
+	if f.SortDirection == "" {
+		f.SortDirection = pagination.DescDirection
+	}
+
+	if f.Sort != ListSortCreatedAt && f.Sort != ListSortEstimatedDeliveryDate {
+		return ErrInvalidFilters
+	}
+
+	if f.SortDirection != pagination.AscDirection && f.SortDirection != pagination.DescDirection {
+		return ErrInvalidFilters
+	}
+
+	for _, status := range f.Statuses {
+		if status != StatusCreated && status != StatusInTransit && status != StatusDelivered && status != StatusCanceled {
+			return ErrInvalidFilters
+		}
+	}
+
+	if isInvalidRange(f.CreatedFrom, f.CreatedTo) ||
+		isInvalidRange(f.DeliveredFrom, f.DeliveredTo) ||
+		isInvalidRange(f.EstimatedDeliveryFrom, f.EstimatedDeliveryTo) {
+		return ErrInvalidFilters
+	}
+
+	return nil
+}
+
+func isInvalidRange(from, to *time.Time) bool {
+	return from != nil && to != nil && from.After(*to)
+}
+
+// DetectOverdue marks the open packs past the estimated delivery date, fires
+// an alert for each newly overdue one and updates the packs_overdue gauge.
+// It returns the number of newly overdue packs.
+func (s *service) DetectOverdue(ctx context.Context) (int, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "pack.DetectOverdue")
+	defer span.End()
+
+	today := startOfDay(time.Now())
+
+	cleared, err := s.repo.ClearOverdue(ctx, today)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	if cleared > 0 {
+		logger.FromContext(ctx).InfoContext(ctx, "overdue packs cleared", "count", cleared)
+	}
+
+	detected := 0
+
+	for {
+		packs, err := s.repo.MarkOverdue(ctx, today, s.overdueBatch)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return detected, err
+		}
+
+		for _, pack := range packs {
+			s.sendOverdueAlert(ctx, pack)
+		}
+
+		detected += len(packs)
+
+		if len(packs) < s.overdueBatch {
+			break
+		}
+	}
+
+	total, err := s.repo.CountOverdue(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return detected, err
+	}
+
+	overduePacks.Set(float64(total))
+	span.SetAttributes(attribute.Int("packs.overdue.detected", detected), attribute.Int("packs.overdue.total", total))
+
+	return detected, nil
+}
+
+// RetryEnrichment sets the fun fact and the holiday flag of the packs left
+// without them, e.g.: the APIs were down. Recent packs are skipped as their
+// enrichment may be running. It returns the number of packs retried.
+func (s *service) RetryEnrichment(ctx context.Context) (int, error) {
+	packs, err := s.repo.ListMissingEnrichment(ctx, time.Now().Add(-enrichmentRetryAfter), enrichmentRetryBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, pack := range packs {
+		if pack.FunFact == nil {
+			s.enrichmentJobs.Add(1)
+			s.setFunFact(ctx, pack)
+		}
+
+		if pack.IsHoliday == nil {
+			s.enrichmentJobs.Add(1)
+			s.setIsHoliday(ctx, pack)
+		}
+	}
+
+	return len(packs), nil
+}
+
+func (s *service) sendOverdueAlert(ctx context.Context, pack *Entity) {
+	ctx = logger.With(ctx, logger.PackIDKey, pack.ID)
+
+	labels := map[string]string{
+		"pack_id":                 pack.ID,
+		"tenant_id":               pack.TenantID,
+		"status":                  pack.Status.String(),
+		"estimated_delivery_date": pack.EstimatedDeliveryDate,
+	}
+
+	if pack.Sender != nil {
+		labels["sender"] = pack.Sender.Name
+	}
+
+	if pack.Receiver != nil {
+		labels["recipient"] = pack.Receiver.Name
+	}
+
+	err := s.alertSink.Send(ctx, &alert.Alert{
+		Name:     overdueAlertName,
+		Severity: alert.SeverityWarning,
+		Message:  "pack passed the estimated delivery date",
+		Labels:   labels,
+		FiredAt:  time.Now(),
+	})
+	if err != nil {
+		logger.FromContext(ctx).ErrorContext(ctx, "error sending overdue alert", logger.Error(err))
+	}
+}
+
+// Shutdown waits for the running enrichment jobs until ctx is done.
+func (s *service) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		s.enrichmentJobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enrichPacks sets the fun fact and the holiday flag of many packs, running
+// up to bulkEnrichmentConcurrency packs at a time.
+func (s *service) enrichPacks(ctx context.Context, packs []*Entity) {
+	semaphore := make(chan struct{}, bulkEnrichmentConcurrency)
+
+	for _, pack := range packs {
+		semaphore <- struct{}{}
+
+		go func() {
+			defer func() { <-semaphore }()
+
+			s.setFunFact(ctx, pack)
+			s.setIsHoliday(ctx, pack)
+		}()
+	}
+}
+
 func (s *service) setFunFact(ctx context.Context, pack *Entity) {
+	defer s.enrichmentJobs.Done()
+
+	ctx, span := tracing.StartDetachedSpan(ctx, "pack.setFunFact")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("pack.id", pack.ID))
+	ctx = tenant.WithContext(ctx, pack.TenantID)
+	ctx = logger.With(ctx, logger.PackIDKey, pack.ID)
+
 	funFacts, err := s.dogAPIClient.GetRandomFacts(ctx, 1)
 	if err != nil {
-		log.Printf("Error getting fun facts: %s. pack: %s", err, pack.ID)
+		span.RecordError(err)
+		logger.FromContext(ctx).ErrorContext(ctx, "error getting fun facts", logger.Error(err))
 		return
 	}
 
 	if len(funFacts) == 0 {
-		log.Printf("No fun facts found to pack: %s", pack.ID)
+		logger.FromContext(ctx).WarnContext(ctx, "no fun facts found")
 		return
 	}
 
@@ -179,20 +781,41 @@ func (s *service) setFunFact(ctx context.Context, pack *Entity) {
 
 	err = s.repo.UpdateFunFactByID(ctx, pack.ID, *pack.FunFact)
 	if err != nil {
-		log.Printf("Error updating pack: (%s). pack: %s", err, pack.ID)
+		span.RecordError(err)
+		logger.FromContext(ctx).ErrorContext(ctx, "error updating pack", logger.Error(err))
 	}
 }
 
 func (s *service) setIsHoliday(ctx context.Context, pack *Entity) {
-	isHoliday, err := s.holidayService.IsHoliday(ctx, pack.EstimatedDeliveryDate)
+	defer s.enrichmentJobs.Done()
+
+	ctx, span := tracing.StartDetachedSpan(ctx, "pack.setIsHoliday")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("pack.id", pack.ID))
+	ctx = tenant.WithContext(ctx, pack.TenantID)
+	ctx = logger.With(ctx, logger.PackIDKey, pack.ID)
+
+	packTenant, err := s.tenantService.GetByID(ctx, pack.TenantID)
 	if err != nil {
-		log.Printf("Error getting holidays: %s. pack: %s", err, pack.ID)
+		span.RecordError(err)
+		logger.FromContext(ctx).ErrorContext(ctx, "error getting the pack tenant", logger.Error(err))
+		return
+	}
+
+	isHoliday, err := s.holidayService.IsHoliday(ctx, packTenant.HolidayCountry, pack.EstimatedDeliveryDate)
+	if err != nil {
+		span.RecordError(err)
+		logger.FromContext(ctx).ErrorContext(ctx, "error getting holidays", logger.Error(err))
+		// is_holiday stays NULL, so the enrichment retry job checks it again
+		return
 	}
 
 	pack.IsHoliday = &isHoliday
 
-	err = s.repo.UpdateIsHolidayByID(ctx, pack.ID, *pack.IsHoliday)
+	err = s.repo.UpdateIsHolidayByID(ctx, pack.ID, pack.EstimatedDeliveryDate, *pack.IsHoliday)
 	if err != nil {
-		log.Printf("Error updating pack: (%s). pack: %s", err, pack.ID)
+		span.RecordError(err)
+		logger.FromContext(ctx).ErrorContext(ctx, "error updating pack", logger.Error(err))
 	}
 }
@@ -1,11 +1,16 @@
 package packevent
 
-import "time"
+import (
+	"pack-management/internal/pkg/cerrors"
+	"time"
+)
 
 type (
 	Entity struct {
 		ID          string
+		TenantID    string
 		PackID      string
+		CarrierID   string
 		Description string
 		Location    string
 		Date        time.Time
@@ -14,6 +19,10 @@ type (
 	}
 )
 
+var (
+	ErrPackNotAssigned = cerrors.New("the pack is not assigned to the carrier", cerrors.CodePackNotAssigned)
+)
+
 func (e *Entity) ToModel() *Model {
 	if e == nil {
 		return nil
@@ -21,6 +30,7 @@ func (e *Entity) ToModel() *Model {
 
 	model := &Model{
 		ID:          e.ID,
+		TenantID:    e.TenantID,
 		PackID:      e.PackID,
 		Description: e.Description,
 		Location:    e.Location,
@@ -31,3 +41,24 @@ func (e *Entity) ToModel() *Model {
 
 	return model
 }
+
+func (e *Entity) ToPendingModel() *PendingModel {
+	if e == nil {
+		return nil
+	}
+
+	model := &PendingModel{
+		ID:          e.ID,
+		TenantID:    e.TenantID,
+		PackID:      e.PackID,
+		Description: e.Description,
+		Location:    e.Location,
+		Date:        e.Date,
+	}
+
+	if e.CarrierID != "" {
+		model.CarrierID = &e.CarrierID
+	}
+
+	return model
+}
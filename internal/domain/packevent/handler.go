@@ -1,7 +1,7 @@
 package packevent
 
 import (
-	"pack-management/internal/domain/pack"
+	"pack-management/internal/domain/auth"
 	"pack-management/internal/pkg/cerrors"
 	"pack-management/internal/pkg/validator"
 	"time"
@@ -18,6 +18,9 @@ type (
 	HandlerParams struct {
 		App     *fiber.App `validate:"required"`
 		Service Service    `validate:"required"`
+		// Idempotency is the Idempotency-Key middleware of the create route,
+		// optional, the keys are ignored by default.
+		Idempotency fiber.Handler
 	}
 
 	CreateEventRequest struct {
@@ -39,17 +42,25 @@ type (
 func NewHTPPHandler(params *HandlerParams) *handler {
 	params.validate()
 
+	if params.Idempotency == nil {
+		params.Idempotency = skipIdempotency
+	}
+
 	h := &handler{
 		service: params.Service,
 		app:     params.App,
 	}
 
 	group := h.app.Group("/pack_events")
-	group.Post("/", h.createEvent)
+	group.Post("/", auth.RequireScope(auth.ScopeEventsWrite), params.Idempotency, h.createEvent)
 
 	return h
 }
 
+func skipIdempotency(ctx *fiber.Ctx) error {
+	return ctx.Next()
+}
+
 func (p *HandlerParams) validate() {
 	err := validator.ValidateStruct(p)
 	if err != nil {
@@ -60,25 +71,24 @@ func (p *HandlerParams) validate() {
 func (h *handler) createEvent(ctx *fiber.Ctx) error {
 	payload := &CreateEventRequest{}
 	if err := ctx.BodyParser(payload); err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return cerrors.ErrInvalidBody
 	}
 
 	err := validator.ValidateStruct(payload)
 	if err != nil {
-		return ctx.SendStatus(fiber.StatusBadRequest)
+		return err
 	}
 
-	go h.service.EnqueueEvent(ctx.Context(), payload.ToEntity())
-
-	return ctx.SendStatus(fiber.StatusNoContent)
-}
+	event := payload.ToEntity()
 
-func (h *handler) errorHandler(ctx *fiber.Ctx, err error) error {
-	if cerrors.Is(err, pack.ErrPackNotFound) {
-		return ctx.Status(fiber.StatusNotFound).JSON(err)
+	err = h.service.AuthorizeEvent(ctx.UserContext(), event)
+	if err != nil {
+		return err
 	}
 
-	return ctx.SendStatus(fiber.StatusInternalServerError)
+	h.service.EnqueueEvent(ctx.UserContext(), event)
+
+	return ctx.SendStatus(fiber.StatusNoContent)
 }
 
 func (r *CreateEventRequest) ToEntity() *Entity {
@@ -2,6 +2,7 @@ package packevent
 
 import (
 	"context"
+	"pack-management/internal/domain/tenant"
 	"time"
 
 	"github.com/uptrace/bun"
@@ -10,22 +11,44 @@ import (
 type (
 	Repository interface {
 		Create(ctx context.Context, event *Entity) error
+		CreatePending(ctx context.Context, events []*Entity) error
+		ClaimPending(ctx context.Context, claimID string, now time.Time, staleBefore time.Time, limit int) ([]*Entity, error)
+		DeletePendingByClaim(ctx context.Context, claimID string) error
+		DeleteClosedBefore(ctx context.Context, before time.Time, limit int) (int, error)
 	}
 
 	Model struct {
 		bun.BaseModel `bun:"table:pack_event,alias:pack_event"`
 		ID            string    `bun:"id,pk"`
+		TenantID      string    `bun:"tenant_id"`
 		PackID        string    `bun:"pack_id"`
 		Description   string    `bun:"description"`
 		Location      string    `bun:"location"`
 		Date          time.Time `bun:"date"`
 		CreatedAt     time.Time `bun:"created_at"`
 		UpdatedAt     time.Time `bun:"updated_at"`
+		tenant.Scoped
+	}
+
+	PendingModel struct {
+		bun.BaseModel `bun:"table:pack_event_pending,alias:pack_event_pending"`
+		ID            string     `bun:"id,pk"`
+		TenantID      string     `bun:"tenant_id"`
+		PackID        string     `bun:"pack_id"`
+		CarrierID     *string    `bun:"carrier_id"`
+		Description   string     `bun:"description"`
+		Location      string     `bun:"location"`
+		Date          time.Time  `bun:"date"`
+		ClaimedBy     *string    `bun:"claimed_by"`
+		ClaimedAt     *time.Time `bun:"claimed_at"`
+		CreatedAt     time.Time  `bun:"created_at"`
+		tenant.Scoped
 	}
 )
 
 const (
-	idPrefix = "event_"
+	idPrefix        = "event_"
+	pendingIDPrefix = "pending_event_"
 )
 
 func (m *Model) ToEntity() *Entity {
@@ -35,6 +58,7 @@ func (m *Model) ToEntity() *Entity {
 
 	return &Entity{
 		ID:          m.ID,
+		TenantID:    m.TenantID,
 		PackID:      m.PackID,
 		Description: m.Description,
 		Location:    m.Location,
@@ -43,3 +67,24 @@ func (m *Model) ToEntity() *Entity {
 		Date:        m.Date,
 	}
 }
+
+func (m *PendingModel) ToEntity() *Entity {
+	if m == nil {
+		return nil
+	}
+
+	entity := &Entity{
+		ID:          m.ID,
+		TenantID:    m.TenantID,
+		PackID:      m.PackID,
+		Description: m.Description,
+		Location:    m.Location,
+		Date:        m.Date,
+	}
+
+	if m.CarrierID != nil {
+		entity.CarrierID = *m.CarrierID
+	}
+
+	return entity
+}
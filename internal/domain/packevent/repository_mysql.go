@@ -2,6 +2,8 @@ package packevent
 
 import (
 	"context"
+	"pack-management/internal/domain/pack"
+	"pack-management/internal/domain/tenant"
 	"pack-management/internal/pkg/validator"
 	"time"
 
@@ -36,11 +38,85 @@ func (p *RepositoryParams) validate() {
 }
 
 func (r *mysqlRepository) Create(ctx context.Context, event *Entity) error {
+	tenantID, err := tenant.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
 	event.ID = r.newID()
+	event.TenantID = tenantID
 	event.CreatedAt = time.Now()
 	event.UpdatedAt = time.Now()
 
-	_, err := r.db.NewInsert().Model(event.ToModel()).Exec(ctx)
+	_, err = r.db.NewInsert().Model(event.ToModel()).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *mysqlRepository) CreatePending(ctx context.Context, events []*Entity) error {
+	models := make([]*PendingModel, 0, len(events))
+
+	for _, event := range events {
+		model := event.ToPendingModel()
+		model.ID = pendingIDPrefix + uuid.New().String()
+		model.CreatedAt = time.Now()
+
+		models = append(models, model)
+	}
+
+	_, err := r.db.NewInsert().Model(&models).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ClaimPending claims up to limit pending events, the oldest first, for
+// claimID with a single update, so the concurrent claims of the other
+// replicas wait for its row locks and skip the claimed rows. The events
+// claimed before staleBefore, by a replica stopped before deleting them, are
+// claimed again.
+func (r *mysqlRepository) ClaimPending(ctx context.Context, claimID string, now time.Time, staleBefore time.Time, limit int) ([]*Entity, error) {
+	_, err := r.db.NewUpdate().
+		Model((*PendingModel)(nil)).
+		Set("claimed_by = ?", claimID).
+		Set("claimed_at = ?", now).
+		Where("claimed_by IS NULL OR claimed_at < ?", staleBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*PendingModel, 0)
+
+	err = r.db.NewSelect().
+		Model(&models).
+		Where("claimed_by = ?", claimID).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*Entity, 0, len(models))
+	for _, model := range models {
+		entities = append(entities, model.ToEntity())
+	}
+
+	return entities, nil
+}
+
+func (r *mysqlRepository) DeletePendingByClaim(ctx context.Context, claimID string) error {
+	_, err := r.db.NewDelete().
+		Model((*PendingModel)(nil)).
+		Where("claimed_by = ?", claimID).
+		Exec(ctx)
 	if err != nil {
 		return err
 	}
@@ -48,6 +124,38 @@ func (r *mysqlRepository) Create(ctx context.Context, event *Entity) error {
 	return nil
 }
 
+// DeleteClosedBefore deletes up to limit events dated before the date of the
+// delivered or canceled packs of all tenants, returning how many were deleted.
+func (r *mysqlRepository) DeleteClosedBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	IDs := []string{}
+
+	err := r.db.NewSelect().
+		Model((*Model)(nil)).
+		Column("pack_event.id").
+		Join("JOIN pack ON pack.id = pack_event.pack_id").
+		Where("pack.status IN (?)", bun.In([]pack.Status{pack.StatusDelivered, pack.StatusCanceled})).
+		Where("pack_event.date < ?", before).
+		Limit(limit).
+		Scan(ctx, &IDs)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(IDs) == 0 {
+		return 0, nil
+	}
+
+	_, err = r.db.NewDelete().
+		Model((*Model)(nil)).
+		Where("id IN (?)", bun.In(IDs)).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(IDs), nil
+}
+
 func (r *mysqlRepository) newID() string {
 	return idPrefix + uuid.New().String()
 }
@@ -2,29 +2,78 @@ package packevent
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"pack-management/internal/domain/auth"
 	"pack-management/internal/domain/pack"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/tracing"
+	"pack-management/internal/pkg/uuid"
 	"pack-management/internal/pkg/validator"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type (
 	Service interface {
+		AuthorizeEvent(ctx context.Context, event *Entity) error
 		EnqueueEvent(ctx context.Context, event *Entity)
+		LastHeartbeat() time.Time
+		QueueStats() (length int, capacity int)
+		Shutdown(ctx context.Context) error
+		PurgeEvents(ctx context.Context, retention time.Duration) (int, error)
 	}
 
 	service struct {
-		repo        Repository
-		packService pack.Service
-		eventsQueue chan *Entity
+		repo            Repository
+		packService     pack.Service
+		eventsQueue     chan *queuedEvent
+		heartbeat       atomic.Int64
+		restoreInterval time.Duration
+		// mutex orders the enqueues before the close, so an event is either
+		// queued before the drain or persisted as pending.
+		mutex      sync.RWMutex
+		closed     bool
+		drainC     chan context.Context
+		workerDone chan struct{}
 	}
 
 	ServiceParams struct {
 		Repo        Repository   `validate:"required"`
 		PackService pack.Service `validate:"required"`
+		// QueueBuffer is optional, 1000 by default.
+		QueueBuffer int `validate:"gte=0"`
+		// RestoreInterval is how often the pending events are restored,
+		// optional, 30s by default.
+		RestoreInterval time.Duration `validate:"gte=0"`
+	}
+
+	queuedEvent struct {
+		event       *Entity
+		spanContext trace.SpanContext
+		logger      *slog.Logger
 	}
 )
 
-const eventsProcessBuffer = 1000
+const (
+	purgeBatch = 500
+
+	eventsProcessBuffer = 1000
+	heartbeatInterval   = 5 * time.Second
+	persistTimeout      = 5 * time.Second
+
+	defaultRestoreInterval = 30 * time.Second
+	// claimTimeout is when the pending events claimed by a replica stopped
+	// before deleting them are claimed again.
+	claimTimeout       = time.Minute
+	pendingClaimPrefix = "claim_"
+)
 
 func NewService(ctx context.Context, params *ServiceParams) Service {
 	params.validate()
@@ -32,9 +81,20 @@ func NewService(ctx context.Context, params *ServiceParams) Service {
 	src := &service{
 		repo:        params.Repo,
 		packService: params.PackService,
+		drainC:      make(chan context.Context, 1),
+		workerDone:  make(chan struct{}),
+	}
+
+	if params.QueueBuffer == 0 {
+		params.QueueBuffer = eventsProcessBuffer
 	}
 
-	src.eventsQueue = make(chan *Entity, eventsProcessBuffer)
+	if params.RestoreInterval == 0 {
+		params.RestoreInterval = defaultRestoreInterval
+	}
+
+	src.eventsQueue = make(chan *queuedEvent, params.QueueBuffer)
+	src.restoreInterval = params.RestoreInterval
 
 	go src.processEventsWorker(ctx)
 
@@ -48,32 +108,319 @@ func (p *ServiceParams) validate() {
 	}
 }
 
+// AuthorizeEvent checks the caller of ctx may post the event, a carrier only
+// for the packs assigned to it, the packs of other carriers and the missing
+// ones are not told apart. It answers the caller early, the check is run
+// again when the event is created.
+func (s *service) AuthorizeEvent(ctx context.Context, event *Entity) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil || !principal.IsCarrier() {
+		return nil
+	}
+
+	eventPack, err := s.packService.GetPackByID(ctx, event.PackID, false)
+	if cerrors.Is(err, pack.ErrPackNotFound) {
+		return ErrPackNotAssigned
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return checkAssignment(eventPack, principal.CarrierID)
+}
+
+// EnqueueEvent adds the event to the in-memory queue, with the tenant and the
+// carrier of ctx. When the queue is full or Shutdown was called the event is
+// persisted as pending instead, to be restored later or on the next start.
 func (s *service) EnqueueEvent(ctx context.Context, event *Entity) {
-	s.eventsQueue <- event
+	event.TenantID = tenant.ScopeKey(ctx)
+
+	principal := auth.PrincipalFromContext(ctx)
+	if principal != nil && principal.IsCarrier() {
+		event.CarrierID = principal.CarrierID
+	}
+
+	item := &queuedEvent{
+		event:       event,
+		spanContext: trace.SpanContextFromContext(ctx),
+		logger:      logger.FromContext(ctx).With(logger.PackIDKey, event.PackID),
+	}
+
+	if !s.push(item) {
+		s.persistPending(ctx, []*queuedEvent{item})
+	}
+}
+
+// push adds the item to the queue without blocking, it fails when the queue
+// is full or closed.
+func (s *service) push(item *queuedEvent) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.closed {
+		return false
+	}
+
+	select {
+	case s.eventsQueue <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *service) LastHeartbeat() time.Time {
+	return time.Unix(0, s.heartbeat.Load())
+}
+
+func (s *service) QueueStats() (int, int) {
+	return len(s.eventsQueue), cap(s.eventsQueue)
+}
+
+// Shutdown stops accepting events and drains the queue until ctx is done,
+// the events left in the queue are persisted as pending.
+func (s *service) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+
+	s.closed = true
+	s.mutex.Unlock()
+
+	s.drainC <- ctx
+
+	select {
+	case <-s.workerDone:
+	case <-ctx.Done():
+	}
+
+	s.persistPending(ctx, s.takeQueued())
+
+	return ctx.Err()
+}
+
+// PurgeEvents deletes the events older than the retention of the delivered
+// or canceled packs, in batches, returning how many were deleted.
+func (s *service) PurgeEvents(ctx context.Context, retention time.Duration) (int, error) {
+	before := time.Now().Add(-retention)
+	purged := 0
+
+	for ctx.Err() == nil {
+		deleted, err := s.repo.DeleteClosedBefore(ctx, before, purgeBatch)
+		if err != nil {
+			return purged, err
+		}
+
+		purged += deleted
+
+		if deleted < purgeBatch {
+			break
+		}
+	}
+
+	logger.FromContext(ctx).InfoContext(ctx, "pack events purged", "count", purged, "before", before)
+
+	return purged, ctx.Err()
 }
 
 func (s *service) processEventsWorker(ctx context.Context) {
+	defer close(s.workerDone)
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	restoreTicker := time.NewTicker(s.restoreInterval)
+	defer restoreTicker.Stop()
+
+	s.beat()
+	s.restorePending(ctx)
+
 	for {
 		select {
-		case event := <-s.eventsQueue:
-			err := s.createEvent(ctx, event)
-			if err != nil {
-				log.Printf("Error creating event: %v", err)
-				log.Printf("Requeuing event: %v", event)
-				s.EnqueueEvent(ctx, event)
+		case <-ticker.C:
+			s.beat()
+		case <-restoreTicker.C:
+			s.restorePending(ctx)
+		case item := <-s.eventsQueue:
+			itemCtx := logger.WithContext(ctx, item.logger)
+
+			err := s.processEvent(itemCtx, item)
+			if isRejected(err) {
+				// retrying won't help
+				item.logger.WarnContext(itemCtx, "dropping rejected event", logger.Error(err))
+			} else if err != nil {
+				item.logger.ErrorContext(itemCtx, "error creating event, requeuing", logger.Error(err))
+				s.requeue(itemCtx, item)
 			}
+
+			s.beat()
+		case drainCtx := <-s.drainC:
+			s.drain(drainCtx)
+			return
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// drain processes the queued events until the queue is empty or ctx is done.
+// Failed events are not requeued but persisted as pending.
+func (s *service) drain(ctx context.Context) {
+	failed := []*queuedEvent{}
+
+	defer func() {
+		s.persistPending(ctx, failed)
+	}()
+
+	for ctx.Err() == nil {
+		select {
+		case item := <-s.eventsQueue:
+			itemCtx := logger.WithContext(ctx, item.logger)
+
+			err := s.processEvent(itemCtx, item)
+			if isRejected(err) {
+				item.logger.WarnContext(itemCtx, "dropping rejected event", logger.Error(err))
+			} else if err != nil {
+				item.logger.ErrorContext(itemCtx, "error creating event while draining", logger.Error(err))
+				failed = append(failed, item)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// requeue never blocks the worker, when the queue is full or closed the
+// event is persisted as pending.
+func (s *service) requeue(ctx context.Context, item *queuedEvent) {
+	if !s.push(item) {
+		s.persistPending(ctx, []*queuedEvent{item})
+	}
+}
+
+func (s *service) takeQueued() []*queuedEvent {
+	items := []*queuedEvent{}
+
+	for {
+		select {
+		case item := <-s.eventsQueue:
+			items = append(items, item)
+		default:
+			return items
+		}
+	}
+}
+
+func (s *service) persistPending(ctx context.Context, items []*queuedEvent) {
+	if len(items) == 0 {
+		return
+	}
+
+	// the pending events keep their tenants
+	ctx, cancel := context.WithTimeout(tenant.WithSystem(context.WithoutCancel(ctx)), persistTimeout)
+	defer cancel()
+
+	events := make([]*Entity, 0, len(items))
+	for _, item := range items {
+		events = append(events, item.event)
+	}
+
+	err := s.repo.CreatePending(ctx, events)
+	if err != nil {
+		logger.FromContext(ctx).ErrorContext(ctx, "error persisting pending events", "count", len(events), logger.Error(err))
+		return
+	}
+
+	logger.FromContext(ctx).InfoContext(ctx, "pending events persisted", "count", len(events))
+}
+
+// restorePending moves the pending events, persisted by a shutdown or while
+// the queue was full, back to the queue, up to its free capacity, the others
+// are left to the next restore. The events are claimed first, so each one is
+// restored by a single replica.
+func (s *service) restorePending(ctx context.Context) {
+	// the pending events of every tenant
+	ctx = tenant.WithSystem(ctx)
+
+	limit := cap(s.eventsQueue) - len(s.eventsQueue)
+	if limit == 0 {
+		return
+	}
+
+	now := time.Now()
+	claimID := pendingClaimPrefix + uuid.New().String()
+
+	events, err := s.repo.ClaimPending(ctx, claimID, now, now.Add(-claimTimeout), limit)
+	if err != nil {
+		logger.FromContext(ctx).ErrorContext(ctx, "error claiming pending events", logger.Error(err))
+		return
+	}
+
+	if len(events) == 0 {
+		return
+	}
+
+	err = s.repo.DeletePendingByClaim(ctx, claimID)
+	if err != nil {
+		logger.FromContext(ctx).ErrorContext(ctx, "error deleting pending events", logger.Error(err))
+		return
+	}
+
+	for _, event := range events {
+		event.ID = ""
+
+		// the queue may have been filled meanwhile, the worker must not block
+		// on it
+		s.requeue(ctx, &queuedEvent{
+			event:  event,
+			logger: logger.FromContext(ctx).With(logger.PackIDKey, event.PackID),
+		})
+	}
+
+	logger.FromContext(ctx).InfoContext(ctx, "pending events restored", "count", len(events))
+}
+
+func (s *service) beat() {
+	s.heartbeat.Store(time.Now().UnixNano())
+}
+
+func (s *service) processEvent(ctx context.Context, item *queuedEvent) error {
+	ctx, span := tracing.StartLinkedSpan(ctx, item.spanContext, "packevent.process")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("pack.id", item.event.PackID))
+
+	err := s.createEvent(ctx, item.event)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// createEvent runs on the worker, the pack is looked up in the event tenant so
+// the events of other tenants' packs are rejected, and the event of a carrier
+// is rejected unless the pack is still assigned to it, e.g.: reassigned while
+// the event was queued.
 func (s *service) createEvent(ctx context.Context, event *Entity) error {
-	_, err := s.packService.GetPackByID(ctx, event.PackID, false)
+	ctx = tenant.WithContext(ctx, event.TenantID)
+
+	eventPack, err := s.packService.GetPackByID(ctx, event.PackID, false)
 	if err != nil {
 		return err
 	}
 
+	if event.CarrierID != "" {
+		err = checkAssignment(eventPack, event.CarrierID)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = s.repo.Create(ctx, event)
 	if err != nil {
 		return err
@@ -81,3 +428,19 @@ func (s *service) createEvent(ctx context.Context, event *Entity) error {
 
 	return nil
 }
+
+// checkAssignment fails with ErrPackNotAssigned unless the pack is assigned to
+// the carrier.
+func checkAssignment(eventPack *pack.Entity, carrierID string) error {
+	if eventPack.CarrierID == nil || *eventPack.CarrierID != carrierID {
+		return ErrPackNotAssigned
+	}
+
+	return nil
+}
+
+// isRejected reports if the event can never be created, the pack doesn't
+// exist in the event tenant or isn't assigned to the event carrier.
+func isRejected(err error) bool {
+	return cerrors.Is(err, pack.ErrPackNotFound) || cerrors.Is(err, ErrPackNotAssigned)
+}
@@ -5,6 +5,7 @@ import "time"
 type (
 	Entity struct {
 		ID        string
+		TenantID  string
 		Name      string
 		CreatedAt time.Time
 		UpdatedAt time.Time
@@ -18,6 +19,7 @@ func (e *Entity) ToModel () *Model {
 
 	model := &Model{
 		ID:        e.ID,
+		TenantID:  e.TenantID,
 		Name:      e.Name,
 		CreatedAt: e.CreatedAt,
 		UpdatedAt: e.UpdatedAt,
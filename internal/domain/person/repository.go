@@ -2,6 +2,7 @@ package person
 
 import (
 	"context"
+	"pack-management/internal/domain/tenant"
 	"time"
 
 	"github.com/uptrace/bun"
@@ -11,14 +12,18 @@ type (
 	Repository interface {
 		Create(ctx context.Context, person *Entity) error
 		GetByName(ctx context.Context, name string) (*Entity, error)
+		ListByNames(ctx context.Context, names []string) ([]*Entity, error)
+		CreateMany(ctx context.Context, persons []*Entity) error
 	}
 
 	Model struct {
 		bun.BaseModel `bun:"table:person,alias:person"`
 		ID            string    `bun:"id,pk"`
+		TenantID      string    `bun:"tenant_id"`
 		Name          string    `bun:"name"`
 		CreatedAt     time.Time `bun:"created_at"`
 		UpdatedAt     time.Time `bun:"updated_at"`
+		tenant.Scoped
 	}
 )
 
@@ -31,6 +36,7 @@ func (m *Model) ToEntity() *Entity {
 
 	return &Entity{
 		ID:        m.ID,
+		TenantID:  m.TenantID,
 		Name:      m.Name,
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/database"
 	"pack-management/internal/pkg/validator"
 	"time"
 
@@ -38,11 +40,17 @@ func (p *RepositoryParams) validate() {
 }
 
 func (r *mysqlRepository) Create(ctx context.Context, person *Entity) error {
+	tenantID, err := tenant.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
 	person.ID = r.newID()
+	person.TenantID = tenantID
 	person.CreatedAt = time.Now()
 	person.UpdatedAt = time.Now()
 
-	_, err := r.db.NewInsert().Model(person.ToModel()).Exec(ctx)
+	_, err = database.DB(ctx, r.db).NewInsert().Model(person.ToModel()).Exec(ctx)
 	if err != nil {
 		return err
 	}
@@ -53,7 +61,12 @@ func (r *mysqlRepository) Create(ctx context.Context, person *Entity) error {
 func (r *mysqlRepository) GetByName(ctx context.Context, name string) (*Entity, error) {
 	person := Model{}
 
-	err := r.db.NewSelect().Model(&person).Where("name = ?", name).Scan(ctx)
+	query := database.DB(ctx, r.db).NewSelect().Model(&person).Where("person.name = ?", name)
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "person.tenant_id"); err != nil {
+		return nil, err
+	}
+
+	err := query.Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -65,6 +78,66 @@ func (r *mysqlRepository) GetByName(ctx context.Context, name string) (*Entity,
 	return person.ToEntity(), nil
 }
 
+// ListByNames lists the persons with the names, the oldest first when a
+// name is repeated.
+func (r *mysqlRepository) ListByNames(ctx context.Context, names []string) ([]*Entity, error) {
+	if len(names) == 0 {
+		return []*Entity{}, nil
+	}
+
+	persons := make([]*Model, 0)
+
+	query := database.DB(ctx, r.db).NewSelect().
+		Model(&persons).
+		Where("person.name IN (?)", bun.In(names)).
+		OrderExpr("person.created_at ASC, person.id ASC")
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "person.tenant_id"); err != nil {
+		return nil, err
+	}
+
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*Entity, 0, len(persons))
+	for _, person := range persons {
+		entities = append(entities, person.ToEntity())
+	}
+
+	return entities, nil
+}
+
+func (r *mysqlRepository) CreateMany(ctx context.Context, persons []*Entity) error {
+	if len(persons) == 0 {
+		return nil
+	}
+
+	tenantID, err := tenant.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	models := make([]*Model, 0, len(persons))
+
+	for _, person := range persons {
+		person.ID = r.newID()
+		person.TenantID = tenantID
+		person.CreatedAt = now
+		person.UpdatedAt = now
+
+		models = append(models, person.ToModel())
+	}
+
+	_, err = database.DB(ctx, r.db).NewInsert().Model(&models).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *mysqlRepository) newID() string {
 	return idPrefix + uuid.New().String()
 }
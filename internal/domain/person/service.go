@@ -2,7 +2,9 @@ package person
 
 import (
 	"context"
+	"pack-management/internal/pkg/tracing"
 	"pack-management/internal/pkg/validator"
+	"slices"
 )
 
 type (
@@ -10,6 +12,7 @@ type (
 		Create(ctx context.Context, person *Entity) error
 		GetByName(ctx context.Context, name string) (*Entity, error)
 		GetOrCreateByName(ctx context.Context, name string) (*Entity, error)
+		GetOrCreateByNames(ctx context.Context, names []string) (map[string]*Entity, error)
 	}
 
 	service struct {
@@ -45,6 +48,9 @@ func (s *service) GetByName(ctx context.Context, name string) (*Entity, error) {
 }
 
 func (s *service) GetOrCreateByName(ctx context.Context, name string) (*Entity, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "person.GetOrCreateByName")
+	defer span.End()
+
 	personEntity, err := s.GetByName(ctx, name)
 	if personEntity != nil && err == nil {
 		return personEntity, nil
@@ -65,3 +71,44 @@ func (s *service) GetOrCreateByName(ctx context.Context, name string) (*Entity,
 
 	return personEntity, nil
 }
+
+// GetOrCreateByNames resolves many names with one lookup and one insert of
+// the missing ones, the result is keyed by name.
+func (s *service) GetOrCreateByNames(ctx context.Context, names []string) (map[string]*Entity, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "person.GetOrCreateByNames")
+	defer span.End()
+
+	names = slices.Compact(slices.Sorted(slices.Values(names)))
+
+	persons, err := s.repo.ListByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*Entity, len(names))
+	for _, person := range persons {
+		if _, ok := byName[person.Name]; !ok {
+			byName[person.Name] = person
+		}
+	}
+
+	missing := []*Entity{}
+	for _, name := range names {
+		if _, ok := byName[name]; !ok {
+			missing = append(missing, &Entity{Name: name})
+		}
+	}
+
+	if len(missing) > 0 {
+		err = s.repo.CreateMany(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, person := range missing {
+			byName[person.Name] = person
+		}
+	}
+
+	return byName, nil
+}
@@ -0,0 +1,39 @@
+package search
+
+import (
+	"pack-management/internal/pkg/cerrors"
+)
+
+type (
+	// Entity is a search hit, Fields holds the searched values of the hit and
+	// Highlights the ones that matched the query with the terms marked.
+	Entity struct {
+		Type       ResultType
+		ID         string
+		PackID     string
+		Score      float64
+		Fields     map[string]string
+		Highlights map[string]string
+	}
+
+	Query struct {
+		Text  string
+		Limit int
+	}
+
+	ResultType string
+)
+
+var (
+	ResultTypePack  ResultType = "pack"
+	ResultTypeEvent ResultType = "event"
+
+	ErrInvalidQuery = cerrors.New("the search query must have a term with at least 3 characters", cerrors.CodeInvalidSearchQuery)
+)
+
+const (
+	FieldDescription = "description"
+	FieldSender      = "sender"
+	FieldRecipient   = "recipient"
+	FieldLocation    = "location"
+)
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	handler struct {
+		service Service
+		app     *fiber.App
+	}
+
+	HandlerParams struct {
+		App     *fiber.App `validate:"required"`
+		Service Service    `validate:"required"`
+	}
+
+	SearchQuery struct {
+		Text  string `query:"q" validate:"required"`
+		Limit int    `query:"limit" validate:"gte=0"`
+	}
+
+	SearchJSON struct {
+		Items []*ResultJSON `json:"items"`
+	}
+
+	ResultJSON struct {
+		Type       ResultType        `json:"type"`
+		ID         string            `json:"id"`
+		PackID     string            `json:"pack_id"`
+		Score      float64           `json:"score"`
+		Highlights map[string]string `json:"highlights"`
+	}
+)
+
+func NewHTPPHandler(params *HandlerParams) *handler {
+	params.validate()
+
+	h := &handler{
+		service: params.Service,
+		app:     params.App,
+	}
+
+	h.app.Get("/search", auth.RequireScope(auth.ScopePacksRead), h.search)
+
+	return h
+}
+
+func (p *HandlerParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (h *handler) search(ctx *fiber.Ctx) error {
+	queries := &SearchQuery{}
+	if err := ctx.QueryParser(queries); err != nil {
+		return cerrors.ErrInvalidQuery
+	}
+
+	err := validator.ValidateStruct(queries)
+	if err != nil {
+		return err
+	}
+
+	results, err := h.service.Search(ctx.UserContext(), queries.ToEntity())
+	if err != nil {
+		return err
+	}
+
+	resp := &SearchJSON{
+		Items: make([]*ResultJSON, 0, len(results)),
+	}
+
+	for _, result := range results {
+		resp.Items = append(resp.Items, &ResultJSON{
+			Type:       result.Type,
+			ID:         result.ID,
+			PackID:     result.PackID,
+			Score:      result.Score,
+			Highlights: result.Highlights,
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (q *SearchQuery) ToEntity() *Query {
+	return &Query{
+		Text:  q.Text,
+		Limit: q.Limit,
+	}
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"context"
+)
+
+type (
+	// Repository is the search index. The MySQL implementation uses the
+	// FULLTEXT indexes, an embedded index (e.g.: bleve) can implement it to
+	// run without MySQL in local development.
+	Repository interface {
+		Search(ctx context.Context, query *Query) ([]*Entity, error)
+	}
+
+	packModel struct {
+		ID           string  `bun:"id"`
+		Description  string  `bun:"description"`
+		SenderName   string  `bun:"sender_name"`
+		ReceiverName string  `bun:"receiver_name"`
+		Score        float64 `bun:"score"`
+	}
+
+	eventModel struct {
+		ID          string  `bun:"id"`
+		PackID      string  `bun:"pack_id"`
+		Description string  `bun:"description"`
+		Location    string  `bun:"location"`
+		Score       float64 `bun:"score"`
+	}
+)
+
+func (m *packModel) ToEntity() *Entity {
+	if m == nil {
+		return nil
+	}
+
+	return &Entity{
+		Type:   ResultTypePack,
+		ID:     m.ID,
+		PackID: m.ID,
+		Score:  m.Score,
+		Fields: map[string]string{
+			FieldDescription: m.Description,
+			FieldSender:      m.SenderName,
+			FieldRecipient:   m.ReceiverName,
+		},
+	}
+}
+
+func (m *eventModel) ToEntity() *Entity {
+	if m == nil {
+		return nil
+	}
+
+	return &Entity{
+		Type:   ResultTypeEvent,
+		ID:     m.ID,
+		PackID: m.PackID,
+		Score:  m.Score,
+		Fields: map[string]string{
+			FieldDescription: m.Description,
+			FieldLocation:    m.Location,
+		},
+	}
+}
@@ -0,0 +1,143 @@
+package search
+
+import (
+	"context"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/fulltext"
+	"pack-management/internal/pkg/validator"
+	"slices"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	RepositoryParams struct {
+		DB *bun.DB `validate:"required"`
+	}
+
+	mysqlRepository struct {
+		db *bun.DB
+	}
+)
+
+const (
+	packsByDescriptionQuery = `
+		SELECT pack.id, pack.description, sender.name AS sender_name, receiver.name AS receiver_name,
+			MATCH (pack.description) AGAINST (? IN BOOLEAN MODE) AS score
+		FROM pack
+		JOIN person AS sender ON sender.id = pack.sender_id
+		JOIN person AS receiver ON receiver.id = pack.receiver_id
+		WHERE MATCH (pack.description) AGAINST (? IN BOOLEAN MODE)
+			AND pack.tenant_id = ?
+		ORDER BY score DESC
+		LIMIT ?`
+
+	packsByPersonQuery = `
+		SELECT pack.id, pack.description, sender.name AS sender_name, receiver.name AS receiver_name,
+			matched.score
+		FROM (
+			SELECT person.id, MATCH (person.name) AGAINST (? IN BOOLEAN MODE) AS score
+			FROM person
+			WHERE MATCH (person.name) AGAINST (? IN BOOLEAN MODE)
+				AND person.tenant_id = ?
+			ORDER BY score DESC
+			LIMIT ?
+		) AS matched
+		JOIN pack ON pack.sender_id = matched.id OR pack.receiver_id = matched.id
+		JOIN person AS sender ON sender.id = pack.sender_id
+		JOIN person AS receiver ON receiver.id = pack.receiver_id
+		ORDER BY matched.score DESC
+		LIMIT ?`
+
+	eventsQuery = `
+		SELECT pack_event.id, pack_event.pack_id, pack_event.description, pack_event.location,
+			MATCH (pack_event.location, pack_event.description) AGAINST (? IN BOOLEAN MODE) AS score
+		FROM pack_event
+		WHERE MATCH (pack_event.location, pack_event.description) AGAINST (? IN BOOLEAN MODE)
+			AND pack_event.tenant_id = ?
+		ORDER BY score DESC
+		LIMIT ?`
+)
+
+func NewMysqlRepository(params *RepositoryParams) Repository {
+	params.validate()
+
+	return &mysqlRepository{
+		db: params.DB,
+	}
+}
+
+func (p *RepositoryParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Search runs a query per FULLTEXT index and merges the hits, a pack matched
+// by its description and by a person name sums both scores. The hits are of
+// the tenant of ctx only, the packs matched by a person belong to it too.
+func (r *mysqlRepository) Search(ctx context.Context, query *Query) ([]*Entity, error) {
+	tenantID, err := tenant.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	search := fulltext.BooleanQuery(query.Text)
+	if search == "" {
+		return []*Entity{}, nil
+	}
+
+	byDescription := []*packModel{}
+	err = r.db.NewRaw(packsByDescriptionQuery, search, search, tenantID, query.Limit).Scan(ctx, &byDescription)
+	if err != nil {
+		return nil, err
+	}
+
+	byPerson := []*packModel{}
+	err = r.db.NewRaw(packsByPersonQuery, search, search, tenantID, query.Limit, query.Limit).Scan(ctx, &byPerson)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*eventModel{}
+	err = r.db.NewRaw(eventsQuery, search, search, tenantID, query.Limit).Scan(ctx, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	packs := map[string]*Entity{}
+	entities := []*Entity{}
+
+	for _, model := range append(byDescription, byPerson...) {
+		if pack, ok := packs[model.ID]; ok {
+			pack.Score += model.Score
+			continue
+		}
+
+		pack := model.ToEntity()
+		packs[model.ID] = pack
+		entities = append(entities, pack)
+	}
+
+	for _, model := range events {
+		entities = append(entities, model.ToEntity())
+	}
+
+	slices.SortStableFunc(entities, func(a, b *Entity) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	if len(entities) > query.Limit {
+		entities = entities[:query.Limit]
+	}
+
+	return entities, nil
+}
@@ -0,0 +1,149 @@
+package search
+
+import (
+	"context"
+	"html"
+	"pack-management/internal/pkg/fulltext"
+	"pack-management/internal/pkg/validator"
+	"strings"
+	"unicode"
+)
+
+type (
+	Service interface {
+		Search(ctx context.Context, query *Query) ([]*Entity, error)
+	}
+
+	service struct {
+		repo         Repository
+		defaultLimit int
+		maxLimit     int
+	}
+
+	ServiceParams struct {
+		Repo Repository `validate:"required"`
+		// DefaultLimit and MaxLimit are optional, 20 and 100 by default.
+		DefaultLimit int `validate:"gte=0"`
+		MaxLimit     int `validate:"gte=0"`
+	}
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+
+	highlightStart = "<mark>"
+	highlightEnd   = "</mark>"
+)
+
+func NewService(params *ServiceParams) Service {
+	params.validate()
+
+	if params.DefaultLimit == 0 {
+		params.DefaultLimit = defaultLimit
+	}
+
+	if params.MaxLimit == 0 {
+		params.MaxLimit = maxLimit
+	}
+
+	return &service{
+		repo:         params.Repo,
+		defaultLimit: params.DefaultLimit,
+		maxLimit:     params.MaxLimit,
+	}
+}
+
+func (p *ServiceParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Search returns the hits ranked by relevance with the matched fields
+// highlighted.
+func (s *service) Search(ctx context.Context, query *Query) ([]*Entity, error) {
+	terms := fulltext.Terms(query.Text)
+	if len(terms) == 0 {
+		return nil, ErrInvalidQuery
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = s.defaultLimit
+	}
+
+	if query.Limit > s.maxLimit {
+		query.Limit = s.maxLimit
+	}
+
+	results, err := s.repo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		result.Highlights = map[string]string{}
+
+		for field, value := range result.Fields {
+			if highlighted, ok := highlight(value, terms); ok {
+				result.Highlights[field] = highlighted
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// highlight HTML escapes the value and marks its words starting with one of
+// the terms, the same prefix match of the full-text query.
+func highlight(value string, terms []string) (string, bool) {
+	builder := strings.Builder{}
+	matched := false
+	wordStart := -1
+
+	writeWord := func(word string) {
+		if !hasTermPrefix(word, terms) {
+			builder.WriteString(html.EscapeString(word))
+			return
+		}
+
+		matched = true
+		builder.WriteString(highlightStart + html.EscapeString(word) + highlightEnd)
+	}
+
+	for i, r := range value {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if wordStart < 0 {
+				wordStart = i
+			}
+
+			continue
+		}
+
+		if wordStart >= 0 {
+			writeWord(value[wordStart:i])
+			wordStart = -1
+		}
+
+		builder.WriteString(html.EscapeString(string(r)))
+	}
+
+	if wordStart >= 0 {
+		writeWord(value[wordStart:])
+	}
+
+	return builder.String(), matched
+}
+
+func hasTermPrefix(word string, terms []string) bool {
+	word = strings.ToLower(word)
+
+	for _, term := range terms {
+		if strings.HasPrefix(word, strings.ToLower(term)) {
+			return true
+		}
+	}
+
+	return false
+}
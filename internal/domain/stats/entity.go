@@ -0,0 +1,39 @@
+package stats
+
+import (
+	"pack-management/internal/pkg/cerrors"
+	"time"
+)
+
+type (
+	// DeliveryFilters is an inclusive range of UTC days.
+	DeliveryFilters struct {
+		From       time.Time
+		To         time.Time
+		SenderName *string
+	}
+
+	// DeliveryStats counts the packs created, delivered and canceled in the
+	// range. The transit times and the on-time rate are of the packs
+	// delivered in the range, nil when there is none.
+	DeliveryStats struct {
+		Created            int
+		Delivered          int
+		Canceled           int
+		AverageTransitTime *time.Duration
+		P95TransitTime     *time.Duration
+		OnTimeRate         *float64
+		Series             []*DailyStats
+	}
+
+	DailyStats struct {
+		Date      time.Time
+		Created   int
+		Delivered int
+		Canceled  int
+	}
+)
+
+var (
+	ErrInvalidRange = cerrors.New("the informed date range is invalid", cerrors.CodeInvalidRange)
+)
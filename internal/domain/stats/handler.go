@@ -0,0 +1,141 @@
+package stats
+
+import (
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	handler struct {
+		service Service
+		app     *fiber.App
+	}
+
+	HandlerParams struct {
+		App     *fiber.App `validate:"required"`
+		Service Service    `validate:"required"`
+	}
+
+	// DeliveryStatsQuery dates are YYYY-MM-DD UTC days.
+	DeliveryStatsQuery struct {
+		From       string  `query:"from" validate:"omitempty,datetime=2006-01-02"`
+		To         string  `query:"to" validate:"omitempty,datetime=2006-01-02"`
+		SenderName *string `query:"sender_name"`
+	}
+
+	DeliveryStatsJSON struct {
+		From                  string            `json:"from"`
+		To                    string            `json:"to"`
+		SenderName            *string           `json:"sender_name,omitempty"`
+		Created               int               `json:"created"`
+		Delivered             int               `json:"delivered"`
+		Canceled              int               `json:"canceled"`
+		AverageTransitSeconds *float64          `json:"average_transit_seconds,omitempty"`
+		P95TransitSeconds     *float64          `json:"p95_transit_seconds,omitempty"`
+		OnTimeRate            *float64          `json:"on_time_rate,omitempty"`
+		Series                []*DailyStatsJSON `json:"series"`
+	}
+
+	DailyStatsJSON struct {
+		Date      string `json:"date"`
+		Created   int    `json:"created"`
+		Delivered int    `json:"delivered"`
+		Canceled  int    `json:"canceled"`
+	}
+)
+
+func NewHTPPHandler(params *HandlerParams) *handler {
+	params.validate()
+
+	h := &handler{
+		service: params.Service,
+		app:     params.App,
+	}
+
+	group := h.app.Group("/stats")
+	group.Get("/deliveries", auth.RequireScope(auth.ScopePacksRead), h.getDeliveryStats)
+
+	return h
+}
+
+func (p *HandlerParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (h *handler) getDeliveryStats(ctx *fiber.Ctx) error {
+	queries := &DeliveryStatsQuery{}
+	if err := ctx.QueryParser(queries); err != nil {
+		return cerrors.ErrInvalidQuery
+	}
+
+	err := validator.ValidateStruct(queries)
+	if err != nil {
+		return err
+	}
+
+	filters := queries.ToFilters()
+
+	stats, err := h.service.GetDeliveryStats(ctx.UserContext(), filters)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.deliveryStatsToJSON(filters, stats))
+}
+
+func (q *DeliveryStatsQuery) ToFilters() *DeliveryFilters {
+	filters := &DeliveryFilters{
+		SenderName: q.SenderName,
+	}
+
+	if q.From != "" {
+		filters.From, _ = time.Parse(time.DateOnly, q.From)
+	}
+
+	if q.To != "" {
+		filters.To, _ = time.Parse(time.DateOnly, q.To)
+	}
+
+	return filters
+}
+
+func (h *handler) deliveryStatsToJSON(filters *DeliveryFilters, stats *DeliveryStats) *DeliveryStatsJSON {
+	resp := &DeliveryStatsJSON{
+		From:       filters.From.Format(time.DateOnly),
+		To:         filters.To.Format(time.DateOnly),
+		SenderName: filters.SenderName,
+		Created:    stats.Created,
+		Delivered:  stats.Delivered,
+		Canceled:   stats.Canceled,
+		OnTimeRate: stats.OnTimeRate,
+		Series:     make([]*DailyStatsJSON, 0, len(stats.Series)),
+	}
+
+	if stats.AverageTransitTime != nil {
+		seconds := stats.AverageTransitTime.Seconds()
+		resp.AverageTransitSeconds = &seconds
+	}
+
+	if stats.P95TransitTime != nil {
+		seconds := stats.P95TransitTime.Seconds()
+		resp.P95TransitSeconds = &seconds
+	}
+
+	for _, day := range stats.Series {
+		resp.Series = append(resp.Series, &DailyStatsJSON{
+			Date:      day.Date.Format(time.DateOnly),
+			Created:   day.Created,
+			Delivered: day.Delivered,
+			Canceled:  day.Canceled,
+		})
+	}
+
+	return resp
+}
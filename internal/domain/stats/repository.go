@@ -0,0 +1,35 @@
+package stats
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	Repository interface {
+		// DailyCounts returns the days of the range with created, delivered
+		// or canceled packs.
+		DailyCounts(ctx context.Context, filters *DeliveryFilters) ([]*DailyStats, error)
+		// TransitTimes returns the transit time stats of the packs delivered
+		// in the range, the p95 uses the nearest rank.
+		TransitTimes(ctx context.Context, filters *DeliveryFilters) (*TransitTimes, error)
+	}
+
+	TransitTimes struct {
+		Delivered int
+		OnTime    int
+		Average   time.Duration
+		P95       time.Duration
+	}
+
+	dayCountModel struct {
+		Day   time.Time `bun:"day"`
+		Count int       `bun:"count"`
+	}
+
+	transitTimesModel struct {
+		Delivered      int      `bun:"delivered"`
+		OnTime         *int     `bun:"on_time"`
+		AverageSeconds *float64 `bun:"average_seconds"`
+	}
+)
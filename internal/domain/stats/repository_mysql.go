@@ -0,0 +1,175 @@
+package stats
+
+import (
+	"context"
+	"math"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/validator"
+	"sort"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	RepositoryParams struct {
+		DB *bun.DB `validate:"required"`
+	}
+
+	mysqlRepository struct {
+		db *bun.DB
+	}
+)
+
+const (
+	transitSecondsExpr = "TIMESTAMPDIFF(SECOND, pack.created_at, pack.delivered_at)"
+	p95Percentile      = 0.95
+)
+
+func NewMysqlRepository(params *RepositoryParams) Repository {
+	params.validate()
+
+	return &mysqlRepository{
+		db: params.DB,
+	}
+}
+
+func (p *RepositoryParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DailyCounts runs a grouped count per date column, each one is backed by
+// the column index.
+func (r *mysqlRepository) DailyCounts(ctx context.Context, filters *DeliveryFilters) ([]*DailyStats, error) {
+	days := map[string]*DailyStats{}
+
+	columns := []struct {
+		name string
+		set  func(day *DailyStats, count int)
+	}{
+		{name: "created_at", set: func(day *DailyStats, count int) { day.Created = count }},
+		{name: "delivered_at", set: func(day *DailyStats, count int) { day.Delivered = count }},
+		{name: "canceled_at", set: func(day *DailyStats, count int) { day.Canceled = count }},
+	}
+
+	for _, column := range columns {
+		counts := []*dayCountModel{}
+
+		query, err := r.rangeQuery(ctx, filters, column.name)
+		if err != nil {
+			return nil, err
+		}
+
+		query.
+			ColumnExpr("DATE(?) AS day", bun.Ident("pack."+column.name)).
+			ColumnExpr("COUNT(*) AS count").
+			GroupExpr("day")
+
+		if err := query.Scan(ctx, &counts); err != nil {
+			return nil, err
+		}
+
+		for _, count := range counts {
+			key := count.Day.Format(time.DateOnly)
+
+			day, ok := days[key]
+			if !ok {
+				day = &DailyStats{Date: count.Day}
+				days[key] = day
+			}
+
+			column.set(day, count.Count)
+		}
+	}
+
+	series := make([]*DailyStats, 0, len(days))
+	for _, day := range days {
+		series = append(series, day)
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Date.Before(series[j].Date)
+	})
+
+	return series, nil
+}
+
+func (r *mysqlRepository) TransitTimes(ctx context.Context, filters *DeliveryFilters) (*TransitTimes, error) {
+	model := transitTimesModel{}
+
+	query, err := r.rangeQuery(ctx, filters, "delivered_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query.
+		ColumnExpr("COUNT(*) AS delivered").
+		ColumnExpr("SUM(DATE(pack.delivered_at) <= DATE(pack.estimated_delivery_date)) AS on_time").
+		ColumnExpr("AVG(" + transitSecondsExpr + ") AS average_seconds")
+
+	if err := query.Scan(ctx, &model); err != nil {
+		return nil, err
+	}
+
+	transitTimes := &TransitTimes{
+		Delivered: model.Delivered,
+	}
+
+	if model.Delivered == 0 {
+		return transitTimes, nil
+	}
+
+	if model.OnTime != nil {
+		transitTimes.OnTime = *model.OnTime
+	}
+
+	if model.AverageSeconds != nil {
+		transitTimes.Average = time.Duration(*model.AverageSeconds * float64(time.Second))
+	}
+
+	p95Offset := int(math.Ceil(p95Percentile*float64(model.Delivered))) - 1
+
+	var p95Seconds int64
+	query, err = r.rangeQuery(ctx, filters, "delivered_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query.
+		ColumnExpr(transitSecondsExpr + " AS transit_seconds").
+		OrderExpr("transit_seconds ASC").
+		Offset(p95Offset).
+		Limit(1)
+
+	if err := query.Scan(ctx, &p95Seconds); err != nil {
+		return nil, err
+	}
+
+	transitTimes.P95 = time.Duration(p95Seconds) * time.Second
+
+	return transitTimes, nil
+}
+
+// rangeQuery selects the packs of the tenant of ctx with the column in the
+// range, the "to" day is inclusive.
+func (r *mysqlRepository) rangeQuery(ctx context.Context, filters *DeliveryFilters, column string) (*bun.SelectQuery, error) {
+	query := r.db.NewSelect().
+		TableExpr("pack AS pack").
+		Where("? >= ?", bun.Ident("pack."+column), filters.From).
+		Where("? < ?", bun.Ident("pack."+column), filters.To.AddDate(0, 0, 1))
+
+	if err := tenant.Scope(ctx, query.QueryBuilder(), "pack.tenant_id"); err != nil {
+		return nil, err
+	}
+
+	if filters.SenderName != nil {
+		query.
+			Join("JOIN person AS sender ON sender.id = pack.sender_id").
+			Where("sender.name = ?", *filters.SenderName)
+	}
+
+	return query, nil
+}
@@ -0,0 +1,119 @@
+package stats
+
+import (
+	"context"
+	"pack-management/internal/pkg/validator"
+	"time"
+)
+
+type (
+	Service interface {
+		GetDeliveryStats(ctx context.Context, filters *DeliveryFilters) (*DeliveryStats, error)
+	}
+
+	service struct {
+		repo         Repository
+		maxRangeDays int
+	}
+
+	ServiceParams struct {
+		Repo Repository `validate:"required"`
+		// MaxRangeDays is optional, 366 by default.
+		MaxRangeDays int `validate:"gte=0"`
+	}
+)
+
+const (
+	maxRangeDays     = 366
+	defaultRangeDays = 30
+)
+
+func NewService(params *ServiceParams) Service {
+	params.validate()
+
+	if params.MaxRangeDays == 0 {
+		params.MaxRangeDays = maxRangeDays
+	}
+
+	return &service{
+		repo:         params.Repo,
+		maxRangeDays: params.MaxRangeDays,
+	}
+}
+
+func (p *ServiceParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GetDeliveryStats returns the stats of the range, by default the last 30
+// days. The series has every day of the range, including the empty ones.
+func (s *service) GetDeliveryStats(ctx context.Context, filters *DeliveryFilters) (*DeliveryStats, error) {
+	if filters.To.IsZero() {
+		filters.To = startOfDay(time.Now().UTC())
+	}
+
+	if filters.From.IsZero() {
+		filters.From = filters.To.AddDate(0, 0, 1-defaultRangeDays)
+	}
+
+	if filters.From.After(filters.To) || filters.From.AddDate(0, 0, s.maxRangeDays).Before(filters.To) {
+		return nil, ErrInvalidRange
+	}
+
+	days, err := s.repo.DailyCounts(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	transitTimes, err := s.repo.TransitTimes(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &DeliveryStats{
+		Series: fillSeries(filters, days),
+	}
+
+	for _, day := range stats.Series {
+		stats.Created += day.Created
+		stats.Delivered += day.Delivered
+		stats.Canceled += day.Canceled
+	}
+
+	if transitTimes.Delivered > 0 {
+		onTimeRate := float64(transitTimes.OnTime) / float64(transitTimes.Delivered)
+
+		stats.AverageTransitTime = &transitTimes.Average
+		stats.P95TransitTime = &transitTimes.P95
+		stats.OnTimeRate = &onTimeRate
+	}
+
+	return stats, nil
+}
+
+func fillSeries(filters *DeliveryFilters, days []*DailyStats) []*DailyStats {
+	byDate := make(map[string]*DailyStats, len(days))
+	for _, day := range days {
+		byDate[day.Date.Format(time.DateOnly)] = day
+	}
+
+	series := []*DailyStats{}
+	for date := filters.From; !date.After(filters.To); date = date.AddDate(0, 0, 1) {
+		day, ok := byDate[date.Format(time.DateOnly)]
+		if !ok {
+			day = &DailyStats{}
+		}
+
+		day.Date = date
+		series = append(series, day)
+	}
+
+	return series
+}
+
+func startOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+}
@@ -0,0 +1,70 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	contextKey struct{}
+
+	systemKey struct{}
+)
+
+var (
+	ErrMissingTenant = errors.New("the context has no tenant")
+)
+
+// WithContext returns a copy of ctx with the tenant id, the repositories
+// scope their queries to it.
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant id of ctx, failing with ErrMissingTenant
+// when it has none.
+func FromContext(ctx context.Context) (string, error) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	if !ok || id == "" {
+		return "", ErrMissingTenant
+	}
+
+	return id, nil
+}
+
+// WithSystem returns a copy of ctx marked as run by the system, e.g.: the
+// jobs, whose queries read and write the rows of every tenant on purpose.
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemKey{}, true)
+}
+
+// IsSystem reports if ctx is marked by WithSystem.
+func IsSystem(ctx context.Context) bool {
+	system, _ := ctx.Value(systemKey{}).(bool)
+
+	return system
+}
+
+// ScopeKey returns the tenant id of ctx or empty, it namespaces keys shared
+// by the tenants, e.g.: the idempotency keys.
+func ScopeKey(ctx context.Context) string {
+	id, _ := FromContext(ctx)
+
+	return id
+}
+
+// Scope adds the condition on the tenant_id column (e.g.: "pack.tenant_id")
+// of the tenant of ctx to the query, failing with ErrMissingTenant when ctx
+// has none, so a query is never run unscoped by mistake.
+func Scope(ctx context.Context, query bun.QueryBuilder, column string) error {
+	id, err := FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query.Where("? = ?", bun.Ident(column), id)
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package tenant
+
+import (
+	"time"
+)
+
+type (
+	// Entity is a brand the service runs for, its packs, persons and events
+	// are isolated from the other tenants. HolidayCountry is the ISO 3166-1
+	// alpha-2 code of the holiday calendar of its packs.
+	Entity struct {
+		ID             string
+		Name           string
+		HolidayCountry string
+		CreatedAt      time.Time
+		UpdatedAt      time.Time
+	}
+)
+
+func (e *Entity) ToModel() *Model {
+	if e == nil {
+		return nil
+	}
+
+	model := &Model{
+		ID:             e.ID,
+		Name:           e.Name,
+		HolidayCountry: e.HolidayCountry,
+		CreatedAt:      e.CreatedAt,
+		UpdatedAt:      e.UpdatedAt,
+	}
+
+	return model
+}
@@ -0,0 +1,49 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	// Scoped is embedded in the models of the tenant tables, its bun query
+	// hooks fail the queries of the model run with neither a tenant nor the
+	// system mark (see WithSystem) in the context, so a query without the
+	// tenant never runs across the tenants by mistake. The queries on a
+	// table expression, e.g.: the aggregations, are checked by Scope.
+	Scoped struct{}
+)
+
+var (
+	_ bun.BeforeSelectHook = (*Scoped)(nil)
+	_ bun.BeforeInsertHook = (*Scoped)(nil)
+	_ bun.BeforeUpdateHook = (*Scoped)(nil)
+	_ bun.BeforeDeleteHook = (*Scoped)(nil)
+)
+
+func (Scoped) BeforeSelect(ctx context.Context, _ *bun.SelectQuery) error {
+	return checkContext(ctx)
+}
+
+func (Scoped) BeforeInsert(ctx context.Context, _ *bun.InsertQuery) error {
+	return checkContext(ctx)
+}
+
+func (Scoped) BeforeUpdate(ctx context.Context, _ *bun.UpdateQuery) error {
+	return checkContext(ctx)
+}
+
+func (Scoped) BeforeDelete(ctx context.Context, _ *bun.DeleteQuery) error {
+	return checkContext(ctx)
+}
+
+func checkContext(ctx context.Context) error {
+	if IsSystem(ctx) {
+		return nil
+	}
+
+	_, err := FromContext(ctx)
+
+	return err
+}
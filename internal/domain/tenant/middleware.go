@@ -0,0 +1,85 @@
+package tenant
+
+import (
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	MiddlewareParams struct {
+		Service Service `validate:"required"`
+		// DefaultTenant is the tenant of the requests with neither a
+		// principal bound to a tenant nor the tenant header, optional, they
+		// are rejected by default.
+		DefaultTenant string
+		// HeaderEnabled accepts the X-Tenant-ID header of the admin
+		// principals bound to no tenant, e.g.: the operators and a trusted
+		// gateway.
+		HeaderEnabled bool
+	}
+)
+
+var (
+	ErrTenantRequired        = cerrors.New("the tenant is required, inform the X-API-Key or X-Tenant-ID header", cerrors.CodeTenantRequired)
+	ErrTenantHeaderForbidden = cerrors.New("the X-Tenant-ID header requires an admin credential bound to no tenant", cerrors.CodeTenantHeaderForbidden)
+)
+
+const (
+	HeaderTenantID = "X-Tenant-ID"
+)
+
+// Middleware resolves the tenant of the request by the tenant of the
+// authenticated principal (see auth.Middleware), the X-Tenant-ID header of
+// an admin principal when enabled or the default tenant, in this order, and
+// stores it in the fiber user context. The header of the other principals is
+// rejected, so a client never acts on a tenant it doesn't belong to.
+func Middleware(params *MiddlewareParams) fiber.Handler {
+	params.validate()
+
+	return func(ctx *fiber.Ctx) error {
+		reqCtx := ctx.UserContext()
+
+		var (
+			tenant *Entity
+			err    error
+		)
+
+		principal := auth.PrincipalFromContext(reqCtx)
+
+		switch {
+		case principal != nil && principal.TenantID != "":
+			tenant, err = params.Service.GetByID(reqCtx, principal.TenantID)
+		case ctx.Get(HeaderTenantID) != "":
+			if !params.HeaderEnabled || principal == nil || !principal.HasScope(auth.ScopeAdmin) {
+				return ErrTenantHeaderForbidden
+			}
+
+			tenant, err = params.Service.GetByID(reqCtx, ctx.Get(HeaderTenantID))
+		case params.DefaultTenant != "":
+			tenant, err = params.Service.GetByID(reqCtx, params.DefaultTenant)
+		default:
+			return ErrTenantRequired
+		}
+
+		if err != nil {
+			return err
+		}
+
+		reqCtx = WithContext(reqCtx, tenant.ID)
+		reqCtx = logger.With(reqCtx, logger.TenantIDKey, tenant.ID)
+		ctx.SetUserContext(reqCtx)
+
+		return ctx.Next()
+	}
+}
+
+func (p *MiddlewareParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
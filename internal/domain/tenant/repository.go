@@ -0,0 +1,38 @@
+package tenant
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	Repository interface {
+		GetByID(ctx context.Context, ID string) (*Entity, error)
+		ListHolidayCountries(ctx context.Context) ([]string, error)
+	}
+
+	Model struct {
+		bun.BaseModel  `bun:"table:tenant,alias:tenant"`
+		ID             string    `bun:"id,pk"`
+		Name           string    `bun:"name"`
+		HolidayCountry string    `bun:"holiday_country"`
+		CreatedAt      time.Time `bun:"created_at"`
+		UpdatedAt      time.Time `bun:"updated_at"`
+	}
+)
+
+func (m *Model) ToEntity() *Entity {
+	if m == nil {
+		return nil
+	}
+
+	return &Entity{
+		ID:             m.ID,
+		Name:           m.Name,
+		HolidayCountry: m.HolidayCountry,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
@@ -0,0 +1,74 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pack-management/internal/pkg/validator"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	RepositoryParams struct {
+		DB *bun.DB `validate:"required"`
+	}
+
+	mysqlRepository struct {
+		db *bun.DB
+	}
+)
+
+func NewMysqlRepository(params *RepositoryParams) Repository {
+	params.validate()
+
+	return &mysqlRepository{
+		db: params.DB,
+	}
+}
+
+func (p *RepositoryParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (r *mysqlRepository) GetByID(ctx context.Context, ID string) (*Entity, error) {
+	return r.get(ctx, "tenant.id = ?", ID)
+}
+
+func (r *mysqlRepository) ListHolidayCountries(ctx context.Context) ([]string, error) {
+	countries := []string{}
+
+	err := r.db.NewSelect().
+		Model((*Model)(nil)).
+		Distinct().
+		Column("holiday_country").
+		Order("holiday_country").
+		Scan(ctx, &countries)
+	if err != nil {
+		return nil, err
+	}
+
+	return countries, nil
+}
+
+func (r *mysqlRepository) get(ctx context.Context, where string, arg string) (*Entity, error) {
+	tenant := Model{}
+
+	err := r.db.NewSelect().
+		Model(&tenant).
+		Where(where, arg).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return tenant.ToEntity(), nil
+}
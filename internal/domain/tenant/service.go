@@ -0,0 +1,98 @@
+package tenant
+
+import (
+	"context"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/validator"
+	"sync"
+	"time"
+)
+
+type (
+	Service interface {
+		GetByID(ctx context.Context, id string) (*Entity, error)
+		ListHolidayCountries(ctx context.Context) ([]string, error)
+	}
+
+	service struct {
+		repo     Repository
+		cacheTTL time.Duration
+		mutex    sync.RWMutex
+		cache    map[string]*cachedTenant
+	}
+
+	ServiceParams struct {
+		Repo Repository `validate:"required"`
+		// CacheTTL is how long a resolved tenant is kept in memory, so the
+		// requests don't query it each time, optional, 1m by default.
+		CacheTTL time.Duration `validate:"gte=0"`
+	}
+
+	cachedTenant struct {
+		tenant    *Entity
+		expiresAt time.Time
+	}
+)
+
+var (
+	ErrTenantNotFound = cerrors.New("the informed tenant was not found", cerrors.CodeTenantNotFound)
+)
+
+const (
+	defaultCacheTTL = time.Minute
+)
+
+func NewService(params *ServiceParams) Service {
+	params.validate()
+
+	if params.CacheTTL == 0 {
+		params.CacheTTL = defaultCacheTTL
+	}
+
+	return &service{
+		repo:     params.Repo,
+		cacheTTL: params.CacheTTL,
+		cache:    map[string]*cachedTenant{},
+	}
+}
+
+func (p *ServiceParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (s *service) GetByID(ctx context.Context, id string) (*Entity, error) {
+	now := time.Now()
+
+	s.mutex.RLock()
+	cached, ok := s.cache[id]
+	s.mutex.RUnlock()
+
+	if ok && cached.expiresAt.After(now) {
+		return cached.tenant, nil
+	}
+
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the missing tenants are not cached so a tenant created meanwhile is
+	// found on the next request
+	if tenant == nil {
+		return nil, ErrTenantNotFound
+	}
+
+	s.mutex.Lock()
+	s.cache[id] = &cachedTenant{tenant: tenant, expiresAt: now.Add(s.cacheTTL)}
+	s.mutex.Unlock()
+
+	return tenant, nil
+}
+
+// ListHolidayCountries lists the distinct holiday countries of the tenants.
+func (s *service) ListHolidayCountries(ctx context.Context) ([]string, error) {
+	return s.repo.ListHolidayCountries(ctx)
+}
@@ -0,0 +1,50 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type (
+	// Sink delivers the alerts, e.g.: to the logs or a webhook.
+	Sink interface {
+		Send(ctx context.Context, alert *Alert) error
+	}
+
+	Alert struct {
+		Name     string            `json:"name"`
+		Severity Severity          `json:"severity"`
+		Message  string            `json:"message"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		FiredAt  time.Time         `json:"fired_at"`
+	}
+
+	Severity string
+
+	multiSink []Sink
+)
+
+var (
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// NewMultiSink sends the alerts to all sinks, a failing sink doesn't stop
+// the others and the errors are joined.
+func NewMultiSink(sinks ...Sink) Sink {
+	return multiSink(sinks)
+}
+
+func (m multiSink) Send(ctx context.Context, alert *Alert) error {
+	errs := []error{}
+
+	for _, sink := range m {
+		err := sink.Send(ctx, alert)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
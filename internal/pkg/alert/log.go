@@ -0,0 +1,31 @@
+package alert
+
+import (
+	"context"
+	"log/slog"
+	"pack-management/internal/pkg/logger"
+)
+
+type (
+	logSink struct{}
+)
+
+// NewLogSink writes the alerts as warning lines of the context logger.
+func NewLogSink() Sink {
+	return &logSink{}
+}
+
+func (s *logSink) Send(ctx context.Context, alert *Alert) error {
+	attrs := []any{
+		slog.String("alert", alert.Name),
+		slog.String("severity", string(alert.Severity)),
+	}
+
+	for key, value := range alert.Labels {
+		attrs = append(attrs, slog.String(key, value))
+	}
+
+	logger.FromContext(ctx).WarnContext(ctx, alert.Message, attrs...)
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+type (
+	WebhookParams struct {
+		URL string `validate:"required,url"`
+		// Timeout is optional, 5s by default.
+		Timeout time.Duration `validate:"gte=0"`
+	}
+
+	webhookSink struct {
+		url    string
+		client *http.Client
+	}
+)
+
+const (
+	defaultWebhookTimeout = 5 * time.Second
+)
+
+// NewWebhookSink posts the alerts as JSON to the URL, any 2xx status is a
+// success.
+func NewWebhookSink(params *WebhookParams) Sink {
+	params.validate()
+
+	if params.Timeout == 0 {
+		params.Timeout = defaultWebhookTimeout
+	}
+
+	return &webhookSink{
+		url: params.URL,
+		client: &http.Client{
+			Timeout:   params.Timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+func (p *WebhookParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (s *webhookSink) Send(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("alert webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package cerrors
+
+// The catalogue of the error codes returned by the API, the errors are
+// created with them, e.g.: cerrors.New("pack not found", CodePackNotFound),
+// and get the category of the code. A code is part of the API contract, so
+// it's never renamed.
+const (
+	// request
+	CodeInvalidBody      = "invalid_body"
+	CodeInvalidParams    = "invalid_params"
+	CodeInvalidQuery     = "invalid_query"
+	CodeValidationFailed = "validation_failed"
+	CodeInternal         = "internal_error"
+	CodeUnavailable      = "service_unavailable"
+
+	// auth
+	CodeUnauthenticated   = "unauthenticated"
+	CodeInvalidAPIKey     = "invalid_api_key"
+	CodeInvalidToken      = "invalid_token"
+	CodeInsufficientScope = "insufficient_scope"
+	CodeInvalidScope      = "invalid_scope"
+	CodeAPIKeyNotFound    = "api_key_not_found"
+
+	// tenant
+	CodeTenantRequired        = "tenant_required"
+	CodeTenantNotFound        = "tenant_not_found"
+	CodeTenantHeaderForbidden = "tenant_header_forbidden"
+
+	// pack
+	CodePackNotFound                = "pack_not_found"
+	CodeStatusInvalid               = "status_invalid"
+	CodeCannotCancel                = "cannot_cancel"
+	CodeInvalidFilters              = "invalid_filters"
+	CodePackVersionConflict         = "pack_version_conflict"
+	CodeInvalidDetails              = "invalid_details"
+	CodeDetailsNotEditable          = "details_not_editable"
+	CodeEstimatedDeliveryDateInPast = "estimated_delivery_date_in_past"
+	CodeCarrierNotAssignable        = "carrier_not_assignable"
+	CodeTrackingNumberInUse         = "tracking_number_in_use"
+	CodeInvalidTrackingCode         = "invalid_tracking_code"
+	CodeTooManyRows                 = "too_many_rows"
+	CodeInvalidImportFile           = "invalid_import_file"
+	CodeInvalidExportFormat         = "invalid_export_format"
+	CodeInvalidCursor               = "invalid_cursor"
+
+	// pack event
+	CodePackNotAssigned = "pack_not_assigned"
+
+	// carrier
+	CodeCarrierNotFound      = "carrier_not_found"
+	CodeCarrierAlreadyExists = "carrier_already_exists"
+
+	// search and stats
+	CodeInvalidSearchQuery = "invalid_search_query"
+	CodeInvalidRange       = "invalid_range"
+
+	// job
+	CodeJobNotFound = "job_not_found"
+	CodeJobRunning  = "job_running"
+
+	// idempotency
+	CodeIdempotencyKeyReused     = "idempotency_key_reused"
+	CodeIdempotencyKeyInProgress = "idempotency_key_in_progress"
+	CodeInvalidIdempotencyKey    = "invalid_idempotency_key"
+
+	// rate limit
+	CodeRateLimited = "rate_limited"
+)
+
+var (
+	ErrInvalidBody   = New("the request body is malformed or has an unsupported content type", CodeInvalidBody)
+	ErrInvalidParams = New("the request path parameters are invalid", CodeInvalidParams)
+	ErrInvalidQuery  = New("the request query parameters are invalid", CodeInvalidQuery)
+	ErrValidation    = New("the request has invalid fields, see the errors", CodeValidationFailed)
+	ErrInternal      = New("an unexpected error happened, retry later", CodeInternal)
+	ErrUnavailable   = New("the service is temporarily unavailable, retry later", CodeUnavailable)
+
+	// categories are the categories of the codes, the codes not listed are
+	// internal.
+	categories = map[string]Category{
+		CodeInvalidBody:      CategoryInvalid,
+		CodeInvalidParams:    CategoryInvalid,
+		CodeInvalidQuery:     CategoryInvalid,
+		CodeValidationFailed: CategoryInvalid,
+		CodeInternal:         CategoryInternal,
+		CodeUnavailable:      CategoryUnavailable,
+
+		CodeUnauthenticated:   CategoryUnauthenticated,
+		CodeInvalidAPIKey:     CategoryUnauthenticated,
+		CodeInvalidToken:      CategoryUnauthenticated,
+		CodeInsufficientScope: CategoryForbidden,
+		CodeInvalidScope:      CategoryInvalid,
+		CodeAPIKeyNotFound:    CategoryNotFound,
+
+		CodeTenantRequired:        CategoryUnauthenticated,
+		CodeTenantNotFound:        CategoryInvalid,
+		CodeTenantHeaderForbidden: CategoryForbidden,
+
+		CodePackNotFound:                CategoryNotFound,
+		CodeStatusInvalid:               CategoryInvalid,
+		CodeCannotCancel:                CategoryInvalid,
+		CodeInvalidFilters:              CategoryInvalid,
+		CodePackVersionConflict:         CategoryPreconditionFailed,
+		CodeInvalidDetails:              CategoryInvalid,
+		CodeDetailsNotEditable:          CategoryInvalid,
+		CodeEstimatedDeliveryDateInPast: CategoryInvalid,
+		CodeCarrierNotAssignable:        CategoryInvalid,
+		CodeTrackingNumberInUse:         CategoryConflict,
+		CodeInvalidTrackingCode:         CategoryInvalid,
+		CodeTooManyRows:                 CategoryInvalid,
+		CodeInvalidImportFile:           CategoryInvalid,
+		CodeInvalidExportFormat:         CategoryInvalid,
+		CodeInvalidCursor:               CategoryInvalid,
+
+		CodePackNotAssigned: CategoryForbidden,
+
+		CodeCarrierNotFound:      CategoryNotFound,
+		CodeCarrierAlreadyExists: CategoryConflict,
+
+		CodeInvalidSearchQuery: CategoryInvalid,
+		CodeInvalidRange:       CategoryInvalid,
+
+		CodeJobNotFound: CategoryNotFound,
+		CodeJobRunning:  CategoryConflict,
+
+		CodeIdempotencyKeyReused:     CategoryConflict,
+		CodeIdempotencyKeyInProgress: CategoryConflict,
+		CodeInvalidIdempotencyKey:    CategoryInvalid,
+
+		CodeRateLimited: CategoryRateLimited,
+	}
+)
@@ -0,0 +1,42 @@
+package cerrors
+
+import "github.com/gofiber/fiber/v2"
+
+// Category is the kind of an error, it sets the HTTP status of the error.
+type Category string
+
+const (
+	CategoryInvalid            Category = "invalid"
+	CategoryUnauthenticated    Category = "unauthenticated"
+	CategoryForbidden          Category = "forbidden"
+	CategoryNotFound           Category = "not_found"
+	CategoryConflict           Category = "conflict"
+	CategoryPreconditionFailed Category = "precondition_failed"
+	CategoryRateLimited        Category = "rate_limited"
+	CategoryInternal           Category = "internal"
+	CategoryUnavailable        Category = "unavailable"
+)
+
+var (
+	categoryStatuses = map[Category]int{
+		CategoryInvalid:            fiber.StatusBadRequest,
+		CategoryUnauthenticated:    fiber.StatusUnauthorized,
+		CategoryForbidden:          fiber.StatusForbidden,
+		CategoryNotFound:           fiber.StatusNotFound,
+		CategoryConflict:           fiber.StatusConflict,
+		CategoryPreconditionFailed: fiber.StatusPreconditionFailed,
+		CategoryRateLimited:        fiber.StatusTooManyRequests,
+		CategoryInternal:           fiber.StatusInternalServerError,
+		CategoryUnavailable:        fiber.StatusServiceUnavailable,
+	}
+)
+
+// Status is the HTTP status of the category, 500 when it's unknown.
+func (c Category) Status() int {
+	status, ok := categoryStatuses[c]
+	if !ok {
+		return fiber.StatusInternalServerError
+	}
+
+	return status
+}
@@ -0,0 +1,148 @@
+package cerrors
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/validator"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	// Problem is the RFC 7807 error body of every API error, Code is the
+	// catalogue code, Errors the invalid fields of the validation errors and
+	// Metadata the details of the error, e.g.: the current pack version.
+	Problem struct {
+		Type      string                  `json:"type"`
+		Title     string                  `json:"title"`
+		Status    int                     `json:"status"`
+		Detail    string                  `json:"detail,omitempty"`
+		Instance  string                  `json:"instance,omitempty"`
+		Code      string                  `json:"code"`
+		RequestID string                  `json:"request_id,omitempty"`
+		Errors    []*validator.FieldError `json:"errors,omitempty"`
+		Metadata  map[string]any          `json:"metadata,omitempty"`
+	}
+
+	// ValidationError is an invalid request, Err is the error returned to
+	// the client with the invalid fields of Cause.
+	ValidationError struct {
+		Err   *Error
+		Cause error
+	}
+)
+
+const (
+	ContentTypeProblem = "application/problem+json"
+
+	typePrefix = "urn:pack-management:error:"
+)
+
+// NewValidationError returns err, the validator error, as an invalid request
+// with the code of target, e.g.: a domain specific code.
+func NewValidationError(target *Error, err error) *ValidationError {
+	return &ValidationError{
+		Err:   target,
+		Cause: err,
+	}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Handler is the fiber ErrorHandler, it writes the errors returned by the
+// handlers and middlewares as problems with the status of their category:
+// the catalogue errors, the validator errors with the invalid fields, the
+// fiber errors, e.g.: an unknown route, and the unknown errors, including the
+// recovered panics, as a 500. The causes are only logged.
+func Handler(ctx *fiber.Ctx, err error) error {
+	var (
+		cerr          *Error
+		fiberErr      *fiber.Error
+		validationErr *ValidationError
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		problem := newProblem(ctx, validationErr.Err)
+		problem.Errors = validator.Fields(validationErr.Cause)
+
+		return send(ctx, problem)
+	case validator.Fields(err) != nil:
+		return Handler(ctx, NewValidationError(ErrValidation, err))
+	case errors.As(err, &cerr):
+		logCause(ctx, cerr)
+		return send(ctx, newProblem(ctx, cerr))
+	case errors.As(err, &fiberErr) && fiberErr.Code < fiber.StatusInternalServerError:
+		return send(ctx, &Problem{
+			Type:      typePrefix + statusCode(fiberErr.Code),
+			Title:     http.StatusText(fiberErr.Code),
+			Status:    fiberErr.Code,
+			Detail:    fiberErr.Message,
+			Instance:  ctx.Path(),
+			Code:      statusCode(fiberErr.Code),
+			RequestID: ctx.GetRespHeader(fiber.HeaderXRequestID),
+		})
+	case errors.Is(err, context.DeadlineExceeded):
+		// a query timeout, the database is overloaded
+		return Handler(ctx, Wrap(err, ErrUnavailable))
+	}
+
+	return Handler(ctx, Wrap(err, ErrInternal))
+}
+
+// logCause logs the server errors and the cause of the wrapped errors, the
+// plain client errors are expected, so they are not logged.
+func logCause(ctx *fiber.Ctx, err *Error) {
+	status := err.Status()
+	if err.cause == nil && status < fiber.StatusInternalServerError {
+		return
+	}
+
+	level := slog.LevelWarn
+	if status >= fiber.StatusInternalServerError {
+		level = slog.LevelError
+	}
+
+	args := []any{"code", err.Code, "status", status}
+	if err.cause != nil {
+		args = append(args, logger.Error(err.cause))
+	}
+
+	reqCtx := ctx.UserContext()
+	logger.FromContext(reqCtx).Log(reqCtx, level, "request error", args...)
+}
+
+func newProblem(ctx *fiber.Ctx, err *Error) *Problem {
+	status := err.Status()
+
+	return &Problem{
+		Type:      typePrefix + err.Code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.Message,
+		Instance:  ctx.Path(),
+		Code:      err.Code,
+		RequestID: ctx.GetRespHeader(fiber.HeaderXRequestID),
+		Metadata:  err.Metadata,
+	}
+}
+
+func send(ctx *fiber.Ctx, problem *Problem) error {
+	return ctx.Status(problem.Status).JSON(problem, ContentTypeProblem)
+}
+
+// statusCode is the code of the fiber errors, the status text in snake case,
+// e.g.: "not_found" or "request_entity_too_large".
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
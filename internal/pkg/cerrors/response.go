@@ -1,34 +1,53 @@
 package cerrors
 
-import "errors"
+import (
+	"errors"
+	"maps"
+)
 
 type (
+	// Error is a catalogue error, Category is its HTTP status, Metadata the
+	// extra details returned to the client and the wrapped cause is only
+	// logged, never returned.
 	Error struct {
-		Message      string `json:"message"`
-		Code         string `json:"code,omitempty"`
+		Message      string         `json:"message"`
+		Code         string         `json:"code,omitempty"`
+		Category     Category       `json:"-"`
+		Metadata     map[string]any `json:"metadata,omitempty"`
+		cause        error
 		matcherError error
 	}
-
-	JSONError struct {
-		Message string `json:"message"`
-		Code    string `json:"code,omitempty"`
-	}
 )
 
+// New creates the error of the catalogue code, with the category of the code.
 func New(message string, code string) *Error {
 	matcherError := errors.New("")
 
 	return &Error{
 		Message:      message,
 		Code:         code,
+		Category:     categories[code],
 		matcherError: matcherError,
 	}
 }
 
+// Wrap returns a copy of target caused by err, so the copy matches both
+// target and err, e.g.: Wrap(mysqlErr, ErrCarrierAlreadyExists).
+func Wrap(err error, target *Error) *Error {
+	wrapped := target.clone()
+	wrapped.cause = err
+
+	return wrapped
+}
+
 func (e *Error) Error() string {
 	return e.Message
 }
 
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
 func (e *Error) Is(target error) bool {
 	var targetError *Error
 
@@ -40,6 +59,39 @@ func (e *Error) Is(target error) bool {
 	return errors.Is(targetError.matcherError, e.matcherError)
 }
 
+// Status is the HTTP status of the error category.
+func (e *Error) Status() int {
+	return e.Category.Status()
+}
+
+// WithCategory returns a copy of the error with the category, for the errors
+// whose status depends on the route, e.g.: a carrier not found is invalid
+// when assigning a pack.
+func (e *Error) WithCategory(category Category) *Error {
+	categorized := e.clone()
+	categorized.Category = category
+
+	return categorized
+}
+
+// WithMetadata returns a copy of the error with the key set in the metadata.
+func (e *Error) WithMetadata(key string, value any) *Error {
+	withMetadata := e.clone()
+	withMetadata.Metadata = maps.Clone(e.Metadata)
+	if withMetadata.Metadata == nil {
+		withMetadata.Metadata = map[string]any{}
+	}
+
+	withMetadata.Metadata[key] = value
+
+	return withMetadata
+}
+
+func (e *Error) clone() *Error {
+	cloned := *e
+	return &cloned
+}
+
 func Is(err error, target error) bool {
 	return errors.Is(err, target)
 }
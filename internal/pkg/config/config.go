@@ -1,37 +1,386 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"pack-management/internal/pkg/helpers"
+	"pack-management/internal/pkg/validator"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
 type (
+	Params struct {
+		// File is an optional YAML or TOML file, when empty the CONFIG_FILE
+		// env variable is used. Env variables override the file values.
+		File string
+	}
+
 	Config struct {
-		DBHost     string `env:"DB_HOST,required"`
-		DBPort     string `env:"DB_PORT,required"`
-		DBName     string `env:"DB_NAME,required"`
-		DBUser     string `env:"DB_USER,required"`
-		DBPassword string `env:"DB_PASSWORD,required"`
+		App         AppConfig         `yaml:"app" toml:"app"`
+		Database    DatabaseConfig    `yaml:"database" toml:"database" envPrefix:"DB_"`
+		Logger      LoggerConfig      `yaml:"logger" toml:"logger" envPrefix:"LOGGER_"`
+		Tracing     TracingConfig     `yaml:"tracing" toml:"tracing" envPrefix:"TRACING_"`
+		Health      HealthConfig      `yaml:"health" toml:"health" envPrefix:"HEALTH_"`
+		APIs        APIsConfig        `yaml:"apis" toml:"apis"`
+		Pagination  PaginationConfig  `yaml:"pagination" toml:"pagination" envPrefix:"PAGINATION_"`
+		Pack        PackConfig        `yaml:"pack" toml:"pack" envPrefix:"PACK_"`
+		PackEvent   PackEventConfig   `yaml:"pack_event" toml:"pack_event" envPrefix:"PACK_EVENT_"`
+		Search      SearchConfig      `yaml:"search" toml:"search" envPrefix:"SEARCH_"`
+		Stats       StatsConfig       `yaml:"stats" toml:"stats" envPrefix:"STATS_"`
+		Alert       AlertConfig       `yaml:"alert" toml:"alert" envPrefix:"ALERT_"`
+		Scheduler   SchedulerConfig   `yaml:"scheduler" toml:"scheduler" envPrefix:"SCHEDULER_"`
+		Idempotency IdempotencyConfig `yaml:"idempotency" toml:"idempotency" envPrefix:"IDEMPOTENCY_"`
+		Tenant      TenantConfig      `yaml:"tenant" toml:"tenant" envPrefix:"TENANT_"`
+		Auth        AuthConfig        `yaml:"auth" toml:"auth" envPrefix:"AUTH_"`
+		RateLimit   RateLimitConfig   `yaml:"rate_limit" toml:"rate_limit" envPrefix:"RATE_LIMIT_"`
+	}
+
+	AppConfig struct {
+		Port            string        `yaml:"port" toml:"port" env:"APP_PORT" validate:"required,numeric"`
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" validate:"gt=0"`
+	}
+
+	DatabaseConfig struct {
+		Host            string  `yaml:"host" toml:"host" env:"HOST" validate:"required"`
+		Port            string  `yaml:"port" toml:"port" env:"PORT" validate:"required,numeric"`
+		Name            string  `yaml:"name" toml:"name" env:"NAME" validate:"required"`
+		User            string  `yaml:"user" toml:"user" env:"USER" validate:"required"`
+		Password        string  `yaml:"password" toml:"password" env:"PASSWORD" validate:"required" secret:"true"`
+		MaxOpenConns    int     `yaml:"max_open_conns" toml:"max_open_conns" env:"MAX_OPEN_CONNS" validate:"gt=0"`
+		IdleConnsFactor float64 `yaml:"idle_conns_factor" toml:"idle_conns_factor" env:"IDLE_CONNS_FACTOR" validate:"gte=0,lte=1"`
+	}
+
+	LoggerConfig struct {
+		Format string `yaml:"format" toml:"format" env:"FORMAT" validate:"oneof=json cli"`
+		Level  string `yaml:"level" toml:"level" env:"LEVEL" validate:"oneof=debug info warn error"`
+	}
+
+	TracingConfig struct {
+		Exporter    string  `yaml:"exporter" toml:"exporter" env:"EXPORTER" validate:"oneof=none stdout otlp"`
+		Endpoint    string  `yaml:"endpoint" toml:"endpoint" env:"ENDPOINT" validate:"omitempty,url"`
+		SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"SAMPLE_RATIO" validate:"gte=0,lte=1"`
+	}
+
+	HealthConfig struct {
+		CheckExternalAPIs        bool          `yaml:"check_external_apis" toml:"check_external_apis" env:"CHECK_EXTERNAL_APIS"`
+		CheckTimeout             time.Duration `yaml:"check_timeout" toml:"check_timeout" env:"CHECK_TIMEOUT" validate:"gt=0"`
+		WorkerHeartbeatMaxAge    time.Duration `yaml:"worker_heartbeat_max_age" toml:"worker_heartbeat_max_age" env:"WORKER_HEARTBEAT_MAX_AGE" validate:"gt=0"`
+		QueueSaturationThreshold float64       `yaml:"queue_saturation_threshold" toml:"queue_saturation_threshold" env:"QUEUE_SATURATION_THRESHOLD" validate:"gt=0,lte=1"`
+	}
+
+	APIsConfig struct {
+		DogAPIURL       string `yaml:"dog_api_url" toml:"dog_api_url" env:"DOG_API_URL" validate:"required,url"`
+		NagerDateAPIURL string `yaml:"nager_date_api_url" toml:"nager_date_api_url" env:"NAGER_DATE_API_URL" validate:"required,url"`
+	}
+
+	PaginationConfig struct {
+		CursorSecret string        `yaml:"cursor_secret" toml:"cursor_secret" env:"CURSOR_SECRET" validate:"required,min=32" secret:"true"`
+		CursorTTL    time.Duration `yaml:"cursor_ttl" toml:"cursor_ttl" env:"CURSOR_TTL" validate:"gt=0"`
+	}
+
+	PackConfig struct {
+		DefaultPageSize     int           `yaml:"default_page_size" toml:"default_page_size" env:"DEFAULT_PAGE_SIZE" validate:"gt=0,ltefield=MaxPageSize"`
+		MaxPageSize         int           `yaml:"max_page_size" toml:"max_page_size" env:"MAX_PAGE_SIZE" validate:"gt=0"`
+		CacheMaxAgeCreated  time.Duration `yaml:"cache_max_age_created" toml:"cache_max_age_created" env:"CACHE_MAX_AGE_CREATED" validate:"gte=0"`
+		CacheMaxAgeInFlight time.Duration `yaml:"cache_max_age_in_flight" toml:"cache_max_age_in_flight" env:"CACHE_MAX_AGE_IN_FLIGHT" validate:"gte=0"`
+		OverdueScanInterval time.Duration `yaml:"overdue_scan_interval" toml:"overdue_scan_interval" env:"OVERDUE_SCAN_INTERVAL" validate:"gt=0"`
+		OverdueBatch        int           `yaml:"overdue_batch" toml:"overdue_batch" env:"OVERDUE_BATCH" validate:"gt=0"`
+		BulkMaxRows         int           `yaml:"bulk_max_rows" toml:"bulk_max_rows" env:"BULK_MAX_ROWS" validate:"gt=0"`
+		ExportPageSize      int           `yaml:"export_page_size" toml:"export_page_size" env:"EXPORT_PAGE_SIZE" validate:"gt=0"`
+		TrackingCodePrefix  string        `yaml:"tracking_code_prefix" toml:"tracking_code_prefix" env:"TRACKING_CODE_PREFIX" validate:"len=2,alpha,uppercase"`
+	}
+
+	PackEventConfig struct {
+		QueueBuffer int           `yaml:"queue_buffer" toml:"queue_buffer" env:"QUEUE_BUFFER" validate:"gt=0"`
+		Retention   time.Duration `yaml:"retention" toml:"retention" env:"RETENTION" validate:"gt=0"`
+	}
+
+	SearchConfig struct {
+		DefaultLimit int `yaml:"default_limit" toml:"default_limit" env:"DEFAULT_LIMIT" validate:"gt=0,ltefield=MaxLimit"`
+		MaxLimit     int `yaml:"max_limit" toml:"max_limit" env:"MAX_LIMIT" validate:"gt=0"`
+	}
+
+	// AlertConfig Sinks are "log" and "webhook", the webhook requires the
+	// WebhookURL.
+	AlertConfig struct {
+		Sinks          []string      `yaml:"sinks" toml:"sinks" env:"SINKS" envSeparator:"," validate:"min=1,dive,oneof=log webhook"`
+		WebhookURL     string        `yaml:"webhook_url" toml:"webhook_url" env:"WEBHOOK_URL" validate:"omitempty,url" secret:"true"`
+		WebhookTimeout time.Duration `yaml:"webhook_timeout" toml:"webhook_timeout" env:"WEBHOOK_TIMEOUT" validate:"gt=0"`
+	}
+
+	StatsConfig struct {
+		MaxRangeDays int `yaml:"max_range_days" toml:"max_range_days" env:"MAX_RANGE_DAYS" validate:"gt=0"`
+	}
+
+	// SchedulerConfig schedules are cron expressions, descriptors (e.g.:
+	// "@daily") or "@every <duration>". Instance is the hostname by default,
+	// RunRetention how long the run history is kept.
+	SchedulerConfig struct {
+		Enabled          bool          `yaml:"enabled" toml:"enabled" env:"ENABLED"`
+		Instance         string        `yaml:"instance" toml:"instance" env:"INSTANCE"`
+		JobTimeout       time.Duration `yaml:"job_timeout" toml:"job_timeout" env:"JOB_TIMEOUT" validate:"gt=0"`
+		HolidayPrefetch  string        `yaml:"holiday_prefetch" toml:"holiday_prefetch" env:"HOLIDAY_PREFETCH" validate:"required"`
+		EnrichmentRetry  string        `yaml:"enrichment_retry" toml:"enrichment_retry" env:"ENRICHMENT_RETRY" validate:"required"`
+		EventPurge       string        `yaml:"event_purge" toml:"event_purge" env:"EVENT_PURGE" validate:"required"`
+		IdempotencyPurge string        `yaml:"idempotency_purge" toml:"idempotency_purge" env:"IDEMPOTENCY_PURGE" validate:"required"`
+		TrackingBackfill string        `yaml:"tracking_backfill" toml:"tracking_backfill" env:"TRACKING_BACKFILL" validate:"required"`
+		RateLimitPurge   string        `yaml:"rate_limit_purge" toml:"rate_limit_purge" env:"RATE_LIMIT_PURGE" validate:"required"`
+		RunPurge         string        `yaml:"run_purge" toml:"run_purge" env:"RUN_PURGE" validate:"required"`
+		RunRetention     time.Duration `yaml:"run_retention" toml:"run_retention" env:"RUN_RETENTION" validate:"gt=0"`
+	}
+
+	// IdempotencyConfig TTL is how long the Idempotency-Key responses are
+	// replayed, ProcessingTimeout when a key left processing can be reused.
+	IdempotencyConfig struct {
+		TTL               time.Duration `yaml:"ttl" toml:"ttl" env:"TTL" validate:"gt=0"`
+		ProcessingTimeout time.Duration `yaml:"processing_timeout" toml:"processing_timeout" env:"PROCESSING_TIMEOUT" validate:"gt=0"`
+	}
+
+	// TenantConfig Default is the tenant of the requests without the
+	// X-API-Key or X-Tenant-ID header, empty rejects them, HeaderEnabled
+	// accepts the X-Tenant-ID header of the admin credentials bound to no
+	// tenant.
+	TenantConfig struct {
+		Default       string        `yaml:"default" toml:"default" env:"DEFAULT"`
+		HeaderEnabled bool          `yaml:"header_enabled" toml:"header_enabled" env:"HEADER_ENABLED"`
+		CacheTTL      time.Duration `yaml:"cache_ttl" toml:"cache_ttl" env:"CACHE_TTL" validate:"gt=0"`
+	}
+
+	// AuthConfig Required rejects the requests without credentials, the
+	// JWT values validate the bearer tokens, which are rejected while
+	// JWTSecret is empty.
+	AuthConfig struct {
+		Required    bool          `yaml:"required" toml:"required" env:"REQUIRED"`
+		JWTSecret   string        `yaml:"jwt_secret" toml:"jwt_secret" env:"JWT_SECRET" validate:"omitempty,min=32" secret:"true"`
+		JWTIssuer   string        `yaml:"jwt_issuer" toml:"jwt_issuer" env:"JWT_ISSUER"`
+		JWTAudience string        `yaml:"jwt_audience" toml:"jwt_audience" env:"JWT_AUDIENCE"`
+		CacheTTL    time.Duration `yaml:"cache_ttl" toml:"cache_ttl" env:"CACHE_TTL" validate:"gt=0"`
+	}
+
+	// RateLimitConfig Store is "memory", each replica limits on its own, or
+	// "mysql", the limits are shared by the replicas. Bulk limits the bulk
+	// creation and import, Export the export and Track the public tracking,
+	// Default the other routes. IP limits each IP before the authentication,
+	// so the credential guessing is limited too.
+	RateLimitConfig struct {
+		Enabled bool          `yaml:"enabled" toml:"enabled" env:"ENABLED"`
+		Store   string        `yaml:"store" toml:"store" env:"STORE" validate:"oneof=memory mysql"`
+		Default RateLimitRule `yaml:"default" toml:"default" envPrefix:"DEFAULT_"`
+		Bulk    RateLimitRule `yaml:"bulk" toml:"bulk" envPrefix:"BULK_"`
+		Export  RateLimitRule `yaml:"export" toml:"export" envPrefix:"EXPORT_"`
+		Track   RateLimitRule `yaml:"track" toml:"track" envPrefix:"TRACK_"`
+		IP      RateLimitRule `yaml:"ip" toml:"ip" envPrefix:"IP_"`
+	}
+
+	// RateLimitRule allows Requests per Period to each client, with bursts
+	// up to Burst requests, Requests when 0.
+	RateLimitRule struct {
+		Requests int           `yaml:"requests" toml:"requests" env:"REQUESTS" validate:"gt=0"`
+		Period   time.Duration `yaml:"period" toml:"period" env:"PERIOD" validate:"gte=1s"`
+		Burst    int           `yaml:"burst" toml:"burst" env:"BURST" validate:"gte=0"`
 	}
 )
 
-func NewConfig() (*Config, error) {
+var (
+	ErrUnsupportedFile   = errors.New("unsupported config file, use .yaml, .yml or .toml")
+	ErrMissingWebhookURL = errors.New("the webhook alert sink requires ALERT_WEBHOOK_URL")
+)
+
+const (
+	redactedValue = "******"
+)
+
+// Default returns the config used when neither the file nor the env
+// variables set a value.
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			Port:            "3300",
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    100,
+			IdleConnsFactor: 0.2,
+		},
+		Logger: LoggerConfig{
+			Format: "json",
+			Level:  "info",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		Health: HealthConfig{
+			CheckTimeout:             2 * time.Second,
+			WorkerHeartbeatMaxAge:    30 * time.Second,
+			QueueSaturationThreshold: 0.9,
+		},
+		APIs: APIsConfig{
+			DogAPIURL:       "https://dogapi.dog/api/v2",
+			NagerDateAPIURL: "https://date.nager.at/api/v3",
+		},
+		Pagination: PaginationConfig{
+			CursorTTL: 24 * time.Hour,
+		},
+		Pack: PackConfig{
+			DefaultPageSize:     100,
+			MaxPageSize:         1000,
+			CacheMaxAgeCreated:  60 * time.Second,
+			CacheMaxAgeInFlight: time.Hour,
+			OverdueScanInterval: 5 * time.Minute,
+			OverdueBatch:        100,
+			BulkMaxRows:         1000,
+			ExportPageSize:      500,
+			TrackingCodePrefix:  "BR",
+		},
+		PackEvent: PackEventConfig{
+			QueueBuffer: 1000,
+			Retention:   2 * 365 * 24 * time.Hour,
+		},
+		Search: SearchConfig{
+			DefaultLimit: 20,
+			MaxLimit:     100,
+		},
+		Stats: StatsConfig{
+			MaxRangeDays: 366,
+		},
+		Alert: AlertConfig{
+			Sinks:          []string{"log"},
+			WebhookTimeout: 5 * time.Second,
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:          true,
+			JobTimeout:       10 * time.Minute,
+			HolidayPrefetch:  "0 3 * * *",
+			EnrichmentRetry:  "@every 10m",
+			EventPurge:       "0 4 * * *",
+			IdempotencyPurge: "@every 1h",
+			TrackingBackfill: "@every 10m",
+			RateLimitPurge:   "@every 1h",
+			RunPurge:         "0 5 * * *",
+			RunRetention:     30 * 24 * time.Hour,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:               24 * time.Hour,
+			ProcessingTimeout: time.Minute,
+		},
+		Tenant: TenantConfig{
+			Default:       "default",
+			HeaderEnabled: false,
+			CacheTTL:      time.Minute,
+		},
+		Auth: AuthConfig{
+			Required: true,
+			CacheTTL: time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Store:   "memory",
+			Default: RateLimitRule{Requests: 1200, Period: time.Minute, Burst: 200},
+			Bulk:    RateLimitRule{Requests: 10, Period: time.Minute},
+			Export:  RateLimitRule{Requests: 10, Period: time.Minute},
+			Track:   RateLimitRule{Requests: 60, Period: time.Minute, Burst: 20},
+			IP:      RateLimitRule{Requests: 3000, Period: time.Minute, Burst: 500},
+		},
+	}
+}
+
+// NewConfig loads the config with the precedence: defaults, file, env
+// variables (including the .env file), and validates the result.
+func NewConfig(params *Params) (*Config, error) {
 	err := loadEnv()
 	if err != nil {
-		log.Println("error loading env file")
+		slog.Warn("error loading env file", "error", err)
+	}
+
+	cfg := Default()
+
+	file := params.File
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
 	}
 
-	var cfg Config
-	err = env.Parse(&cfg)
+	if file != "" {
+		err = loadFile(file, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = env.Parse(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	return &cfg, nil
+	err = validator.ValidateStruct(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(cfg.Alert.Sinks, "webhook") && cfg.Alert.WebhookURL == "" {
+		return nil, ErrMissingWebhookURL
+	}
+
+	return cfg, nil
+}
+
+// Print writes the effective config as YAML with the secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	redacted := *c
+	redact(reflect.ValueOf(&redacted).Elem())
+
+	encoder := yaml.NewEncoder(w)
+	defer encoder.Close()
+
+	return encoder.Encode(redacted)
+}
+
+func loadFile(file string, cfg *Config) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	switch filepath.Ext(file) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, cfg)
+	case ".toml":
+		err = toml.Unmarshal(content, cfg)
+	default:
+		return ErrUnsupportedFile
+	}
+
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", file, err)
+	}
+
+	return nil
+}
+
+func redact(value reflect.Value) {
+	for i := range value.NumField() {
+		field := value.Field(i)
+
+		if field.Kind() == reflect.Struct {
+			redact(field)
+			continue
+		}
+
+		if value.Type().Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "" {
+			field.SetString(redactedValue)
+		}
+	}
 }
 
 func loadEnv() error {
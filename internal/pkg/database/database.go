@@ -12,6 +12,7 @@ import (
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/mysqldialect"
 	"github.com/uptrace/bun/extra/bundebug"
+	"github.com/uptrace/bun/extra/bunotel"
 )
 
 type (
@@ -105,6 +106,7 @@ func (s *service) Connect() (*bun.DB, error) {
 
 	bunDB := bun.NewDB(sqldb, mysqldialect.New())
 	bunDB.AddQueryHook(bundebug.NewQueryHook(bundebug.FromEnv("BUNDEBUG")))
+	bunDB.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName(s.dbName)))
 
 	return bunDB, nil
 }
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	txKey struct{}
+)
+
+// RunInTx runs fn in a transaction carried by its ctx, so the repositories
+// querying DB(ctx, db) join it. Within a transaction fn joins the outer one,
+// which commits or rolls back everything.
+func RunInTx(ctx context.Context, db *bun.DB, fn func(ctx context.Context, tx bun.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(bun.Tx); ok {
+		return fn(ctx, tx)
+	}
+
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx), tx)
+	})
+}
+
+// DB returns the transaction of ctx, or db outside of one.
+func DB(ctx context.Context, db *bun.DB) bun.IDB {
+	if tx, ok := ctx.Value(txKey{}).(bun.Tx); ok {
+		return tx
+	}
+
+	return db
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+type (
+	Format string
+
+	// Record is an exported row. The CSV columns are flat, so the record
+	// encodes its nested fields itself; NDJSON and Parquet use the json
+	// and parquet struct tags.
+	Record interface {
+		CSVHeader() []string
+		CSVRecord() []string
+	}
+
+	// Writer encodes the records to the output as they are written, Close
+	// flushes the buffered ones and, on Parquet, writes the file footer.
+	Writer[T Record] interface {
+		Write(record T) error
+		Close() error
+	}
+
+	csvWriter[T Record] struct {
+		writer      *csv.Writer
+		wroteHeader bool
+	}
+
+	ndjsonWriter[T Record] struct {
+		encoder *json.Encoder
+	}
+
+	parquetWriter[T Record] struct {
+		writer *parquet.GenericWriter[T]
+	}
+)
+
+var (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+
+	ErrUnsupportedFormat = errors.New("unsupported export format, use csv, ndjson or parquet")
+
+	contentTypes = map[Format]string{
+		FormatCSV:     "text/csv; charset=utf-8",
+		FormatNDJSON:  "application/x-ndjson",
+		FormatParquet: "application/vnd.apache.parquet",
+	}
+)
+
+// ParseFormat returns the format of its name, csv by default.
+func ParseFormat(name string) (Format, error) {
+	if name == "" {
+		return FormatCSV, nil
+	}
+
+	format := Format(name)
+	if _, ok := contentTypes[format]; !ok {
+		return "", ErrUnsupportedFormat
+	}
+
+	return format, nil
+}
+
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+// NewWriter returns the writer of the format. rowGroupSize is the number of
+// records Parquet buffers before writing them, so the memory stays bounded.
+func NewWriter[T Record](w io.Writer, format Format, rowGroupSize int) (Writer[T], error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter[T]{writer: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter[T]{encoder: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return &parquetWriter[T]{
+			writer: parquet.NewGenericWriter[T](w, parquet.MaxRowsPerRowGroup(int64(rowGroupSize))),
+		}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// Write writes the header before the first record.
+func (w *csvWriter[T]) Write(record T) error {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+
+		if err := w.writer.Write(record.CSVHeader()); err != nil {
+			return err
+		}
+	}
+
+	return w.writer.Write(record.CSVRecord())
+}
+
+// Close writes the header of the zero record when nothing was exported.
+func (w *csvWriter[T]) Close() error {
+	if !w.wroteHeader {
+		var record T
+
+		if err := w.writer.Write(record.CSVHeader()); err != nil {
+			return err
+		}
+	}
+
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+func (w *ndjsonWriter[T]) Write(record T) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *ndjsonWriter[T]) Close() error {
+	return nil
+}
+
+func (w *parquetWriter[T]) Write(record T) error {
+	_, err := w.writer.Write([]T{record})
+
+	return err
+}
+
+func (w *parquetWriter[T]) Close() error {
+	return w.writer.Close()
+}
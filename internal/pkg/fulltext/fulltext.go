@@ -0,0 +1,45 @@
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MinTokenSize is the InnoDB innodb_ft_min_token_size default, shorter
+	// terms are not indexed.
+	MinTokenSize = 3
+)
+
+// Terms splits the text in the searchable terms, dropping the punctuation,
+// the boolean mode operators and the terms shorter than MinTokenSize.
+func Terms(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if utf8.RuneCountInString(word) < MinTokenSize {
+			continue
+		}
+
+		terms = append(terms, word)
+	}
+
+	return terms
+}
+
+// BooleanQuery builds a MySQL "IN BOOLEAN MODE" search requiring every term
+// of the text as a prefix, it is empty when the text has no terms.
+func BooleanQuery(text string) string {
+	terms := Terms(text)
+
+	search := make([]string, 0, len(terms))
+	for _, term := range terms {
+		search = append(search, "+"+term+"*")
+	}
+
+	return strings.Join(search, " ")
+}
@@ -0,0 +1,233 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	MiddlewareParams struct {
+		Store Store `validate:"required"`
+		// TTL is how long a key is replayed, optional, 24h by default.
+		// ProcessingTimeout is when a key left processing, e.g.: the replica
+		// died, can be reused, optional, 1m by default.
+		TTL               time.Duration `validate:"gte=0"`
+		ProcessingTimeout time.Duration `validate:"gte=0"`
+		// Scope namespaces the keys by the request context, e.g.: by
+		// tenant, optional, the keys are shared by default.
+		Scope func(ctx context.Context) string
+	}
+)
+
+var (
+	ErrKeyReused     = cerrors.New("the idempotency key was used by another request", cerrors.CodeIdempotencyKeyReused)
+	ErrKeyInProgress = cerrors.New("a request with the idempotency key is in progress", cerrors.CodeIdempotencyKeyInProgress)
+	ErrInvalidKey    = cerrors.New("the idempotency key is invalid", cerrors.CodeInvalidIdempotencyKey)
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+
+	defaultTTL               = 24 * time.Hour
+	defaultProcessingTimeout = time.Minute
+	maxKeyLength             = 255
+)
+
+var (
+	// replayedHeaders are the response headers saved to be replayed.
+	replayedHeaders = []string{
+		fiber.HeaderContentType,
+		fiber.HeaderETag,
+		fiber.HeaderLocation,
+	}
+)
+
+// Middleware makes the route idempotent for the requests with the
+// Idempotency-Key header: the first response is saved and replayed to the
+// requests repeating the key, a key reused with another method, path or body
+// fails with 409. The server errors are not saved, so the request can be
+// retried with the same key.
+func Middleware(params *MiddlewareParams) fiber.Handler {
+	params.validate()
+
+	if params.TTL == 0 {
+		params.TTL = defaultTTL
+	}
+
+	if params.ProcessingTimeout == 0 {
+		params.ProcessingTimeout = defaultProcessingTimeout
+	}
+
+	if params.Scope == nil {
+		params.Scope = sharedScope
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return ctx.Next()
+		}
+
+		if len(key) > maxKeyLength {
+			return ErrInvalidKey
+		}
+
+		reqCtx := ctx.UserContext()
+		now := time.Now()
+
+		record := &Record{
+			Scope:       params.Scope(reqCtx),
+			Key:         key,
+			RequestHash: requestHash(ctx),
+			Status:      StatusProcessing,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(params.TTL),
+		}
+
+		reserved, err := reserve(ctx, params, record)
+		if err != nil || !reserved {
+			return err
+		}
+
+		// The returned errors are written by the app error handler first, so
+		// the client errors are saved like any other response.
+		err = ctx.Next()
+		if err != nil {
+			err = ctx.App().ErrorHandler(ctx, err)
+		}
+
+		status := ctx.Response().StatusCode()
+
+		if err != nil || status >= fiber.StatusInternalServerError {
+			deleteErr := params.Store.Delete(reqCtx, record.Scope, key)
+			if deleteErr != nil {
+				logger.FromContext(reqCtx).ErrorContext(reqCtx, "error releasing the idempotency key", logger.Error(deleteErr))
+			}
+
+			return err
+		}
+
+		record.Status = StatusCompleted
+		record.ResponseStatus = status
+		record.ResponseBody = append([]byte(nil), ctx.Response().Body()...)
+		record.ResponseHeaders = map[string]string{}
+
+		for _, header := range replayedHeaders {
+			if value := ctx.GetRespHeader(header); value != "" {
+				record.ResponseHeaders[header] = value
+			}
+		}
+
+		err = params.Store.Complete(reqCtx, record)
+		if err != nil {
+			logger.FromContext(reqCtx).ErrorContext(reqCtx, "error saving the idempotent response", logger.Error(err))
+		}
+
+		return nil
+	}
+}
+
+func sharedScope(ctx context.Context) string {
+	return ""
+}
+
+func (p *MiddlewareParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// reserve saves the processing record, it is not reserved when the key was
+// already used and the response was written.
+func reserve(ctx *fiber.Ctx, params *MiddlewareParams, record *Record) (bool, error) {
+	reqCtx := ctx.UserContext()
+
+	err := params.Store.Reserve(reqCtx, record)
+	if err == nil {
+		return true, nil
+	}
+
+	if !errors.Is(err, ErrKeyExists) {
+		return false, err
+	}
+
+	saved, err := params.Store.Get(reqCtx, record.Scope, record.Key)
+	if err != nil {
+		return false, err
+	}
+
+	// the key was released meanwhile
+	if saved == nil {
+		err = params.Store.Reserve(reqCtx, record)
+		if errors.Is(err, ErrKeyExists) {
+			return false, ErrKeyInProgress
+		}
+
+		return err == nil, err
+	}
+
+	// the key expired or its request was abandoned, only one of the
+	// concurrent retries reclaims it
+	if isAbandoned(saved, params.ProcessingTimeout) {
+		now := time.Now()
+
+		reclaimed, err := params.Store.Reclaim(reqCtx, record, now, now.Add(-params.ProcessingTimeout))
+		if err != nil {
+			return false, err
+		}
+
+		if !reclaimed {
+			return false, ErrKeyInProgress
+		}
+
+		return true, nil
+	}
+
+	if saved.RequestHash != record.RequestHash {
+		return false, ErrKeyReused
+	}
+
+	if saved.Status == StatusProcessing {
+		return false, ErrKeyInProgress
+	}
+
+	for header, value := range saved.ResponseHeaders {
+		ctx.Set(header, value)
+	}
+
+	ctx.Set(HeaderReplayed, "true")
+
+	return false, ctx.Status(saved.ResponseStatus).Send(saved.ResponseBody)
+}
+
+// isAbandoned is true for the expired records not deleted yet and the ones
+// processing for longer than the timeout.
+func isAbandoned(record *Record, processingTimeout time.Duration) bool {
+	if !record.ExpiresAt.After(time.Now()) {
+		return true
+	}
+
+	return record.Status == StatusProcessing && time.Since(record.CreatedAt) > processingTimeout
+}
+
+// requestHash identifies the request by method, path and body.
+func requestHash(ctx *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.Path()))
+	hash.Write([]byte{0})
+	hash.Write(ctx.Body())
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,72 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type (
+	// Record is a request made with an Idempotency-Key, the response is set
+	// once the request is completed. Scope namespaces the key, e.g.: by
+	// tenant.
+	Record struct {
+		Scope           string
+		Key             string
+		RequestHash     string
+		Status          Status
+		ResponseStatus  int
+		ResponseHeaders map[string]string
+		ResponseBody    []byte
+		CreatedAt       time.Time
+		ExpiresAt       time.Time
+	}
+
+	// Store saves the records. Reserve fails with ErrKeyExists when the key
+	// is already saved. Reclaim replaces the saved record of the key with
+	// record atomically, only when it expired at now or is processing since
+	// before abandonedBefore, reporting if it was replaced.
+	Store interface {
+		Reserve(ctx context.Context, record *Record) error
+		Reclaim(ctx context.Context, record *Record, now time.Time, abandonedBefore time.Time) (bool, error)
+		Get(ctx context.Context, scope string, key string) (*Record, error)
+		Complete(ctx context.Context, record *Record) error
+		Delete(ctx context.Context, scope string, key string) error
+		DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error)
+	}
+
+	Status string
+)
+
+var (
+	StatusProcessing Status = "PROCESSING"
+	StatusCompleted  Status = "COMPLETED"
+
+	ErrKeyExists = errors.New("idempotency key already exists")
+)
+
+const (
+	purgeBatch = 1000
+)
+
+// PurgeExpired deletes the expired records in batches, returning how many
+// were deleted.
+func PurgeExpired(ctx context.Context, store Store) (int, error) {
+	now := time.Now()
+	purged := 0
+
+	for ctx.Err() == nil {
+		deleted, err := store.DeleteExpired(ctx, now, purgeBatch)
+		if err != nil {
+			return purged, err
+		}
+
+		purged += deleted
+
+		if deleted < purgeBatch {
+			break
+		}
+	}
+
+	return purged, ctx.Err()
+}
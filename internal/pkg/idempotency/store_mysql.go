@@ -0,0 +1,195 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/uptrace/bun"
+)
+
+type (
+	StoreParams struct {
+		DB *bun.DB `validate:"required"`
+	}
+
+	mysqlStore struct {
+		db *bun.DB
+	}
+
+	recordModel struct {
+		bun.BaseModel   `bun:"table:idempotency_key,alias:idempotency_key"`
+		Scope           string            `bun:"scope,pk"`
+		Key             string            `bun:"key,pk"`
+		RequestHash     string            `bun:"request_hash"`
+		Status          Status            `bun:"status"`
+		ResponseStatus  *int              `bun:"response_status"`
+		ResponseHeaders map[string]string `bun:"response_headers,type:json"`
+		ResponseBody    []byte            `bun:"response_body"`
+		CreatedAt       time.Time         `bun:"created_at"`
+		ExpiresAt       time.Time         `bun:"expires_at"`
+	}
+)
+
+const (
+	mysqlDuplicateEntry = 1062
+)
+
+func NewMysqlStore(params *StoreParams) Store {
+	params.validate()
+
+	return &mysqlStore{
+		db: params.DB,
+	}
+}
+
+func (p *StoreParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Reserve relies on the scope and key primary key to detect a key already
+// used.
+func (s *mysqlStore) Reserve(ctx context.Context, record *Record) error {
+	_, err := s.db.NewInsert().Model(toRecordModel(record)).Exec(ctx)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return ErrKeyExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Reclaim is a single conditional update, so only one of the concurrent
+// retries of an abandoned key takes it over.
+func (s *mysqlStore) Reclaim(ctx context.Context, record *Record, now time.Time, abandonedBefore time.Time) (bool, error) {
+	result, err := s.db.NewUpdate().
+		Model(toRecordModel(record)).
+		Column("request_hash", "status", "response_status", "response_headers", "response_body", "created_at", "expires_at").
+		WherePK().
+		Where("(idempotency_key.expires_at <= ? OR (idempotency_key.status = ? AND idempotency_key.created_at < ?))",
+			now, StatusProcessing, abandonedBefore).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	reclaimed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return reclaimed == 1, nil
+}
+
+func (s *mysqlStore) Get(ctx context.Context, scope string, key string) (*Record, error) {
+	model := &recordModel{}
+
+	err := s.db.NewSelect().
+		Model(model).
+		Where("idempotency_key.scope = ?", scope).
+		Where("idempotency_key.key = ?", key).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return model.ToRecord(), nil
+}
+
+func (s *mysqlStore) Complete(ctx context.Context, record *Record) error {
+	_, err := s.db.NewUpdate().
+		Model(toRecordModel(record)).
+		Column("status", "response_status", "response_headers", "response_body").
+		WherePK().
+		Exec(ctx)
+
+	return err
+}
+
+func (s *mysqlStore) Delete(ctx context.Context, scope string, key string) error {
+	_, err := s.db.NewDelete().
+		Model((*recordModel)(nil)).
+		Where("scope = ?", scope).
+		Where("`key` = ?", key).
+		Exec(ctx)
+
+	return err
+}
+
+// DeleteExpired deletes up to limit records expired at now, returning how
+// many were deleted.
+func (s *mysqlStore) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	result, err := s.db.NewDelete().
+		Model((*recordModel)(nil)).
+		Where("expires_at <= ?", now).
+		OrderExpr("expires_at ASC").
+		Limit(limit).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
+func toRecordModel(record *Record) *recordModel {
+	model := &recordModel{
+		Scope:           record.Scope,
+		Key:             record.Key,
+		RequestHash:     record.RequestHash,
+		Status:          record.Status,
+		ResponseHeaders: record.ResponseHeaders,
+		ResponseBody:    record.ResponseBody,
+		CreatedAt:       record.CreatedAt,
+		ExpiresAt:       record.ExpiresAt,
+	}
+
+	if record.ResponseStatus != 0 {
+		model.ResponseStatus = &record.ResponseStatus
+	}
+
+	return model
+}
+
+func (m *recordModel) ToRecord() *Record {
+	if m == nil {
+		return nil
+	}
+
+	record := &Record{
+		Scope:           m.Scope,
+		Key:             m.Key,
+		RequestHash:     m.RequestHash,
+		Status:          m.Status,
+		ResponseHeaders: m.ResponseHeaders,
+		ResponseBody:    m.ResponseBody,
+		CreatedAt:       m.CreatedAt,
+		ExpiresAt:       m.ExpiresAt,
+	}
+
+	if m.ResponseStatus != nil {
+		record.ResponseStatus = *m.ResponseStatus
+	}
+
+	return record
+}
@@ -0,0 +1,97 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/validator"
+	"syscall"
+	"time"
+)
+
+type (
+	Manager interface {
+		// Register adds a stop hook, hooks run in registration order.
+		Register(name string, stop StopFunc)
+		// Wait blocks until a shutdown signal or ctx is done, then stops.
+		Wait(ctx context.Context) error
+		Stop(ctx context.Context) error
+	}
+
+	StopFunc func(ctx context.Context) error
+
+	Params struct {
+		Timeout time.Duration `validate:"required"`
+	}
+
+	hook struct {
+		name string
+		stop StopFunc
+	}
+
+	manager struct {
+		timeout time.Duration
+		hooks   []hook
+	}
+)
+
+func NewManager(params *Params) Manager {
+	params.validate()
+
+	return &manager{
+		timeout: params.Timeout,
+	}
+}
+
+func (p *Params) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (m *manager) Register(name string, stop StopFunc) {
+	m.hooks = append(m.hooks, hook{
+		name: name,
+		stop: stop,
+	})
+}
+
+func (m *manager) Wait(ctx context.Context) error {
+	ctx, stopNotify := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stopNotify()
+
+	return m.Stop(context.WithoutCancel(ctx))
+}
+
+// Stop runs every hook sharing the same deadline. A failing hook does not
+// prevent the next ones from running, so resources are always released.
+func (m *manager) Stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	slog.InfoContext(ctx, "Shutting down...", "timeout", m.timeout.String())
+
+	errs := []error{}
+	for _, h := range m.hooks {
+		start := time.Now()
+
+		err := h.stop(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "shutdown step failed", "step", h.name, logger.Error(err))
+			errs = append(errs, err)
+
+			continue
+		}
+
+		slog.InfoContext(ctx, "shutdown step completed", "step", h.name, "duration_ms", time.Since(start).Milliseconds())
+	}
+
+	slog.InfoContext(ctx, "Shutdown complete.")
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"pack-management/internal/pkg/validator"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	Params struct {
+		Format string `validate:"omitempty,oneof=json cli"`
+		Level  string `validate:"omitempty,oneof=debug info warn error"`
+		Output io.Writer
+	}
+
+	contextKey struct{}
+
+	traceHandler struct {
+		slog.Handler
+	}
+)
+
+const (
+	FormatJSON = "json"
+	FormatCLI  = "cli"
+
+	RequestIDKey = "request_id"
+	PackIDKey    = "pack_id"
+	TenantIDKey  = "tenant_id"
+	SubjectKey   = "subject"
+	ErrorKey     = "error"
+)
+
+// NewLogger builds the application logger and registers it as the slog
+// default, so code without a request context logs with the same format.
+func NewLogger(params *Params) *slog.Logger {
+	params.validate()
+
+	if params.Output == nil {
+		params.Output = os.Stdout
+	}
+
+	opts := &slog.HandlerOptions{
+		Level: parseLevel(params.Level),
+	}
+
+	var handler slog.Handler
+	if params.Format == FormatCLI {
+		handler = slog.NewTextHandler(params.Output, opts)
+	} else {
+		handler = slog.NewJSONHandler(params.Output, opts)
+	}
+
+	logger := slog.New(&traceHandler{Handler: handler})
+	slog.SetDefault(logger)
+
+	return logger
+}
+
+func (p *Params) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// WithContext stores the logger in the context.
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in the context or the default one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+
+	return slog.Default()
+}
+
+// With returns a context whose logger includes the given attributes in
+// every line, e.g.: logger.With(ctx, logger.PackIDKey, pack.ID).
+func With(ctx context.Context, args ...any) context.Context {
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
+
+func Error(err error) slog.Attr {
+	return slog.Any(ErrorKey, err)
+}
+
+func (h *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func parseLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"pack-management/internal/pkg/uuid"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	HeaderRequestID = fiber.HeaderXRequestID
+)
+
+// Middleware reuses the caller X-Request-ID or generates one, echoes it in the
+// response and stores a logger with the request ID in the fiber user context.
+func Middleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+
+		requestID := ctx.Get(HeaderRequestID)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+
+		ctx.Set(HeaderRequestID, requestID)
+		trace.SpanFromContext(ctx.UserContext()).SetAttributes(attribute.String("request.id", requestID))
+
+		reqCtx := With(ctx.UserContext(), RequestIDKey, requestID)
+		ctx.SetUserContext(reqCtx)
+
+		// The returned errors are written by the app error handler here, so
+		// the logged status is the one sent to the client.
+		err := ctx.Next()
+		if err != nil {
+			err = ctx.App().ErrorHandler(ctx, err)
+		}
+
+		FromContext(reqCtx).InfoContext(
+			reqCtx,
+			"request completed",
+			"method", ctx.Method(),
+			"path", ctx.Path(),
+			"status", ctx.Response().StatusCode(),
+			"latency_ms", time.Since(start).Milliseconds(),
+		)
+
+		return err
+	}
+}
@@ -1,155 +1,372 @@
 package pagination
 
 import (
-	"encoding/base64"
 	"errors"
+	"pack-management/internal/pkg/cerrors"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/uptrace/bun"
 )
 
 type (
+	// Metadata of a page, Total is only set when the listing is asked to
+	// count the matching items.
 	Metadata struct {
 		PageSize   int    `json:"page_size"`
+		Total      *int   `json:"total,omitempty"`
 		NextCursor string `json:"next_cursor,omitempty"`
 		PrevCursor string `json:"prev_cursor,omitempty"`
 	}
 
+	// SortField is a keyset column. Column is the SQL column, prefixed with
+	// CursorConfig.TableAlias when it has no alias, and Field is the model
+	// struct field that holds its value.
+	SortField struct {
+		Column    string
+		Field     string
+		Direction string
+	}
+
+	// CursorConfig describes the keyset. The last sort field must be unique
+	// (e.g.: the id) to break the ties of the previous ones. Filters is the
+	// filter set of the listing, a cursor is only accepted with the same one.
 	CursorConfig struct {
-		PageSize        int
-		PageCursor      *string
-		CursorDirection string
-		OrderStrategy   string
-		CursorField     string
+		PageSize   int
+		PageCursor *string
+		TableAlias string
+		SortFields []SortField
+		Filters    any
+		Signer     *Signer
+	}
+
+	// Cursor is the decoded page cursor, clients receive it as an opaque
+	// signed string.
+	Cursor struct {
+		Version   int           `json:"v"`
+		Backward  bool          `json:"b,omitempty"`
+		Sort      string        `json:"s"`
+		Filters   string        `json:"f,omitempty"`
+		ExpiresAt int64         `json:"e"`
+		Values    []cursorValue `json:"k"`
+	}
+
+	// cursorValue is a sort value, typeNull for a NULL column.
+	cursorValue struct {
+		Type  string `json:"t"`
+		Value string `json:"v,omitempty"`
 	}
 )
 
 var (
-	ErrInvalidCursor      = errors.New("invalid cursor")
-	ErrInvalidCursorField = errors.New("cursor field is not a string")
+	ErrInvalidCursor      = cerrors.New("the informed page cursor is invalid or expired", cerrors.CodeInvalidCursor)
+	ErrInvalidCursorField = errors.New("cursor field type is not supported")
+	ErrMissingSortFields  = errors.New("at least one sort field is required")
+	ErrMissingSigner      = errors.New("cursor signer is required")
 )
 
 const (
 	AscDirection  = "ASC"
 	DescDirection = "DESC"
+
+	cursorVersion = 1
+
+	typeString = "s"
+	typeInt    = "i"
+	typeUint   = "u"
+	typeFloat  = "f"
+	typeBool   = "b"
+	typeTime   = "t"
+	typeNull   = "n"
 )
 
-func EncodeCursor(order string, cursor string) string {
-	return base64.StdEncoding.EncodeToString([]byte(order + ":" + cursor))
+func InvertDirection(direction string) string {
+	if direction == AscDirection {
+		return DescDirection
+	}
+
+	return AscDirection
 }
 
-func DecodeCursor(encodedCursor string) (string, string, error) {
-	decodedCursor, err := base64.StdEncoding.DecodeString(encodedCursor)
+// BuildCursorQuery applies the keyset WHERE and ORDER BY clauses of the
+// config to the query and returns the decoded cursor, nil on the first page.
+// The caller must limit the query to PageSize + 1 rows.
+func BuildCursorQuery(config CursorConfig, query *bun.SelectQuery) (*bun.SelectQuery, *Cursor, error) {
+	if len(config.SortFields) == 0 {
+		return nil, nil, ErrMissingSortFields
+	}
+
+	if config.Signer == nil {
+		return nil, nil, ErrMissingSigner
+	}
+
+	cursor, err := getCursor(config)
 	if err != nil {
-		return "", "", err
+		return nil, nil, err
 	}
 
-	cursor := string(decodedCursor)
-	cursorParts := splitCursor(cursor)
+	backward := cursor != nil && cursor.Backward
+
+	if cursor != nil {
+		values, err := cursor.values()
+		if err != nil {
+			return nil, nil, err
+		}
 
-	if len(cursorParts) < 2 {
-		return "", "", ErrInvalidCursor
+		where, args := keysetCondition(config, values, backward)
+		query.Where(where, args...)
 	}
 
-	return cursorParts[0], cursorParts[1], nil
-}
+	for _, field := range config.SortFields {
+		direction := field.Direction
+		if backward {
+			direction = InvertDirection(direction)
+		}
 
-func InvertDirection(direction string) string {
-	if direction == AscDirection {
-		return DescDirection
+		query.OrderExpr("? "+direction, bun.Ident(config.column(field)))
 	}
 
-	return AscDirection
+	return query, cursor, nil
 }
 
-func BuildMetadata[T any](config CursorConfig, items []T) ([]T, *Metadata, error) {
+// BuildMetadata removes the extra row fetched to detect more pages, restores
+// the sort order of backward pages and builds the next and previous cursors.
+func BuildMetadata[T any](config CursorConfig, cursor *Cursor, items []T) ([]T, *Metadata, error) {
+	backward := cursor != nil && cursor.Backward
+
 	hasMoreItems := len(items) > config.PageSize
 	if hasMoreItems {
 		items = items[:len(items)-1]
 	}
 
-	if config.CursorDirection != config.OrderStrategy {
+	if backward {
 		slices.Reverse(items)
 	}
 
-	var nextCursor string
-	if hasMoreItems || config.CursorDirection != config.OrderStrategy {
-		lastItem, err := getItemValue(items[len(items)-1], config.CursorField)
+	metadata := &Metadata{
+		PageSize: config.PageSize,
+	}
+
+	if len(items) == 0 {
+		return items, metadata, nil
+	}
+
+	if hasMoreItems || backward {
+		nextCursor, err := newCursor(config, items[len(items)-1], false)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		nextCursor = EncodeCursor(config.OrderStrategy, lastItem)
+		metadata.NextCursor = config.Signer.Encode(nextCursor)
 	}
 
-	var prevCursor string
-	if len(items) > 0 && config.PageCursor != nil && (hasMoreItems || config.CursorDirection == config.OrderStrategy) {
-		firstItem, err := getItemValue(items[0], config.CursorField)
+	if cursor != nil && (hasMoreItems || !backward) {
+		prevCursor, err := newCursor(config, items[0], true)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		prevCursor = EncodeCursor(InvertDirection(config.OrderStrategy), firstItem)
+		metadata.PrevCursor = config.Signer.Encode(prevCursor)
 	}
 
-	return items, &Metadata{
-		PageSize:   config.PageSize,
-		NextCursor: nextCursor,
-		PrevCursor: prevCursor,
-	}, nil
+	return items, metadata, nil
 }
 
-func BuildCursorQuery(config CursorConfig, query *bun.SelectQuery) (*bun.SelectQuery, string, error) {
-	cursorDirection, cursorValue, err := getCursorValues(config)
-	if err != nil {
-		return nil, "", err
-	}
+// keysetCondition builds "(a > ?) OR (a = ? AND b > ?) ..." honoring the
+// direction of each sort field. A nil value is a NULL column, sorted first in
+// ascending order and last in descending order as MySQL does.
+func keysetCondition(config CursorConfig, values []any, backward bool) (string, []any) {
+	conditions := make([]string, 0, len(config.SortFields))
+	args := []any{}
+
+	for i, field := range config.SortFields {
+		parts := make([]string, 0, i+1)
+
+		for j := range i {
+			column := bun.Ident(config.column(config.SortFields[j]))
+
+			if values[j] == nil {
+				parts = append(parts, "? IS NULL")
+				args = append(args, column)
+			} else {
+				parts = append(parts, "? = ?")
+				args = append(args, column, values[j])
+			}
+		}
+
+		direction := field.Direction
+		if backward {
+			direction = InvertDirection(direction)
+		}
 
-	if cursorValue != "" {
-		if cursorDirection == DescDirection {
-			query.Where("pack.id < ?", cursorValue)
-		} else {
-			query.Where("pack.id > ?", cursorValue)
+		column := bun.Ident(config.column(field))
+
+		switch {
+		case direction == AscDirection && values[i] == nil:
+			parts = append(parts, "? IS NOT NULL")
+			args = append(args, column)
+		case direction == AscDirection:
+			parts = append(parts, "? > ?")
+			args = append(args, column, values[i])
+		case values[i] == nil:
+			// nothing sorts after the NULLs in descending order
+			parts = append(parts, "1 = 0")
+		default:
+			parts = append(parts, "(? < ? OR ? IS NULL)")
+			args = append(args, column, values[i], column)
 		}
+
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
 	}
 
-	query.Order("pack.id " + cursorDirection)
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
 
-	return query, cursorDirection, nil
+func (c CursorConfig) column(field SortField) string {
+	if c.TableAlias == "" || strings.Contains(field.Column, ".") {
+		return field.Column
+	}
+
+	return c.TableAlias + "." + field.Column
 }
 
-func getItemValue[T any](item T, cursorField string) (string, error) {
-	reflectedValue := reflect.ValueOf(item)
+// signature identifies the sort of the config, so a cursor issued for
+// another sort is rejected.
+func (c CursorConfig) signature() string {
+	parts := make([]string, 0, len(c.SortFields))
+	for _, field := range c.SortFields {
+		parts = append(parts, c.column(field)+":"+field.Direction)
+	}
 
-	if reflectedValue.Kind() == reflect.Ptr {
-		reflectedValue = reflectedValue.Elem()
+	return strings.Join(parts, ",")
+}
+
+func getCursor(config CursorConfig) (*Cursor, error) {
+	if config.PageCursor == nil || *config.PageCursor == "" {
+		return nil, nil
 	}
 
-	cursorFieldInterface := reflectedValue.FieldByName(cursorField).Interface()
+	cursor, err := config.Signer.Decode(*config.PageCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor.Sort != config.signature() || len(cursor.Values) != len(config.SortFields) {
+		return nil, ErrInvalidCursor
+	}
+
+	filters, err := filtersHash(config.Filters)
+	if err != nil {
+		return nil, err
+	}
 
-	value, ok := cursorFieldInterface.(string)
-	if ok {
-		return value, nil
+	if cursor.Filters != filters {
+		return nil, ErrInvalidCursor
 	}
 
-	return "", ErrInvalidCursorField
+	return cursor, nil
 }
 
-func getCursorValues(config CursorConfig) (string, string, error) {
-	if config.PageCursor == nil {
-		return config.OrderStrategy, "", nil
+func newCursor[T any](config CursorConfig, item T, backward bool) (*Cursor, error) {
+	values := make([]cursorValue, 0, len(config.SortFields))
+
+	for _, field := range config.SortFields {
+		value, err := getItemValue(item, field.Field)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
 	}
 
-	cursorDirection, cursorValue, err := DecodeCursor(*config.PageCursor)
+	filters, err := filtersHash(config.Filters)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	return cursorDirection, cursorValue, nil
+	return &Cursor{
+		Backward: backward,
+		Sort:     config.signature(),
+		Filters:  filters,
+		Values:   values,
+	}, nil
 }
 
-func splitCursor(cursor string) []string {
-	return strings.Split(cursor, ":")
+func (c *Cursor) values() ([]any, error) {
+	values := make([]any, 0, len(c.Values))
+
+	for _, value := range c.Values {
+		parsed, err := value.parse()
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		values = append(values, parsed)
+	}
+
+	return values, nil
+}
+
+func (v cursorValue) parse() (any, error) {
+	switch v.Type {
+	case typeNull:
+		return nil, nil
+	case typeString:
+		return v.Value, nil
+	case typeTime:
+		return time.Parse(time.RFC3339Nano, v.Value)
+	case typeInt:
+		return strconv.ParseInt(v.Value, 10, 64)
+	case typeUint:
+		return strconv.ParseUint(v.Value, 10, 64)
+	case typeFloat:
+		return strconv.ParseFloat(v.Value, 64)
+	case typeBool:
+		return strconv.ParseBool(v.Value)
+	default:
+		return nil, ErrInvalidCursor
+	}
+}
+
+func getItemValue[T any](item T, cursorField string) (cursorValue, error) {
+	reflectedValue := reflect.ValueOf(item)
+
+	if reflectedValue.Kind() == reflect.Ptr {
+		reflectedValue = reflectedValue.Elem()
+	}
+
+	fieldValue := reflectedValue.FieldByName(cursorField)
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return cursorValue{Type: typeNull}, nil
+		}
+
+		fieldValue = fieldValue.Elem()
+	}
+
+	if !fieldValue.IsValid() {
+		return cursorValue{}, ErrInvalidCursorField
+	}
+
+	if value, ok := fieldValue.Interface().(time.Time); ok {
+		return cursorValue{Type: typeTime, Value: value.UTC().Format(time.RFC3339Nano)}, nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.String:
+		return cursorValue{Type: typeString, Value: fieldValue.String()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: typeInt, Value: strconv.FormatInt(fieldValue.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: typeUint, Value: strconv.FormatUint(fieldValue.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return cursorValue{Type: typeFloat, Value: strconv.FormatFloat(fieldValue.Float(), 'g', -1, 64)}, nil
+	case reflect.Bool:
+		return cursorValue{Type: typeBool, Value: strconv.FormatBool(fieldValue.Bool())}, nil
+	default:
+		return cursorValue{}, ErrInvalidCursorField
+	}
 }
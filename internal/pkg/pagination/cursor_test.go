@@ -0,0 +1,269 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/schema"
+)
+
+type (
+	testItem struct {
+		ID        string
+		CreatedAt time.Time
+		DueAt     *time.Time
+	}
+
+	testFilters struct {
+		Status string `json:"status"`
+	}
+)
+
+const (
+	testSecret = "0123456789abcdef0123456789abcdef"
+)
+
+func testConfig(pageCursor *string) CursorConfig {
+	return CursorConfig{
+		PageSize:   2,
+		PageCursor: pageCursor,
+		TableAlias: "item",
+		SortFields: []SortField{
+			{Column: "due_at", Field: "DueAt", Direction: AscDirection},
+			{Column: "id", Field: "ID", Direction: DescDirection},
+		},
+		Filters: testFilters{Status: "CREATED"},
+		Signer:  NewSigner(&SignerParams{Secret: testSecret, TTL: time.Hour}),
+	}
+}
+
+func formatCondition(config CursorConfig, values []any, backward bool) string {
+	where, args := keysetCondition(config, values, backward)
+	return schema.NewFormatter(mysqldialect.New()).FormatQuery(where, args...)
+}
+
+func TestKeysetCondition(t *testing.T) {
+	dueAt := time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Shoud build the forward condition", func(t *testing.T) {
+		assert.Equal(t,
+			"((`item`.`due_at` > '2025-04-02 00:00:00') OR "+
+				"(`item`.`due_at` = '2025-04-02 00:00:00' AND (`item`.`id` < 'pack_2' OR `item`.`id` IS NULL)))",
+			formatCondition(testConfig(nil), []any{dueAt, "pack_2"}, false),
+		)
+	})
+
+	t.Run("Shoud invert the directions of the backward condition", func(t *testing.T) {
+		assert.Equal(t,
+			"(((`item`.`due_at` < '2025-04-02 00:00:00' OR `item`.`due_at` IS NULL)) OR "+
+				"(`item`.`due_at` = '2025-04-02 00:00:00' AND `item`.`id` > 'pack_2'))",
+			formatCondition(testConfig(nil), []any{dueAt, "pack_2"}, true),
+		)
+	})
+
+	t.Run("Shoud compare the NULL values", func(t *testing.T) {
+		assert.Equal(t,
+			"((`item`.`due_at` IS NOT NULL) OR "+
+				"(`item`.`due_at` IS NULL AND (`item`.`id` < 'pack_2' OR `item`.`id` IS NULL)))",
+			formatCondition(testConfig(nil), []any{nil, "pack_2"}, false),
+		)
+
+		assert.Equal(t,
+			"((1 = 0) OR (`item`.`due_at` IS NULL AND `item`.`id` > 'pack_2'))",
+			formatCondition(testConfig(nil), []any{nil, "pack_2"}, true),
+		)
+	})
+
+	t.Run("Shoud keep the column alias", func(t *testing.T) {
+		config := testConfig(nil)
+		config.SortFields = []SortField{{Column: "person.name", Field: "ID", Direction: AscDirection}}
+
+		assert.Equal(t, "((`person`.`name` > 'Maria'))", formatCondition(config, []any{"Maria"}, false))
+	})
+}
+
+func TestBuildMetadata(t *testing.T) {
+	dueAt := time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)
+	items := []*testItem{
+		{ID: "pack_4", DueAt: nil},
+		{ID: "pack_3", DueAt: &dueAt},
+		{ID: "pack_2", DueAt: &dueAt},
+	}
+
+	t.Run("Shoud return only the next cursor on the first page", func(t *testing.T) {
+		config := testConfig(nil)
+
+		page, metadata, err := BuildMetadata(config, nil, items)
+		assert.Nil(t, err)
+		assert.Equal(t, items[:2], page)
+		assert.Equal(t, 2, metadata.PageSize)
+		assert.NotEmpty(t, metadata.NextCursor)
+		assert.Empty(t, metadata.PrevCursor)
+
+		cursor, err := getCursor(testConfig(&metadata.NextCursor))
+		assert.Nil(t, err)
+		assert.False(t, cursor.Backward)
+
+		values, err := cursor.values()
+		assert.Nil(t, err)
+		assert.Equal(t, []any{dueAt, "pack_3"}, values)
+	})
+
+	t.Run("Shoud return both cursors on a middle page", func(t *testing.T) {
+		cursor := &Cursor{}
+
+		page, metadata, err := BuildMetadata(testConfig(nil), cursor, items)
+		assert.Nil(t, err)
+		assert.Len(t, page, 2)
+		assert.NotEmpty(t, metadata.NextCursor)
+		assert.NotEmpty(t, metadata.PrevCursor)
+
+		prevCursor, err := getCursor(testConfig(&metadata.PrevCursor))
+		assert.Nil(t, err)
+		assert.True(t, prevCursor.Backward)
+
+		values, err := prevCursor.values()
+		assert.Nil(t, err)
+		assert.Equal(t, []any{nil, "pack_4"}, values)
+	})
+
+	t.Run("Shoud restore the order of a backward page", func(t *testing.T) {
+		cursor := &Cursor{Backward: true}
+		// a backward page is fetched in the inverted order
+		backwardItems := []*testItem{items[2], items[1], items[0]}
+
+		page, metadata, err := BuildMetadata(testConfig(nil), cursor, backwardItems)
+		assert.Nil(t, err)
+		assert.Equal(t, []*testItem{items[1], items[2]}, page)
+		assert.NotEmpty(t, metadata.NextCursor)
+		assert.NotEmpty(t, metadata.PrevCursor)
+	})
+
+	t.Run("Shoud return no previous cursor on the first page reached backward", func(t *testing.T) {
+		cursor := &Cursor{Backward: true}
+
+		page, metadata, err := BuildMetadata(testConfig(nil), cursor, []*testItem{items[1], items[0]})
+		assert.Nil(t, err)
+		assert.Equal(t, []*testItem{items[0], items[1]}, page)
+		assert.NotEmpty(t, metadata.NextCursor)
+		assert.Empty(t, metadata.PrevCursor)
+	})
+
+	t.Run("Shoud return no cursor on an empty page", func(t *testing.T) {
+		page, metadata, err := BuildMetadata(testConfig(nil), &Cursor{}, []*testItem{})
+		assert.Nil(t, err)
+		assert.Empty(t, page)
+		assert.Empty(t, metadata.NextCursor)
+		assert.Empty(t, metadata.PrevCursor)
+	})
+}
+
+func TestGetCursor(t *testing.T) {
+	dueAt := time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)
+	item := &testItem{ID: "pack_1", DueAt: &dueAt}
+
+	encode := func(t *testing.T, config CursorConfig) string {
+		cursor, err := newCursor(config, item, false)
+		assert.Nil(t, err)
+
+		return config.Signer.Encode(cursor)
+	}
+
+	t.Run("Shoud return no cursor on the first page", func(t *testing.T) {
+		cursor, err := getCursor(testConfig(nil))
+		assert.Nil(t, err)
+		assert.Nil(t, cursor)
+	})
+
+	t.Run("Shoud reject a cursor of another sort", func(t *testing.T) {
+		encoded := encode(t, testConfig(nil))
+
+		config := testConfig(&encoded)
+		config.SortFields[1].Direction = AscDirection
+
+		_, err := getCursor(config)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Shoud reject a cursor of other filters", func(t *testing.T) {
+		encoded := encode(t, testConfig(nil))
+
+		config := testConfig(&encoded)
+		config.Filters = testFilters{Status: "DELIVERED"}
+
+		_, err := getCursor(config)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Shoud reject a cursor with an unknown value type", func(t *testing.T) {
+		config := testConfig(nil)
+
+		cursor, err := newCursor(config, item, false)
+		assert.Nil(t, err)
+
+		cursor.Values[0].Type = "x"
+		encoded := config.Signer.Encode(cursor)
+
+		decoded, err := getCursor(testConfig(&encoded))
+		assert.Nil(t, err)
+
+		_, err = decoded.values()
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+// flip changes a base64 char, so the decoded payload differs.
+func flip(c byte) string {
+	if c == 'A' {
+		return "B"
+	}
+
+	return "A"
+}
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner(&SignerParams{Secret: testSecret, TTL: time.Hour})
+	cursor := &Cursor{
+		Sort:   "item.id:DESC",
+		Values: []cursorValue{{Type: typeString, Value: "pack_1"}, {Type: typeNull}},
+	}
+
+	t.Run("Shoud decode the encoded cursor", func(t *testing.T) {
+		decoded, err := signer.Decode(signer.Encode(cursor))
+		assert.Nil(t, err)
+		assert.Equal(t, cursor.Sort, decoded.Sort)
+		assert.Equal(t, cursor.Values, decoded.Values)
+		assert.Equal(t, cursorVersion, decoded.Version)
+	})
+
+	t.Run("Shoud reject a tampered cursor", func(t *testing.T) {
+		encoded := signer.Encode(cursor)
+
+		for _, tampered := range []string{
+			"x" + encoded[1:],
+			encoded[:10] + flip(encoded[10]) + encoded[11:],
+			encoded + "x",
+			"no-separator",
+			"",
+		} {
+			_, err := signer.Decode(tampered)
+			assert.ErrorIs(t, err, ErrInvalidCursor, tampered)
+		}
+	})
+
+	t.Run("Shoud reject a cursor signed with another secret", func(t *testing.T) {
+		other := NewSigner(&SignerParams{Secret: "fedcba9876543210fedcba9876543210", TTL: time.Hour})
+
+		_, err := signer.Decode(other.Encode(cursor))
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Shoud reject an expired cursor", func(t *testing.T) {
+		expired := &Signer{secret: []byte(testSecret), ttl: -time.Hour}
+
+		_, err := signer.Decode(expired.Encode(cursor))
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
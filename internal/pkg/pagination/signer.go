@@ -0,0 +1,113 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"pack-management/internal/pkg/validator"
+	"strings"
+	"time"
+)
+
+type (
+	// Signer signs the cursors with HMAC-SHA256, so clients can't craft
+	// their own, and sets their expiration.
+	Signer struct {
+		secret []byte
+		ttl    time.Duration
+	}
+
+	SignerParams struct {
+		Secret string        `validate:"required,min=32"`
+		TTL    time.Duration `validate:"gt=0"`
+	}
+)
+
+const (
+	signatureSeparator = "."
+)
+
+func NewSigner(params *SignerParams) *Signer {
+	params.validate()
+
+	return &Signer{
+		secret: []byte(params.Secret),
+		ttl:    params.TTL,
+	}
+}
+
+func (p *SignerParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (s *Signer) Encode(cursor *Cursor) string {
+	cursor.Version = cursorVersion
+	cursor.ExpiresAt = time.Now().Add(s.ttl).Unix()
+
+	//nolint:errchkjson // the cursor only has strings, bools and ints
+	payload, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(payload) +
+		signatureSeparator +
+		base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+func (s *Signer) Decode(encodedCursor string) (*Cursor, error) {
+	encodedPayload, encodedSignature, found := strings.Cut(encodedCursor, signatureSeparator)
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if !hmac.Equal(signature, s.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+
+	err = json.Unmarshal(payload, cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if cursor.Version != cursorVersion || time.Now().Unix() > cursor.ExpiresAt {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+func (s *Signer) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+func filtersHash(filters any) (string, error) {
+	if filters == nil {
+		return "", nil
+	}
+
+	payload, err := json.Marshal(filters)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(payload)
+
+	return base64.RawURLEncoding.EncodeToString(hash[:16]), nil
+}
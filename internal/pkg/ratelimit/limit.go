@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+)
+
+type (
+	// Limit is a token bucket refilled with Requests tokens per Period, it
+	// holds up to Burst tokens, Requests when 0, and each request takes one.
+	Limit struct {
+		Requests int
+		Period   time.Duration
+		Burst    int
+	}
+
+	// Bucket is the state of a client bucket, ExpiresAt is when it's full
+	// again, so it can be deleted.
+	Bucket struct {
+		Tokens    float64
+		UpdatedAt time.Time
+		ExpiresAt time.Time
+	}
+
+	// Result is the outcome of taking a token, Reset is the time until the
+	// bucket is full and RetryAfter until the next token when not allowed.
+	Result struct {
+		Allowed    bool
+		Limit      int
+		Remaining  int
+		Reset      time.Duration
+		RetryAfter time.Duration
+	}
+
+	// Store keeps the buckets, Take must refill the bucket of the key and take
+	// a token from it atomically, e.g.: see Limit.Take.
+	Store interface {
+		Take(ctx context.Context, key string, limit Limit, now time.Time) (*Result, error)
+		DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error)
+	}
+)
+
+const (
+	purgeBatch = 1000
+)
+
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return float64(l.Requests)
+}
+
+// rate is the tokens refilled per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Take refills the bucket up to now and takes a token, bucket is nil for a
+// new client, and returns the new bucket state.
+func (l Limit) Take(bucket *Bucket, now time.Time) (*Bucket, *Result) {
+	burst := l.burst()
+	rate := l.rate()
+
+	tokens := burst
+	if bucket != nil {
+		elapsed := max(now.Sub(bucket.UpdatedAt).Seconds(), 0)
+		tokens = min(burst, bucket.Tokens+elapsed*rate)
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	result := l.result(tokens, allowed)
+
+	return &Bucket{
+		Tokens:    tokens,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(result.Reset),
+	}, result
+}
+
+// result reports the bucket with tokens left after the request.
+func (l Limit) result(tokens float64, allowed bool) *Result {
+	burst := l.burst()
+	rate := l.rate()
+
+	result := &Result{
+		Allowed: allowed,
+		Limit:   int(burst),
+	}
+
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = seconds((burst - tokens) / rate)
+
+	return result
+}
+
+// Policy is the RateLimit-Policy header value, e.g.: "100;w=60;burst=20".
+func (l Limit) Policy() string {
+	policy := strconv.Itoa(l.Requests) + ";w=" + strconv.Itoa(int(l.Period.Seconds()))
+	if l.Burst > 0 {
+		policy += ";burst=" + strconv.Itoa(l.Burst)
+	}
+
+	return policy
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
+
+// PurgeExpired deletes the full buckets in batches, returning how many were
+// deleted.
+func PurgeExpired(ctx context.Context, store Store) (int, error) {
+	now := time.Now()
+	purged := 0
+
+	for ctx.Err() == nil {
+		deleted, err := store.DeleteExpired(ctx, now, purgeBatch)
+		if err != nil {
+			return purged, err
+		}
+
+		purged += deleted
+
+		if deleted < purgeBatch {
+			break
+		}
+	}
+
+	return purged, ctx.Err()
+}
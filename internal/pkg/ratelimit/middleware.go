@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"math"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/validator"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	MiddlewareParams struct {
+		Store Store `validate:"required"`
+		// Name namespaces the buckets of the default limit, "default" when
+		// empty, so the middlewares sharing a store keep their buckets apart.
+		Name string
+		// Default is the limit of the requests matching no rule.
+		Default Limit
+		// Rules are the per route limits, the first matching rule is used.
+		Rules []*Rule `validate:"dive"`
+		// Key identifies the client of the request, optional, the IP by
+		// default.
+		Key func(ctx *fiber.Ctx) string
+	}
+
+	// Rule limits the requests with the method, any when empty, and the
+	// path prefix, Name namespaces its buckets.
+	Rule struct {
+		Name   string `validate:"required"`
+		Method string
+		Prefix string `validate:"required"`
+		Limit  Limit
+	}
+)
+
+var (
+	ErrRateLimited = cerrors.New("too many requests, retry after the Retry-After seconds", cerrors.CodeRateLimited)
+)
+
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+	HeaderPolicy    = "RateLimit-Policy"
+
+	defaultRuleName = "default"
+)
+
+// Middleware limits the requests of each client by a token bucket per rule,
+// answering 429 when it's empty. The RateLimit-* headers, as the IETF
+// draft, report the bucket state. A store failure lets the request through,
+// so the limiter never takes the API down.
+func Middleware(params *MiddlewareParams) fiber.Handler {
+	params.validate()
+
+	if params.Key == nil {
+		params.Key = KeyByIP
+	}
+
+	if params.Name == "" {
+		params.Name = defaultRuleName
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		reqCtx := ctx.UserContext()
+		name, limit := params.match(ctx)
+
+		result, err := params.Store.Take(reqCtx, name+":"+params.Key(ctx), limit, time.Now())
+		if err != nil {
+			logger.FromContext(reqCtx).ErrorContext(reqCtx, "error taking the rate limit token", logger.Error(err))
+			return ctx.Next()
+		}
+
+		ctx.Set(HeaderLimit, strconv.Itoa(result.Limit))
+		ctx.Set(HeaderRemaining, strconv.Itoa(result.Remaining))
+		ctx.Set(HeaderReset, ceilSeconds(result.Reset))
+		ctx.Set(HeaderPolicy, limit.Policy())
+
+		if !result.Allowed {
+			ctx.Set(fiber.HeaderRetryAfter, ceilSeconds(result.RetryAfter))
+			return ErrRateLimited.WithMetadata("retry_after", int(math.Ceil(result.RetryAfter.Seconds())))
+		}
+
+		return ctx.Next()
+	}
+}
+
+func (p *MiddlewareParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, limit := range append([]Limit{p.Default}, rulesLimits(p.Rules)...) {
+		if limit.Requests <= 0 || limit.Period < time.Second || limit.Burst < 0 {
+			panic("ratelimit: the limits require positive requests and a period of at least 1s")
+		}
+	}
+}
+
+func (p *MiddlewareParams) match(ctx *fiber.Ctx) (string, Limit) {
+	for _, rule := range p.Rules {
+		if (rule.Method == "" || rule.Method == ctx.Method()) && strings.HasPrefix(ctx.Path(), rule.Prefix) {
+			return rule.Name, rule.Limit
+		}
+	}
+
+	return p.Name, p.Default
+}
+
+func rulesLimits(rules []*Rule) []Limit {
+	limits := make([]Limit, 0, len(rules))
+	for _, rule := range rules {
+		limits = append(limits, rule.Limit)
+	}
+
+	return limits
+}
+
+// KeyByIP identifies the client by the IP, see the fiber ProxyHeader config
+// for the deployments behind a proxy.
+func KeyByIP(ctx *fiber.Ctx) string {
+	return "ip:" + ctx.IP()
+}
+
+func ceilSeconds(value time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(value.Seconds())))
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	memoryStore struct {
+		mutex     sync.Mutex
+		buckets   map[string]*Bucket
+		nextSweep time.Time
+	}
+)
+
+const (
+	sweepInterval = time.Minute
+)
+
+// NewMemoryStore keeps the buckets in the replica memory, so each replica
+// enforces the limits on its own, meant for the single replica deployments.
+// The full buckets are swept by the takes, at most once per minute, as the
+// purge job runs in a single replica.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets: map[string]*Bucket{},
+	}
+}
+
+func (s *memoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (*Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !now.Before(s.nextSweep) {
+		s.sweep(now)
+	}
+
+	bucket, result := limit.Take(s.buckets[key], now)
+	s.buckets[key] = bucket
+
+	return result, nil
+}
+
+func (s *memoryStore) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	deleted := 0
+
+	for key, bucket := range s.buckets {
+		if deleted == limit {
+			break
+		}
+
+		if !bucket.ExpiresAt.After(now) {
+			delete(s.buckets, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// sweep deletes the full buckets, the caller holds the mutex.
+func (s *memoryStore) sweep(now time.Time) {
+	for key, bucket := range s.buckets {
+		if !bucket.ExpiresAt.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.nextSweep = now.Add(sweepInterval)
+}
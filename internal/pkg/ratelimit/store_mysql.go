@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	StoreParams struct {
+		DB *bun.DB `validate:"required"`
+	}
+
+	mysqlStore struct {
+		db *bun.DB
+	}
+
+	bucketModel struct {
+		bun.BaseModel `bun:"table:rate_limit_bucket,alias:rate_limit_bucket"`
+		Key           string    `bun:"key,pk"`
+		Tokens        float64   `bun:"tokens"`
+		UpdatedAt     time.Time `bun:"updated_at"`
+		ExpiresAt     time.Time `bun:"expires_at"`
+	}
+)
+
+// NewMysqlStore keeps the buckets in the rate_limit_bucket table, so the
+// limits are shared by the replicas.
+func NewMysqlStore(params *StoreParams) Store {
+	params.validate()
+
+	return &mysqlStore{
+		db: params.DB,
+	}
+}
+
+func (p *StoreParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Take refills the bucket and takes a token with a single upsert, so the
+// requests of the client on the other replicas wait for its row lock. The
+// tokens are derived from expires_at, when the bucket is full again, and
+// MySQL assigns the columns from left to right, so expires_at is assigned
+// last and the conditions read its value before the request. An empty
+// bucket is left unchanged, so the upsert affects no row.
+func (s *mysqlStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (*Result, error) {
+	burst := int(limit.burst())
+	// the microseconds to refill a token, integers keep the division exact
+	interval := limit.Period.Microseconds() / int64(limit.Requests)
+	tokens := bun.SafeQuery("(? - GREATEST(TIMESTAMPDIFF(MICROSECOND, ?, expires_at), 0) / ?)", burst, now, interval)
+
+	var result *Result
+
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		inserted, err := tx.NewInsert().
+			Model(&bucketModel{
+				Key:       key,
+				Tokens:    float64(burst - 1),
+				UpdatedAt: now,
+				ExpiresAt: now.Add(time.Duration(interval) * time.Microsecond),
+			}).
+			On("DUPLICATE KEY UPDATE").
+			Set("tokens = IF(? >= 1, ? - 1, tokens)", tokens, tokens).
+			Set("updated_at = IF(? >= 1, ?, updated_at)", tokens, now).
+			Set("expires_at = IF(? >= 1, DATE_ADD(?, INTERVAL ROUND((? - ? + 1) * ?) MICROSECOND), expires_at)",
+				tokens, now, burst, tokens, interval).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		// 1 when inserted, 2 when updated and 0 when unchanged
+		affected, err := inserted.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		var left float64
+
+		err = tx.NewSelect().
+			Model((*bucketModel)(nil)).
+			ColumnExpr("?", tokens).
+			Where("rate_limit_bucket.key = ?", key).
+			Scan(ctx, &left)
+		if err != nil {
+			return err
+		}
+
+		result = limit.result(left, affected > 0)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteExpired deletes up to limit buckets full at now, returning how many
+// were deleted.
+func (s *mysqlStore) DeleteExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	result, err := s.db.NewDelete().
+		Model((*bucketModel)(nil)).
+		Where("expires_at <= ?", now).
+		OrderExpr("expires_at ASC").
+		Limit(limit).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type (
+	LockerParams struct {
+		DB *bun.DB `validate:"required"`
+		// Prefix namespaces the lock names in the MySQL server, optional,
+		// "scheduler:" by default.
+		Prefix string `validate:"max=20"`
+	}
+
+	mysqlLocker struct {
+		db     *bun.DB
+		prefix string
+	}
+)
+
+const (
+	defaultLockPrefix = "scheduler:"
+	releaseTimeout    = 5 * time.Second
+)
+
+// NewMysqlLocker uses the MySQL advisory locks (GET_LOCK). The lock belongs
+// to a connection, so it is held by a dedicated one until unlocked and is
+// released by the server if the replica dies.
+func NewMysqlLocker(params *LockerParams) Locker {
+	params.validate()
+
+	if params.Prefix == "" {
+		params.Prefix = defaultLockPrefix
+	}
+
+	return &mysqlLocker{
+		db:     params.DB,
+		prefix: params.Prefix,
+	}
+}
+
+func (p *LockerParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (l *mysqlLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	lockName := l.prefix + name
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired sql.NullInt64
+
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", lockName).Scan(&acquired)
+	if err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+
+	if !acquired.Valid || acquired.Int64 != 1 {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+		defer cancel()
+
+		var released sql.NullInt64
+
+		err := conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(?)", lockName).Scan(&released)
+		if err != nil {
+			logger.FromContext(ctx).ErrorContext(ctx, "error releasing the job lock", "lock", lockName, logger.Error(err))
+		}
+
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type (
+	// Run is a job execution, ScheduledAt is the schedule slot and is nil
+	// for the manual runs.
+	Run struct {
+		ID          string
+		Job         string
+		Trigger     Trigger
+		Instance    string
+		Status      Status
+		Error       *string
+		ScheduledAt *time.Time
+		StartedAt   time.Time
+		FinishedAt  *time.Time
+	}
+
+	// Store is the run history. Create fails with ErrDuplicateRun when the
+	// job slot was already run.
+	Store interface {
+		Create(ctx context.Context, run *Run) error
+		Finish(ctx context.Context, run *Run) error
+		ListByJob(ctx context.Context, job string, limit int) ([]*Run, error)
+		DeleteStartedBefore(ctx context.Context, before time.Time, limit int) (int, error)
+	}
+
+	// Locker takes a job lock shared by all replicas, without waiting. The
+	// unlock function releases it.
+	Locker interface {
+		TryLock(ctx context.Context, name string) (unlock func(), locked bool, err error)
+	}
+
+	Trigger string
+	Status  string
+)
+
+var (
+	TriggerSchedule Trigger = "SCHEDULE"
+	TriggerManual   Trigger = "MANUAL"
+
+	StatusRunning   Status = "RUNNING"
+	StatusSucceeded Status = "SUCCEEDED"
+	StatusFailed    Status = "FAILED"
+
+	ErrDuplicateRun = errors.New("job slot already run")
+)
+
+const (
+	purgeBatch = 1000
+)
+
+// PurgeRuns deletes the runs started more than retention ago in batches,
+// returning how many were deleted. The purged slots are in the past, so
+// they are never scheduled again.
+func PurgeRuns(ctx context.Context, store Store, retention time.Duration) (int, error) {
+	before := time.Now().Add(-retention)
+	purged := 0
+
+	for ctx.Err() == nil {
+		deleted, err := store.DeleteStartedBefore(ctx, before, purgeBatch)
+		if err != nil {
+			return purged, err
+		}
+
+		purged += deleted
+
+		if deleted < purgeBatch {
+			break
+		}
+	}
+
+	return purged, ctx.Err()
+}
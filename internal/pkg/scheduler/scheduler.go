@@ -0,0 +1,444 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/tracing"
+	"pack-management/internal/pkg/validator"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type (
+	// Scheduler runs the registered jobs on their schedules. Every run takes
+	// the job lock, so only one replica runs a job at a time, and is saved
+	// in the run history.
+	Scheduler interface {
+		Register(job *Job) error
+		Start(ctx context.Context)
+		Trigger(ctx context.Context, name string) (*Run, error)
+		Jobs(ctx context.Context) ([]*JobInfo, error)
+		Runs(ctx context.Context, name string, limit int) ([]*Run, error)
+		Shutdown(ctx context.Context) error
+	}
+
+	Job struct {
+		Name string `validate:"required,max=40"`
+		// Schedule is a cron expression (e.g.: "0 3 * * *"), a descriptor
+		// (e.g.: "@daily") or "@every <duration>". The "@every" slots are
+		// aligned to the clock, so every replica gets the same ones.
+		Schedule string                          `validate:"required"`
+		Run      func(ctx context.Context) error `validate:"required"`
+		// Timeout is optional, the scheduler DefaultTimeout by default.
+		Timeout time.Duration `validate:"gte=0"`
+	}
+
+	JobInfo struct {
+		Name      string
+		Schedule  string
+		NextRunAt *time.Time
+		LastRun   *Run
+	}
+
+	Params struct {
+		Locker Locker `validate:"required"`
+		Store  Store  `validate:"required"`
+		// Instance identifies the replica in the run history, optional, the
+		// hostname by default. DefaultTimeout is optional, 10m by default.
+		Instance       string
+		DefaultTimeout time.Duration `validate:"gte=0"`
+	}
+
+	scheduler struct {
+		locker         Locker
+		store          Store
+		instance       string
+		defaultTimeout time.Duration
+		mutex          sync.RWMutex
+		entries        map[string]*entry
+		loops          sync.WaitGroup
+		running        sync.WaitGroup
+		cancelLoops    context.CancelFunc
+		jobsCtx        context.Context
+		cancelJobs     context.CancelFunc
+	}
+
+	entry struct {
+		job       *Job
+		schedule  cron.Schedule
+		mutex     sync.Mutex
+		nextRunAt *time.Time
+	}
+
+	// everySchedule runs on the multiples of the interval since the zero
+	// time, unlike the cron one that counts from the start.
+	everySchedule struct {
+		interval time.Duration
+	}
+)
+
+var (
+	ErrJobNotFound = cerrors.New("job not found", cerrors.CodeJobNotFound)
+	ErrJobRunning  = cerrors.New("the job is already running", cerrors.CodeJobRunning)
+
+	ErrJobAlreadyRegistered = errors.New("job already registered")
+)
+
+const (
+	defaultTimeout = 10 * time.Minute
+	finishTimeout  = 5 * time.Second
+	everyPrefix    = "@every "
+)
+
+func NewScheduler(params *Params) Scheduler {
+	params.validate()
+
+	if params.Instance == "" {
+		params.Instance, _ = os.Hostname()
+	}
+
+	if params.DefaultTimeout == 0 {
+		params.DefaultTimeout = defaultTimeout
+	}
+
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+
+	return &scheduler{
+		locker:         params.Locker,
+		store:          params.Store,
+		instance:       params.Instance,
+		defaultTimeout: params.DefaultTimeout,
+		entries:        map[string]*entry{},
+		jobsCtx:        jobsCtx,
+		cancelJobs:     cancelJobs,
+	}
+}
+
+func (p *Params) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (s *scheduler) Register(job *Job) error {
+	err := validator.ValidateStruct(job)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := parseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	if job.Timeout == 0 {
+		job.Timeout = s.defaultTimeout
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.entries[job.Name]; ok {
+		return ErrJobAlreadyRegistered
+	}
+
+	s.entries[job.Name] = &entry{
+		job:      job,
+		schedule: schedule,
+	}
+
+	return nil
+}
+
+// Start runs every registered job on its schedule until Shutdown is called
+// or ctx is done.
+func (s *scheduler) Start(ctx context.Context) {
+	ctx, s.cancelLoops = context.WithCancel(ctx)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, entry := range s.entries {
+		s.loops.Add(1)
+		go s.loop(ctx, entry)
+	}
+}
+
+// Trigger starts a manual run of the job in background, it fails with
+// ErrJobRunning when any replica is running it.
+func (s *scheduler) Trigger(ctx context.Context, name string) (*Run, error) {
+	entry := s.entry(name)
+	if entry == nil {
+		return nil, ErrJobNotFound
+	}
+
+	unlock, locked, err := s.locker.TryLock(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !locked {
+		return nil, ErrJobRunning
+	}
+
+	run := s.newRun(name, TriggerManual, nil)
+
+	err = s.store.Create(ctx, run)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	triggered := *run
+
+	s.running.Add(1)
+	go s.execute(logger.WithContext(s.jobsCtx, logger.FromContext(ctx)), entry, run, unlock)
+
+	return &triggered, nil
+}
+
+func (s *scheduler) Jobs(ctx context.Context) ([]*JobInfo, error) {
+	s.mutex.RLock()
+	entries := make([]*entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	s.mutex.RUnlock()
+
+	slices.SortFunc(entries, func(a, b *entry) int {
+		return strings.Compare(a.job.Name, b.job.Name)
+	})
+
+	jobs := make([]*JobInfo, 0, len(entries))
+	for _, entry := range entries {
+		runs, err := s.store.ListByJob(ctx, entry.job.Name, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		info := &JobInfo{
+			Name:      entry.job.Name,
+			Schedule:  entry.job.Schedule,
+			NextRunAt: entry.next(),
+		}
+
+		if len(runs) > 0 {
+			info.LastRun = runs[0]
+		}
+
+		jobs = append(jobs, info)
+	}
+
+	return jobs, nil
+}
+
+func (s *scheduler) Runs(ctx context.Context, name string, limit int) ([]*Run, error) {
+	if s.entry(name) == nil {
+		return nil, ErrJobNotFound
+	}
+
+	return s.store.ListByJob(ctx, name, limit)
+}
+
+// Shutdown stops scheduling and waits for the running jobs until ctx is
+// done, then cancels them.
+func (s *scheduler) Shutdown(ctx context.Context) error {
+	if s.cancelLoops != nil {
+		s.cancelLoops()
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		s.loops.Wait()
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelJobs()
+		return nil
+	case <-ctx.Done():
+		s.cancelJobs()
+		return ctx.Err()
+	}
+}
+
+func (s *scheduler) loop(ctx context.Context, entry *entry) {
+	defer s.loops.Done()
+
+	for {
+		scheduledAt := entry.schedule.Next(time.Now())
+		entry.setNext(&scheduledAt)
+
+		timer := time.NewTimer(time.Until(scheduledAt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			entry.setNext(nil)
+			return
+		case <-timer.C:
+		}
+
+		s.runScheduled(ctx, entry, scheduledAt)
+	}
+}
+
+// runScheduled runs the job slot once among the replicas: the lock avoids
+// concurrent runs and the unique slot in the history avoids a replica
+// running a slot already finished by another one.
+func (s *scheduler) runScheduled(ctx context.Context, entry *entry, scheduledAt time.Time) {
+	log := logger.FromContext(ctx).With("job", entry.job.Name)
+
+	unlock, locked, err := s.locker.TryLock(ctx, entry.job.Name)
+	if err != nil {
+		log.ErrorContext(ctx, "error taking the job lock", logger.Error(err))
+		return
+	}
+
+	if !locked {
+		log.DebugContext(ctx, "job running in another replica, skipping")
+		return
+	}
+
+	run := s.newRun(entry.job.Name, TriggerSchedule, &scheduledAt)
+
+	err = s.store.Create(ctx, run)
+	if err != nil {
+		unlock()
+
+		if errors.Is(err, ErrDuplicateRun) {
+			log.DebugContext(ctx, "job slot already run by another replica, skipping")
+			return
+		}
+
+		log.ErrorContext(ctx, "error creating the job run", logger.Error(err))
+		return
+	}
+
+	s.running.Add(1)
+	s.execute(logger.WithContext(s.jobsCtx, log), entry, run, unlock)
+}
+
+func (s *scheduler) execute(ctx context.Context, entry *entry, run *Run, unlock func()) {
+	defer s.running.Done()
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, entry.job.Timeout)
+	defer cancel()
+
+	ctx, span := tracing.Tracer().Start(ctx, "scheduler.run")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("job.name", run.Job),
+		attribute.String("job.run_id", run.ID),
+		attribute.String("job.trigger", string(run.Trigger)),
+	)
+
+	ctx = logger.With(ctx, "job", run.Job, "run_id", run.ID)
+	logger.FromContext(ctx).InfoContext(ctx, "job started")
+
+	err := safeRun(ctx, entry.job)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = StatusSucceeded
+
+	if err != nil {
+		message := err.Error()
+		run.Status = StatusFailed
+		run.Error = &message
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, message)
+		logger.FromContext(ctx).ErrorContext(ctx, "job failed", logger.Error(err))
+	} else {
+		logger.FromContext(ctx).InfoContext(ctx, "job succeeded", "duration", finishedAt.Sub(run.StartedAt).String())
+	}
+
+	finishCtx, cancelFinish := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancelFinish()
+
+	err = s.store.Finish(finishCtx, run)
+	if err != nil {
+		logger.FromContext(ctx).ErrorContext(ctx, "error finishing the job run", logger.Error(err))
+	}
+}
+
+func (s *scheduler) entry(name string) *entry {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.entries[name]
+}
+
+func (s *scheduler) newRun(name string, trigger Trigger, scheduledAt *time.Time) *Run {
+	return &Run{
+		Job:         name,
+		Trigger:     trigger,
+		Instance:    s.instance,
+		Status:      StatusRunning,
+		ScheduledAt: scheduledAt,
+		StartedAt:   time.Now(),
+	}
+}
+
+// safeRun turns a job panic into an error, so it doesn't stop the scheduler.
+func safeRun(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panic: %v", recovered)
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+func (e *entry) next() *time.Time {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.nextRunAt
+}
+
+func (e *entry) setNext(next *time.Time) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.nextRunAt = next
+}
+
+func parseSchedule(spec string) (cron.Schedule, error) {
+	if strings.HasPrefix(spec, everyPrefix) {
+		interval, err := time.ParseDuration(strings.TrimPrefix(spec, everyPrefix))
+		if err != nil {
+			return nil, err
+		}
+
+		if interval < time.Second {
+			return nil, fmt.Errorf("the @every interval must be at least 1s, got %s", interval)
+		}
+
+		return everySchedule{interval: interval}, nil
+	}
+
+	return cron.ParseStandard(spec)
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(s.interval).Add(s.interval)
+}
@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"pack-management/internal/pkg/uuid"
+	"pack-management/internal/pkg/validator"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/uptrace/bun"
+)
+
+type (
+	StoreParams struct {
+		DB *bun.DB `validate:"required"`
+	}
+
+	mysqlStore struct {
+		db *bun.DB
+	}
+
+	runModel struct {
+		bun.BaseModel `bun:"table:job_run,alias:job_run"`
+		ID            string     `bun:"id,pk"`
+		JobName       string     `bun:"job_name"`
+		TriggeredBy   Trigger    `bun:"triggered_by"`
+		Instance      string     `bun:"instance"`
+		Status        Status     `bun:"status"`
+		Error         *string    `bun:"error"`
+		ScheduledAt   *time.Time `bun:"scheduled_at"`
+		StartedAt     time.Time  `bun:"started_at"`
+		FinishedAt    *time.Time `bun:"finished_at"`
+	}
+)
+
+const (
+	runIDPrefix = "job_run_"
+
+	mysqlDuplicateEntry = 1062
+)
+
+func NewMysqlStore(params *StoreParams) Store {
+	params.validate()
+
+	return &mysqlStore{
+		db: params.DB,
+	}
+}
+
+func (p *StoreParams) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Create relies on the unique (job_name, scheduled_at) index to detect a
+// slot already run.
+func (s *mysqlStore) Create(ctx context.Context, run *Run) error {
+	run.ID = runIDPrefix + uuid.New().String()
+
+	_, err := s.db.NewInsert().Model(toRunModel(run)).Exec(ctx)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return ErrDuplicateRun
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *mysqlStore) Finish(ctx context.Context, run *Run) error {
+	_, err := s.db.NewUpdate().
+		Model(toRunModel(run)).
+		Column("status", "error", "finished_at").
+		WherePK().
+		Exec(ctx)
+
+	return err
+}
+
+func (s *mysqlStore) ListByJob(ctx context.Context, job string, limit int) ([]*Run, error) {
+	models := make([]*runModel, 0)
+
+	err := s.db.NewSelect().
+		Model(&models).
+		Where("job_run.job_name = ?", job).
+		OrderExpr("job_run.started_at DESC, job_run.id DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]*Run, 0, len(models))
+	for _, model := range models {
+		runs = append(runs, model.ToRun())
+	}
+
+	return runs, nil
+}
+
+// DeleteStartedBefore deletes up to limit runs started before the time,
+// returning how many were deleted.
+func (s *mysqlStore) DeleteStartedBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	result, err := s.db.NewDelete().
+		Model((*runModel)(nil)).
+		Where("started_at < ?", before).
+		OrderExpr("started_at ASC").
+		Limit(limit).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
+func toRunModel(run *Run) *runModel {
+	return &runModel{
+		ID:          run.ID,
+		JobName:     run.Job,
+		TriggeredBy: run.Trigger,
+		Instance:    run.Instance,
+		Status:      run.Status,
+		Error:       run.Error,
+		ScheduledAt: run.ScheduledAt,
+		StartedAt:   run.StartedAt,
+		FinishedAt:  run.FinishedAt,
+	}
+}
+
+func (m *runModel) ToRun() *Run {
+	if m == nil {
+		return nil
+	}
+
+	return &Run{
+		ID:          m.ID,
+		Job:         m.JobName,
+		Trigger:     m.TriggeredBy,
+		Instance:    m.Instance,
+		Status:      m.Status,
+		Error:       m.Error,
+		ScheduledAt: m.ScheduledAt,
+		StartedAt:   m.StartedAt,
+		FinishedAt:  m.FinishedAt,
+	}
+}
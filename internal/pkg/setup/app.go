@@ -4,14 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/logger"
+	"pack-management/internal/pkg/tracing"
+	"runtime/debug"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
 type (
@@ -33,13 +36,33 @@ func NewApp() *App {
 		DisableStartupMessage:    false,
 		EnablePrintRoutes:        false,
 		EnableSplittingOnParsers: true,
+		ErrorHandler:             cerrors.Handler,
 	})
 
+	fiberApp.Use(tracing.Middleware())
+	fiberApp.Use(logger.Middleware())
+	fiberApp.Use(recover.New(recover.Config{
+		EnableStackTrace:  true,
+		StackTraceHandler: logPanic,
+	}))
+
 	return &App{
 		fiberApp: fiberApp,
 	}
 }
 
+// logPanic logs the recovered panic with the request logger, the error
+// handler answers it as a 500 internal_error.
+func logPanic(ctx *fiber.Ctx, recovered any) {
+	reqCtx := ctx.UserContext()
+	logger.FromContext(reqCtx).ErrorContext(
+		reqCtx,
+		"request panic",
+		"panic", fmt.Sprint(recovered),
+		"stack", string(debug.Stack()),
+	)
+}
+
 func (a *App) Start(port string) {
 	if port == "" {
 		port = defaultPort
@@ -48,10 +71,11 @@ func (a *App) Start(port string) {
 	err := a.fiberApp.Listen(":" + port)
 	if err != nil {
 		if !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("HTTP server error: %v", err)
+			slog.Error("HTTP server error", logger.Error(err))
+			os.Exit(1)
 		}
 
-		log.Println("Stopped serving new connections.")
+		slog.Info("Stopped serving new connections.")
 	}
 }
 
@@ -59,21 +83,8 @@ func (a *App) FiberApp() *fiber.App {
 	return a.fiberApp
 }
 
-func (a *App) Shutdown(ctx context.Context) {
-	shutdownC := make(chan os.Signal, 1)
-	signal.Notify(shutdownC, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	<-shutdownC
-
-	log.Println("Shutting down...")
-
-	ctx, shutdownRelease := context.WithTimeout(ctx, 10*time.Second)
-	defer shutdownRelease()
-
-	ctx.Done()
-
-	if err := a.fiberApp.ShutdownWithContext(ctx); err != nil {
-		log.Fatalf("HTTP shutdown error: %v", err)
-	}
-
-	log.Println("Shutdown complete.")
+// Shutdown stops accepting connections and waits for the in-flight requests
+// until ctx is done.
+func (a *App) Shutdown(ctx context.Context) error {
+	return a.fiberApp.ShutdownWithContext(ctx)
 }
@@ -0,0 +1,240 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type (
+	Format string
+)
+
+var (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+
+	ErrUnsupportedFormat = errors.New("unsupported spreadsheet format, use .csv or .xlsx")
+	ErrEmptySpreadsheet  = errors.New("the spreadsheet has no sheets")
+)
+
+const (
+	utf8BOM = "\uFEFF"
+
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04:05"
+)
+
+var (
+	// builtInDateFormats are the built-in number formats with a date, e.g.:
+	// 14 is "m/d/yy", the time only ones are left as displayed.
+	builtInDateFormats = map[int]bool{14: true, 15: true, 16: true, 17: true, 22: true}
+)
+
+// FormatFromFilename detects the format by the file extension.
+func FormatFromFilename(name string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// Read returns the rows of the file, the first sheet of the XLSX ones, and
+// the rows may have different lengths. The XLSX cells are the displayed
+// values, but the date cells, whose display depends on the locale, e.g.:
+// 10/21/26, are ISO 8601: YYYY-MM-DD, with THH:MM:SS when there is a time.
+func Read(r io.Reader, format Format) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatXLSX:
+		return readXLSX(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// spreadsheet apps prefix the CSV exports with the UTF-8 BOM
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], utf8BOM)
+	}
+
+	return rows, nil
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, ErrEmptySpreadsheet
+	}
+
+	sheet := sheets[0]
+
+	rows, err := file.GetRows(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	rawRows, err := file.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := file.GetWorkbookProps()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &dateReader{
+		file:     file,
+		sheet:    sheet,
+		date1904: props.Date1904 != nil && *props.Date1904,
+		styles:   map[int]bool{},
+	}
+
+	for i, row := range rows {
+		for j := range row {
+			if i >= len(rawRows) || j >= len(rawRows[i]) {
+				continue
+			}
+
+			date, ok, err := reader.read(i, j, rawRows[i][j])
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				row[j] = date
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// dateReader reads the date cells of a sheet, caching the date styles.
+type dateReader struct {
+	file     *excelize.File
+	sheet    string
+	date1904 bool
+	styles   map[int]bool
+}
+
+// read returns the ISO 8601 date of the cell when it is a date serial
+// number, e.g.: 46316 is 2026-10-21, in a date number format.
+func (r *dateReader) read(row, col int, raw string) (string, bool, error) {
+	serial, err := strconv.ParseFloat(raw, 64)
+	if err != nil || serial < 0 {
+		return "", false, nil
+	}
+
+	cell, err := excelize.CoordinatesToCellName(col+1, row+1)
+	if err != nil {
+		return "", false, err
+	}
+
+	cellType, err := r.file.GetCellType(r.sheet, cell)
+	if err != nil {
+		return "", false, err
+	}
+
+	// the strings and the booleans are never dates
+	if cellType != excelize.CellTypeUnset && cellType != excelize.CellTypeNumber {
+		return "", false, nil
+	}
+
+	isDate, err := r.isDateStyle(cell)
+	if err != nil || !isDate {
+		return "", false, err
+	}
+
+	date, err := excelize.ExcelDateToTime(serial, r.date1904)
+	if err != nil {
+		return "", false, nil
+	}
+
+	if date.Equal(date.Truncate(24 * time.Hour)) {
+		return date.Format(dateLayout), true, nil
+	}
+
+	return date.Format(dateTimeLayout), true, nil
+}
+
+func (r *dateReader) isDateStyle(cell string) (bool, error) {
+	styleID, err := r.file.GetCellStyle(r.sheet, cell)
+	if err != nil {
+		return false, err
+	}
+
+	if isDate, ok := r.styles[styleID]; ok {
+		return isDate, nil
+	}
+
+	style, err := r.file.GetStyle(styleID)
+	if err != nil {
+		return false, err
+	}
+
+	isDate := builtInDateFormats[style.NumFmt]
+	if style.CustomNumFmt != nil {
+		isDate = isDateFormat(*style.CustomNumFmt)
+	}
+
+	r.styles[styleID] = isDate
+
+	return isDate, nil
+}
+
+// isDateFormat checks for the day or year tokens in the number format code,
+// out of the quoted texts, the escaped chars and the brackets, e.g.:
+// "dd/mm/yyyy" or "[$-416]d mmm yy", the month token "m" is also the minutes
+// one, so it is ignored.
+func isDateFormat(code string) bool {
+	quoted, bracketed := false, false
+
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '\\' || c == '_' || c == '*':
+			// the escaped, the padding and the repeated char
+			i++
+		case c == '[':
+			bracketed = true
+		case c == ']':
+			bracketed = false
+		case bracketed:
+		case c == 'd' || c == 'D' || c == 'y' || c == 'Y':
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestIsDateFormat(t *testing.T) {
+	t.Run("Shoud detect the date formats", func(t *testing.T) {
+		for _, code := range []string{"dd/mm/yyyy", "yyyy-mm-dd", "[$-416]d mmm yy", "d-mmm", "m/d/yy h:mm"} {
+			assert.True(t, isDateFormat(code), code)
+		}
+	})
+
+	t.Run("Shoud ignore the time, number and literal formats", func(t *testing.T) {
+		for _, code := range []string{"General", "0.00", "h:mm:ss", `"day" 0`, `0\d`, "[Red]0", "#,##0_);(#,##0)", "0*d"} {
+			assert.False(t, isDateFormat(code), code)
+		}
+	})
+}
+
+func TestReadXLSX(t *testing.T) {
+	newFile := func(t *testing.T, fill func(file *excelize.File, sheet string)) *bytes.Buffer {
+		file := excelize.NewFile()
+		defer file.Close()
+
+		fill(file, file.GetSheetName(0))
+
+		content, err := file.WriteToBuffer()
+		assert.Nil(t, err)
+
+		return content
+	}
+
+	dateStyle := func(t *testing.T, file *excelize.File, style *excelize.Style) int {
+		styleID, err := file.NewStyle(style)
+		assert.Nil(t, err)
+
+		return styleID
+	}
+
+	t.Run("Shoud read the date cells as ISO 8601", func(t *testing.T) {
+		content := newFile(t, func(file *excelize.File, sheet string) {
+			shortDate := dateStyle(t, file, &excelize.Style{NumFmt: 14})
+			customDate := "dd/mm/yyyy"
+			brDate := dateStyle(t, file, &excelize.Style{CustomNumFmt: &customDate})
+			dateTime := dateStyle(t, file, &excelize.Style{NumFmt: 22})
+
+			file.SetSheetRow(sheet, "A1", &[]string{"short", "custom", "datetime", "text", "number"})
+			file.SetCellValue(sheet, "A2", time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC))
+			file.SetCellStyle(sheet, "A2", "A2", shortDate)
+			file.SetCellValue(sheet, "B2", time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC))
+			file.SetCellStyle(sheet, "B2", "B2", brDate)
+			file.SetCellValue(sheet, "C2", time.Date(2026, 10, 23, 14, 30, 0, 0, time.UTC))
+			file.SetCellStyle(sheet, "C2", "C2", dateTime)
+			file.SetCellValue(sheet, "D2", "2026-10-24")
+			file.SetCellValue(sheet, "E2", 42)
+		})
+
+		rows, err := Read(content, FormatXLSX)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{
+			{"short", "custom", "datetime", "text", "number"},
+			{"2026-10-21", "2026-10-22", "2026-10-23T14:30:00", "2026-10-24", "42"},
+		}, rows)
+	})
+
+	t.Run("Shoud read the dates of a 1904 workbook", func(t *testing.T) {
+		content := newFile(t, func(file *excelize.File, sheet string) {
+			date1904 := true
+			file.SetWorkbookProps(&excelize.WorkbookPropsOptions{Date1904: &date1904})
+
+			file.SetCellValue(sheet, "A1", time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC))
+			file.SetCellStyle(sheet, "A1", "A1", dateStyle(t, file, &excelize.Style{NumFmt: 14}))
+		})
+
+		rows, err := Read(content, FormatXLSX)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{{"2026-10-21"}}, rows)
+	})
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	headerCarrier struct {
+		ctx *fiber.Ctx
+	}
+)
+
+// Middleware starts a server span per request and stores it in the fiber
+// user context, so handlers must pass ctx.UserContext() down to services.
+func Middleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		parentCtx := otel.GetTextMapPropagator().Extract(ctx.UserContext(), headerCarrier{ctx: ctx})
+
+		spanCtx, span := Tracer().Start(
+			parentCtx,
+			ctx.Method()+" "+ctx.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Method()),
+				semconv.URLPath(ctx.Path()),
+				semconv.UserAgentOriginal(ctx.Get(fiber.HeaderUserAgent)),
+			),
+		)
+		defer span.End()
+
+		ctx.SetUserContext(spanCtx)
+
+		err := ctx.Next()
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		route := ctx.Route().Path
+		statusCode := ctx.Response().StatusCode()
+
+		span.SetName(ctx.Method() + " " + route)
+		span.SetAttributes(
+			semconv.HTTPRoute(route),
+			semconv.HTTPResponseStatusCode(statusCode),
+		)
+
+		if statusCode >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+
+		return err
+	}
+}
+
+func (c headerCarrier) Get(key string) string {
+	return c.ctx.Get(key)
+}
+
+func (c headerCarrier) Set(key string, value string) {
+	c.ctx.Request().Header.Set(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	headers := c.ctx.GetReqHeaders()
+
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
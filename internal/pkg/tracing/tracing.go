@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"pack-management/internal/pkg/validator"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	Provider interface {
+		Shutdown(ctx context.Context) error
+	}
+
+	Params struct {
+		ServiceName string  `validate:"required"`
+		Exporter    string  `validate:"omitempty,oneof=none stdout otlp"`
+		Endpoint    string  `validate:"omitempty,url"`
+		SampleRatio float64 `validate:"gte=0,lte=1"`
+	}
+
+	provider struct {
+		tracerProvider *sdktrace.TracerProvider
+	}
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+
+	instrumentationName = "pack-management"
+)
+
+func NewProvider(ctx context.Context, params *Params) (Provider, error) {
+	params.validate()
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if exporter == nil {
+		return &provider{}, nil
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(params.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(params.ServiceName),
+		)),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+
+	return &provider{
+		tracerProvider: tracerProvider,
+	}, nil
+}
+
+func (p *Params) validate() {
+	err := validator.ValidateStruct(p)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (p *provider) Shutdown(ctx context.Context) error {
+	if p.tracerProvider == nil {
+		return nil
+	}
+
+	return p.tracerProvider.Shutdown(ctx)
+}
+
+// Tracer returns the application tracer from the global provider, so it is a
+// no-op until NewProvider registers an exporter.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartDetachedSpan starts a child span for work that outlives the caller,
+// like the enrichment goroutines, keeping the trace but dropping the
+// caller's cancellation.
+func StartDetachedSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return Tracer().Start(context.WithoutCancel(ctx), name)
+}
+
+// StartLinkedSpan starts a new root span linked to the span that produced the
+// work, used by queue consumers that run long after the request finished.
+func StartLinkedSpan(ctx context.Context, link trace.SpanContext, name string) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+	}
+
+	if link.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: link}))
+	}
+
+	return Tracer().Start(ctx, name, opts...)
+}
+
+func newExporter(ctx context.Context, params *Params) (sdktrace.SpanExporter, error) {
+	switch params.Exporter {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if params.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(params.Endpoint))
+		}
+
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, nil
+	}
+}
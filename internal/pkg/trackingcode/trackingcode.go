@@ -0,0 +1,129 @@
+package trackingcode
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// A code is the 2 letters prefix, 2 digits, 3 letters, 4 digits and the check
+// digit, e.g.: BR12ABC34566.
+var (
+	layout = []charset{letters, letters, digits, digits, letters, letters, letters, digits, digits, digits, digits}
+
+	// generatedLetters skips I, O and Q, easily mistaken for 1 and 0.
+	generatedLetters = "ABCDEFGHJKLMNPRSTUVWXYZ"
+	generatedDigits  = "0123456789"
+)
+
+type charset int
+
+const (
+	letters charset = iota
+	digits
+)
+
+const (
+	// Length is the length of a code, check digit included.
+	Length = 12
+
+	prefixLength = 2
+)
+
+// New generates a random code with the prefix, a 2 uppercase letters code,
+// e.g.: the country. The codes are random, so the caller must handle the
+// rare collisions, e.g.: retry on the unique index violation. The bodies
+// without a check digit are drawn again.
+func New(prefix string) (string, error) {
+	for {
+		body := []byte(prefix)
+
+		for _, set := range layout[prefixLength:] {
+			chars := generatedDigits
+			if set == letters {
+				chars = generatedLetters
+			}
+
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+			if err != nil {
+				return "", err
+			}
+
+			body = append(body, chars[n.Int64()])
+		}
+
+		digit, ok := checkDigit(string(body))
+		if ok {
+			return string(body) + string(digit), nil
+		}
+	}
+}
+
+// Normalize uppercases the code and drops the spaces and dashes customers
+// may type, e.g.: "br 12-abc-34567".
+func Normalize(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+
+		return r
+	}, strings.ToUpper(code))
+}
+
+// Valid reports if the normalized code has the layout and the check digit,
+// catching most typos before any lookup: every digit typo and swap of
+// adjacent chars, but not the letters whose values are 11 apart, e.g.: A, K
+// and U. The bodies without a check digit are never valid. It's not an access
+// control, the codes are random.
+func Valid(code string) bool {
+	if len(code) != Length {
+		return false
+	}
+
+	for i, set := range layout {
+		if charValue(code[i]) < 0 || (set == letters) != isLetter(code[i]) {
+			return false
+		}
+	}
+
+	digit, ok := checkDigit(code[:Length-1])
+
+	return ok && code[Length-1] == digit
+}
+
+// checkDigit is the ISO 6346 (freight containers) check digit: the sum of
+// the char values weighted by the powers of 2, modulo 11. ISO 6346 maps the
+// remainder 10 to 0, which lets some typos through, so those bodies have no
+// check digit instead.
+func checkDigit(body string) (byte, bool) {
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		sum += charValue(body[i]) << i
+	}
+
+	if sum%11 == 10 {
+		return 0, false
+	}
+
+	return byte('0' + sum%11), true
+}
+
+// charValue maps the digits to themselves and the letters from A = 10
+// upwards skipping the multiples of 11, it is -1 for the other chars.
+func charValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case isLetter(c):
+		value := int(c-'A') + 10
+
+		return value + (value-1)/10
+	default:
+		return -1
+	}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
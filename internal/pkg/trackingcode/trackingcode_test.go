@@ -0,0 +1,121 @@
+package trackingcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDigit(t *testing.T) {
+	t.Run("Shoud compute the ISO 6346 check digit", func(t *testing.T) {
+		// the ISO 6346 example container CSQU3054383
+		digit, ok := checkDigit("CSQU305438")
+		assert.True(t, ok)
+		assert.Equal(t, byte('3'), digit)
+
+		digit, ok = checkDigit("BR12ABC3456")
+		assert.True(t, ok)
+		assert.Equal(t, byte('6'), digit)
+	})
+
+	t.Run("Shoud have no check digit for the remainder 10", func(t *testing.T) {
+		_, ok := checkDigit("BR29CJD7776")
+		assert.False(t, ok)
+	})
+
+	t.Run("Shoud map the letters skipping the multiples of 11", func(t *testing.T) {
+		assert.Equal(t, 10, charValue('A'))
+		assert.Equal(t, 12, charValue('B'))
+		assert.Equal(t, 21, charValue('K'))
+		assert.Equal(t, 23, charValue('L'))
+		assert.Equal(t, 38, charValue('Z'))
+		assert.Equal(t, -1, charValue('a'))
+	})
+}
+
+func TestValid(t *testing.T) {
+	t.Run("Shoud accept the generated codes", func(t *testing.T) {
+		for range 1000 {
+			code, err := New("BR")
+			assert.Nil(t, err)
+			assert.Len(t, code, Length)
+			assert.True(t, Valid(code), code)
+		}
+	})
+
+	t.Run("Shoud accept a normalized code", func(t *testing.T) {
+		assert.True(t, Valid("BR12ABC34566"))
+		assert.True(t, Valid(Normalize("br 12-abc-3456 6")))
+	})
+
+	t.Run("Shoud reject the generated codes with a single char typo", func(t *testing.T) {
+		for range 200 {
+			code, err := New("BR")
+			assert.Nil(t, err)
+
+			for i := prefixLength; i < Length; i++ {
+				chars := generatedDigits
+				if i < Length-1 && layout[i] == letters {
+					chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+				}
+
+				for _, c := range []byte(chars) {
+					// the letters 11 apart, e.g.: A, K and U, have the same
+					// weighted value modulo 11, a known ISO 6346 limitation
+					if c == code[i] || (charValue(c)-charValue(code[i]))%11 == 0 {
+						continue
+					}
+
+					typo := code[:i] + string(c) + code[i+1:]
+					assert.False(t, Valid(typo), typo)
+				}
+			}
+		}
+	})
+
+	t.Run("Shoud reject the generated codes with swapped adjacent chars", func(t *testing.T) {
+		for range 200 {
+			code, err := New("BR")
+			assert.Nil(t, err)
+
+			for i := 0; i < Length-1; i++ {
+				if (charValue(code[i])-charValue(code[i+1]))%11 == 0 {
+					continue
+				}
+
+				swapped := code[:i] + string(code[i+1]) + string(code[i]) + code[i+2:]
+				assert.False(t, Valid(swapped), swapped)
+			}
+		}
+	})
+
+	t.Run("Shoud reject a body without a check digit", func(t *testing.T) {
+		// ISO 6346 gives it the check digit 0, the one of BR59CJD7776, so the
+		// typo BR59CJD77760 of this code would be valid
+		assert.False(t, Valid("BR29CJD77760"))
+		assert.True(t, Valid("BR59CJD77760"))
+	})
+
+	t.Run("Shoud reject the codes out of the layout", func(t *testing.T) {
+		for _, code := range []string{
+			"",
+			"BR12ABC3456",
+			"BR12ABC345666",
+			"1R12ABC34566",
+			"BRA2ABC34566",
+			"BR12A1C34566",
+			"BR12ABC3A566",
+			"BR12ABC3456A",
+			"br12abc34566",
+			"BR12AB?34566",
+		} {
+			assert.False(t, Valid(code), code)
+		}
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	t.Run("Shoud uppercase and drop the spaces and dashes", func(t *testing.T) {
+		assert.Equal(t, "BR12ABC34566", Normalize(" br12-abc 34566 "))
+	})
+}
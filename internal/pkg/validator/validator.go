@@ -1,6 +1,10 @@
 package validator
 
 import (
+	"errors"
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -10,8 +14,105 @@ var (
 
 func init()  {
 	defaultValidator = validator.New(validator.WithRequiredStructEnabled())
+	defaultValidator.RegisterTagNameFunc(jsonFieldName)
 }
 
 func ValidateStruct(i interface{}) error {
 	return defaultValidator.Struct(i)
 }
+
+// FieldError is an invalid field, by its json name, with the failed rule and
+// a message for the clients.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Fields returns the invalid fields of err, it is nil when err is not a
+// validation error.
+func Fields(err error) []*FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fields := make([]*FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fields = append(fields, &FieldError{
+			Field:   fieldError.Field(),
+			Rule:    fieldError.Tag(),
+			Message: fieldMessage(fieldError),
+		})
+	}
+
+	return fields
+}
+
+// fieldMessage describes the failed rule, the rules not listed get a generic
+// message.
+func fieldMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	isText := fieldError.Kind() == reflect.String
+	isList := fieldError.Kind() == reflect.Slice || fieldError.Kind() == reflect.Map
+
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "max", "lte":
+		if isText {
+			return "must have at most " + param + " characters"
+		}
+
+		if isList {
+			return "must have at most " + param + " items"
+		}
+
+		return "must be at most " + param
+	case "min", "gte":
+		if isText {
+			return "must have at least " + param + " characters"
+		}
+
+		if isList {
+			return "must have at least " + param + " items"
+		}
+
+		return "must be at least " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lt":
+		return "must be less than " + param
+	case "len":
+		if isText {
+			return "must have " + param + " characters"
+		}
+
+		return "must have " + param + " items"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "datetime":
+		return "must be a date in the " + param + " layout"
+	case "numeric":
+		return "must be a number"
+	case "alpha":
+		return "must have only letters"
+	case "uppercase":
+		return "must be upper case"
+	case "url":
+		return "must be an URL"
+	case "email":
+		return "must be an email"
+	default:
+		return "is invalid"
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
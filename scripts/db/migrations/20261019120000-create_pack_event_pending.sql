@@ -0,0 +1,15 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `pack_event_pending` (
+  `id` VARCHAR(255) NOT NULL,
+  `pack_id` VARCHAR(255) NOT NULL,
+  `location` TEXT NOT NULL,
+  `description` TEXT NOT NULL,
+  `date` TIMESTAMP NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+);
+CREATE INDEX `pack_event_pending_created_at_index` ON `pack_event_pending` (`created_at`);
+
+-- +migrate Down
+DROP TABLE `pack_event_pending`;
@@ -0,0 +1,16 @@
+
+-- +migrate Up
+CREATE INDEX `pack_created_at_id_index` ON `pack` (`created_at`, `id`);
+CREATE INDEX `pack_estimated_delivery_date_id_index` ON `pack` (`estimated_delivery_date`, `id`);
+CREATE INDEX `pack_status_estimated_delivery_date_index` ON `pack` (`status`, `estimated_delivery_date`);
+CREATE INDEX `pack_delivered_at_index` ON `pack` (`delivered_at`);
+CREATE INDEX `pack_is_holiday_index` ON `pack` (`is_holiday`);
+CREATE FULLTEXT INDEX `pack_description_fulltext_index` ON `pack` (`description`);
+
+-- +migrate Down
+DROP INDEX `pack_description_fulltext_index` ON `pack`;
+DROP INDEX `pack_is_holiday_index` ON `pack`;
+DROP INDEX `pack_delivered_at_index` ON `pack`;
+DROP INDEX `pack_status_estimated_delivery_date_index` ON `pack`;
+DROP INDEX `pack_estimated_delivery_date_id_index` ON `pack`;
+DROP INDEX `pack_created_at_id_index` ON `pack`;
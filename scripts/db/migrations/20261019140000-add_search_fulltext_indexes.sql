@@ -0,0 +1,8 @@
+
+-- +migrate Up
+CREATE FULLTEXT INDEX `person_name_fulltext_index` ON `person` (`name`);
+CREATE FULLTEXT INDEX `pack_event_location_description_fulltext_index` ON `pack_event` (`location`, `description`);
+
+-- +migrate Down
+DROP INDEX `pack_event_location_description_fulltext_index` ON `pack_event`;
+DROP INDEX `person_name_fulltext_index` ON `person`;
@@ -0,0 +1,6 @@
+
+-- +migrate Up
+CREATE INDEX `pack_canceled_at_index` ON `pack` (`canceled_at`);
+
+-- +migrate Down
+DROP INDEX `pack_canceled_at_index` ON `pack`;
@@ -0,0 +1,8 @@
+
+-- +migrate Up
+ALTER TABLE `pack` ADD COLUMN `overdue_since` TIMESTAMP NULL DEFAULT NULL AFTER `canceled_at`;
+CREATE INDEX `pack_status_overdue_since_index` ON `pack` (`status`, `overdue_since`);
+
+-- +migrate Down
+DROP INDEX `pack_status_overdue_since_index` ON `pack`;
+ALTER TABLE `pack` DROP COLUMN `overdue_since`;
@@ -0,0 +1,19 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `job_run` (
+  `id` VARCHAR(255) NOT NULL,
+  `job_name` VARCHAR(64) NOT NULL,
+  `triggered_by` ENUM('SCHEDULE', 'MANUAL') NOT NULL,
+  `instance` VARCHAR(255) NOT NULL,
+  `status` ENUM('RUNNING', 'SUCCEEDED', 'FAILED') NOT NULL DEFAULT 'RUNNING',
+  `error` TEXT NULL DEFAULT NULL,
+  `scheduled_at` TIMESTAMP NULL DEFAULT NULL,
+  `started_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `finished_at` TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `job_run_job_name_scheduled_at_unique` (`job_name`, `scheduled_at`)
+);
+CREATE INDEX `job_run_job_name_started_at_index` ON `job_run` (`job_name`, `started_at`);
+
+-- +migrate Down
+DROP TABLE `job_run`;
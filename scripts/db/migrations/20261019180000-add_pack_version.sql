@@ -0,0 +1,6 @@
+
+-- +migrate Up
+ALTER TABLE `pack` ADD COLUMN `version` INT UNSIGNED NOT NULL DEFAULT 1 AFTER `overdue_since`;
+
+-- +migrate Down
+ALTER TABLE `pack` DROP COLUMN `version`;
@@ -0,0 +1,17 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `idempotency_key` (
+  `key` VARCHAR(255) NOT NULL,
+  `request_hash` CHAR(64) NOT NULL,
+  `status` ENUM('PROCESSING', 'COMPLETED') NOT NULL DEFAULT 'PROCESSING',
+  `response_status` SMALLINT UNSIGNED NULL DEFAULT NULL,
+  `response_headers` JSON NULL DEFAULT NULL,
+  `response_body` MEDIUMBLOB NULL DEFAULT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `expires_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`key`)
+);
+CREATE INDEX `idempotency_key_expires_at_index` ON `idempotency_key` (`expires_at`);
+
+-- +migrate Down
+DROP TABLE `idempotency_key`;
@@ -0,0 +1,60 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `tenant` (
+  `id` VARCHAR(64) NOT NULL,
+  `name` VARCHAR(255) NOT NULL,
+  `holiday_country` CHAR(2) NOT NULL DEFAULT 'BR',
+  `api_key_hash` CHAR(64) NULL DEFAULT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `tenant_api_key_hash_unique` (`api_key_hash`)
+);
+INSERT INTO `tenant` (`id`, `name`, `holiday_country`) VALUES ('default', 'Default', 'BR');
+
+ALTER TABLE `person` ADD COLUMN `tenant_id` VARCHAR(64) NOT NULL DEFAULT 'default' AFTER `id`;
+ALTER TABLE `person` ALTER COLUMN `tenant_id` DROP DEFAULT;
+ALTER TABLE `person` ADD CONSTRAINT `person_tenant_id_foreign` FOREIGN KEY (`tenant_id`) REFERENCES `tenant`(`id`);
+CREATE INDEX `person_tenant_id_name_index` ON `person` (`tenant_id`, `name`);
+
+ALTER TABLE `pack` ADD COLUMN `tenant_id` VARCHAR(64) NOT NULL DEFAULT 'default' AFTER `id`;
+ALTER TABLE `pack` ALTER COLUMN `tenant_id` DROP DEFAULT;
+ALTER TABLE `pack` ADD CONSTRAINT `pack_tenant_id_foreign` FOREIGN KEY (`tenant_id`) REFERENCES `tenant`(`id`);
+CREATE INDEX `pack_tenant_id_created_at_id_index` ON `pack` (`tenant_id`, `created_at`, `id`);
+CREATE INDEX `pack_tenant_id_estimated_delivery_date_id_index` ON `pack` (`tenant_id`, `estimated_delivery_date`, `id`);
+
+ALTER TABLE `pack_event` ADD COLUMN `tenant_id` VARCHAR(64) NOT NULL DEFAULT 'default' AFTER `id`;
+ALTER TABLE `pack_event` ALTER COLUMN `tenant_id` DROP DEFAULT;
+ALTER TABLE `pack_event` ADD CONSTRAINT `pack_event_tenant_id_foreign` FOREIGN KEY (`tenant_id`) REFERENCES `tenant`(`id`);
+
+ALTER TABLE `pack_event_pending` ADD COLUMN `tenant_id` VARCHAR(64) NOT NULL DEFAULT 'default' AFTER `id`;
+ALTER TABLE `pack_event_pending` ALTER COLUMN `tenant_id` DROP DEFAULT;
+
+ALTER TABLE `holiday` ADD COLUMN `country` CHAR(2) NOT NULL DEFAULT 'BR' AFTER `id`;
+ALTER TABLE `holiday` ALTER COLUMN `country` DROP DEFAULT;
+CREATE INDEX `holiday_country_date_index` ON `holiday` (`country`, `date`);
+
+ALTER TABLE `idempotency_key` ADD COLUMN `scope` VARCHAR(64) NOT NULL DEFAULT '' FIRST,
+  DROP PRIMARY KEY,
+  ADD PRIMARY KEY (`scope`, `key`);
+
+-- +migrate Down
+ALTER TABLE `idempotency_key` DROP PRIMARY KEY, DROP COLUMN `scope`, ADD PRIMARY KEY (`key`);
+
+DROP INDEX `holiday_country_date_index` ON `holiday`;
+ALTER TABLE `holiday` DROP COLUMN `country`;
+
+ALTER TABLE `pack_event_pending` DROP COLUMN `tenant_id`;
+
+ALTER TABLE `pack_event` DROP FOREIGN KEY `pack_event_tenant_id_foreign`;
+ALTER TABLE `pack_event` DROP COLUMN `tenant_id`;
+
+DROP INDEX `pack_tenant_id_estimated_delivery_date_id_index` ON `pack`;
+DROP INDEX `pack_tenant_id_created_at_id_index` ON `pack`;
+ALTER TABLE `pack` DROP FOREIGN KEY `pack_tenant_id_foreign`;
+ALTER TABLE `pack` DROP COLUMN `tenant_id`;
+
+DROP INDEX `person_tenant_id_name_index` ON `person`;
+ALTER TABLE `person` DROP FOREIGN KEY `person_tenant_id_foreign`;
+ALTER TABLE `person` DROP COLUMN `tenant_id`;
+
+DROP TABLE `tenant`;
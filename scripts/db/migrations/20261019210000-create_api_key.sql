@@ -0,0 +1,34 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `api_key` (
+  `id` VARCHAR(64) NOT NULL,
+  `tenant_id` VARCHAR(64) NOT NULL,
+  `name` VARCHAR(255) NOT NULL,
+  `key_hash` CHAR(64) NOT NULL,
+  `scopes` VARCHAR(255) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `revoked_at` TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `api_key_key_hash_unique` (`key_hash`),
+  KEY `api_key_tenant_id_created_at_index` (`tenant_id`, `created_at`),
+  CONSTRAINT `api_key_tenant_id_foreign` FOREIGN KEY (`tenant_id`) REFERENCES `tenant`(`id`)
+);
+
+INSERT INTO `api_key` (`id`, `tenant_id`, `name`, `key_hash`, `scopes`)
+  SELECT CONCAT('apikey_', UUID()), `id`, 'tenant key', `api_key_hash`, 'packs:read,packs:write,events:write'
+  FROM `tenant`
+  WHERE `api_key_hash` IS NOT NULL;
+
+ALTER TABLE `tenant` DROP INDEX `tenant_api_key_hash_unique`, DROP COLUMN `api_key_hash`;
+
+-- +migrate Down
+ALTER TABLE `tenant` ADD COLUMN `api_key_hash` CHAR(64) NULL DEFAULT NULL AFTER `holiday_country`,
+  ADD UNIQUE KEY `tenant_api_key_hash_unique` (`api_key_hash`);
+
+UPDATE `tenant` SET `api_key_hash` = (
+  SELECT `key_hash` FROM `api_key`
+  WHERE `api_key`.`tenant_id` = `tenant`.`id` AND `api_key`.`revoked_at` IS NULL
+  ORDER BY `api_key`.`created_at`
+  LIMIT 1
+);
+
+DROP TABLE `api_key`;
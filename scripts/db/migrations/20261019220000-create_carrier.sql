@@ -0,0 +1,28 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `carrier` (
+  `id` VARCHAR(255) NOT NULL,
+  `tenant_id` VARCHAR(64) NOT NULL,
+  `name` VARCHAR(255) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `carrier_tenant_id_name_unique` (`tenant_id`, `name`),
+  CONSTRAINT `carrier_tenant_id_foreign` FOREIGN KEY (`tenant_id`) REFERENCES `tenant`(`id`)
+);
+
+ALTER TABLE `pack` ADD COLUMN `carrier_id` VARCHAR(255) NULL DEFAULT NULL AFTER `overdue_since`,
+  ADD COLUMN `tracking_number` VARCHAR(64) NULL DEFAULT NULL AFTER `carrier_id`,
+  ADD CONSTRAINT `pack_carrier_id_foreign` FOREIGN KEY (`carrier_id`) REFERENCES `carrier`(`id`),
+  ADD UNIQUE KEY `pack_carrier_id_tracking_number_unique` (`carrier_id`, `tracking_number`);
+
+ALTER TABLE `api_key` ADD COLUMN `carrier_id` VARCHAR(255) NULL DEFAULT NULL AFTER `tenant_id`,
+  ADD CONSTRAINT `api_key_carrier_id_foreign` FOREIGN KEY (`carrier_id`) REFERENCES `carrier`(`id`);
+
+-- +migrate Down
+ALTER TABLE `api_key` DROP FOREIGN KEY `api_key_carrier_id_foreign`;
+ALTER TABLE `api_key` DROP COLUMN `carrier_id`;
+
+ALTER TABLE `pack` DROP FOREIGN KEY `pack_carrier_id_foreign`;
+ALTER TABLE `pack` DROP INDEX `pack_carrier_id_tracking_number_unique`, DROP COLUMN `tracking_number`, DROP COLUMN `carrier_id`;
+
+DROP TABLE `carrier`;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE `pack` ADD COLUMN `tracking_code` CHAR(12) NULL DEFAULT NULL AFTER `tenant_id`,
+  ADD UNIQUE KEY `pack_tracking_code_unique` (`tracking_code`);
+
+-- +migrate Down
+ALTER TABLE `pack` DROP INDEX `pack_tracking_code_unique`, DROP COLUMN `tracking_code`;
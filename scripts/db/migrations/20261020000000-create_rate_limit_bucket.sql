@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `rate_limit_bucket` (
+  `key` VARCHAR(255) NOT NULL,
+  `tokens` DOUBLE NOT NULL,
+  `updated_at` DATETIME(6) NOT NULL,
+  `expires_at` DATETIME(6) NOT NULL,
+  PRIMARY KEY (`key`),
+  INDEX `rate_limit_bucket_expires_at_index` (`expires_at`)
+);
+
+-- +migrate Down
+DROP TABLE `rate_limit_bucket`;
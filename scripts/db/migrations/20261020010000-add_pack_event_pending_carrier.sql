@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE `pack_event_pending` ADD COLUMN `carrier_id` VARCHAR(255) NULL DEFAULT NULL AFTER `pack_id`;
+
+-- +migrate Down
+ALTER TABLE `pack_event_pending` DROP COLUMN `carrier_id`;
@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE `pack_event_pending` ADD COLUMN `claimed_by` VARCHAR(255) NULL DEFAULT NULL AFTER `date`,
+  ADD COLUMN `claimed_at` DATETIME(6) NULL DEFAULT NULL AFTER `claimed_by`;
+CREATE INDEX `pack_event_pending_claimed_by_index` ON `pack_event_pending` (`claimed_by`);
+
+-- +migrate Down
+DROP INDEX `pack_event_pending_claimed_by_index` ON `pack_event_pending`;
+ALTER TABLE `pack_event_pending` DROP COLUMN `claimed_at`, DROP COLUMN `claimed_by`;
@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX `job_run_started_at_index` ON `job_run` (`started_at`);
+
+-- +migrate Down
+DROP INDEX `job_run_started_at_index` ON `job_run`;
@@ -18,10 +18,10 @@ func CreateDatabase(cfg *config.Config) string {
 	DBName = strings.ReplaceAll(DBName, "-", "_")
 
 	bunDB, err := database.NewDatabase(&database.Params{
-		DBHost:     cfg.DBHost,
-		DBPort:     cfg.DBPort,
-		DBUser:     cfg.DBUser,
-		DBPassword: cfg.DBPassword,
+		DBHost:     cfg.Database.Host,
+		DBPort:     cfg.Database.Port,
+		DBUser:     cfg.Database.User,
+		DBPassword: cfg.Database.Password,
 	}).Connect()
 	if err != nil {
 		panic(err)
@@ -2,10 +2,12 @@ package helpers
 
 import (
 	"os"
+	"pack-management/internal/pkg/cerrors"
 	"pack-management/internal/pkg/config"
 	"pack-management/internal/pkg/database"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/uptrace/bun"
 )
 
@@ -13,23 +15,25 @@ func Setup() (*bun.DB, *fiber.App, func()) {
 	os.Setenv("GO_ENV", "test")
 	os.Setenv("BUNDEBUG", "2")
 
-	cfg, err := config.NewConfig()
+	cfg, err := config.NewConfig(&config.Params{})
 	if err != nil {
 		panic(err)
 	}
 
 	app := fiber.New(fiber.Config{
-		AppName: "test",
+		AppName:      "test",
+		ErrorHandler: cerrors.Handler,
 	})
+	app.Use(recover.New())
 
 	DBName := CreateDatabase(cfg)
 
 	bunDB, err := database.NewDatabase(&database.Params{
-		DBHost:     cfg.DBHost,
-		DBPort:     cfg.DBPort,
+		DBHost:     cfg.Database.Host,
+		DBPort:     cfg.Database.Port,
 		DBName:     DBName,
-		DBUser:     cfg.DBUser,
-		DBPassword: cfg.DBPassword,
+		DBUser:     cfg.Database.User,
+		DBPassword: cfg.Database.Password,
 	}).Connect()
 	if err != nil {
 		panic("connecting db: " + err.Error())
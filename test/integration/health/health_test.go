@@ -0,0 +1,47 @@
+package health_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pack-management/internal/domain/health"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLive(t *testing.T) {
+	t.Run("Shoud return the liveness report with only liveness checks", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/livez", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		report := health.Report{}
+		err = json.NewDecoder(resp.Body).Decode(&report)
+		assert.Nil(t, err)
+
+		assert.Equal(t, health.StatusUp, report.Status)
+		assert.Len(t, report.Checks, 1)
+		assert.Equal(t, health.StatusUp, report.Checks["packevent_worker"].Status)
+	})
+}
+
+func TestReady(t *testing.T) {
+	t.Run("Shoud return the readiness report degraded by the optional check", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		report := health.Report{}
+		err = json.NewDecoder(resp.Body).Decode(&report)
+		assert.Nil(t, err)
+
+		assert.Equal(t, health.StatusDegraded, report.Status)
+		assert.Len(t, report.Checks, 4)
+		assert.Equal(t, health.StatusUp, report.Checks["database"].Status)
+		assert.Equal(t, health.StatusUp, report.Checks["migrations"].Status)
+		assert.Equal(t, health.StatusUp, report.Checks["packevent_worker"].Status)
+		assert.Equal(t, health.StatusDown, report.Checks["dogapi"].Status)
+		assert.NotEmpty(t, report.Checks["dogapi"].Error)
+	})
+}
@@ -0,0 +1,150 @@
+package health_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/domain/carrier"
+	"pack-management/internal/domain/health"
+	"pack-management/internal/domain/holiday"
+	"pack-management/internal/domain/pack"
+	"pack-management/internal/domain/packevent"
+	"pack-management/internal/domain/person"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/helpers"
+	"pack-management/internal/pkg/http/client"
+	"pack-management/internal/pkg/http/dogapi"
+	"pack-management/internal/pkg/http/nagerdateapi"
+	"pack-management/internal/pkg/pagination"
+	testhelpers "pack-management/test/helpers"
+	"testing"
+	"time"
+)
+
+var (
+	shutdownServer func()
+	clientApp      func(req *http.Request) (*http.Response, error)
+
+	dogApiURL       = "http://dogapidog:1000"
+	negerDateAPIURL = "http://datenagerat:1000"
+	cursorSecret    = "integration-tests-cursor-secret-0123456789"
+)
+
+func beforeAll() {
+	ctx := context.Background()
+	bunDB, app, shutdown := testhelpers.Setup()
+	shutdownServer = shutdown
+
+	baseClient := client.NewClient()
+	dogAPIClient := dogapi.NewDogAPIClient(baseClient, dogApiURL)
+	nagerDateAPIClient := nagerdateapi.NewHolidayAPIClient(baseClient, negerDateAPIURL)
+
+	holidayRepo := holiday.NewMysqlRepository(&holiday.RepositoryParams{
+		DB: bunDB,
+	})
+	holidaySvc := holiday.NewService(&holiday.ServiceParams{
+		Repo:   holidayRepo,
+		Client: nagerDateAPIClient,
+	})
+
+	app.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats"}, auth.Middleware(&auth.MiddlewareParams{
+		Service: auth.NewService(&auth.ServiceParams{
+			Repo: auth.NewMysqlRepository(&auth.RepositoryParams{
+				DB: bunDB,
+			}),
+		}),
+	}))
+
+	tenantSvc := tenant.NewService(&tenant.ServiceParams{
+		Repo: tenant.NewMysqlRepository(&tenant.RepositoryParams{
+			DB: bunDB,
+		}),
+	})
+	app.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats"}, tenant.Middleware(&tenant.MiddlewareParams{
+		Service:       tenantSvc,
+		DefaultTenant: "default",
+		HeaderEnabled: true,
+	}))
+
+	carrierSvc := carrier.NewService(&carrier.ServiceParams{
+		Repo: carrier.NewMysqlRepository(&carrier.RepositoryParams{
+			DB: bunDB,
+		}),
+	})
+
+	personRepo := person.NewMysqlRepository(&person.RepositoryParams{
+		DB: bunDB,
+	})
+	personSvc := person.NewService(&person.ServiceParams{
+		Repo: personRepo,
+	})
+
+	packRepo := pack.NewMysqlRepository(&pack.RepositoryParams{
+		DB: bunDB,
+		CursorSigner: pagination.NewSigner(&pagination.SignerParams{
+			Secret: cursorSecret,
+			TTL:    time.Hour,
+		}),
+	})
+	packSvc := pack.NewService(&pack.ServiceParams{
+		Repo:           packRepo,
+		PersonService:  personSvc,
+		DogAPIClient:   dogAPIClient,
+		HolidayService: holidaySvc,
+		TenantService:  tenantSvc,
+		CarrierService: carrierSvc,
+	})
+
+	packeventRepo := packevent.NewMysqlRepository(&packevent.RepositoryParams{
+		DB: bunDB,
+	})
+	packeventSvc := packevent.NewService(ctx, &packevent.ServiceParams{
+		Repo:        packeventRepo,
+		PackService: packSvc,
+	})
+
+	rootDir, _ := helpers.GetRootDirectory()
+
+	health.NewHTPPHandler(&health.HandlerParams{
+		App: app,
+		Service: health.NewService(&health.ServiceParams{
+			Checks: []*health.Check{
+				{
+					Name: "database",
+					Run:  health.DatabaseCheck(bunDB),
+				},
+				{
+					Name: "migrations",
+					Run:  health.MigrationCheck(bunDB, "migrations", rootDir+"scripts/db/migrations"),
+				},
+				{
+					Name:     "packevent_worker",
+					Run:      health.WorkerCheck(packeventSvc.LastHeartbeat, time.Minute),
+					Liveness: true,
+				},
+				{
+					Name:     "dogapi",
+					Run:      health.HTTPCheck(http.DefaultClient, dogApiURL),
+					Optional: true,
+				},
+			},
+		}),
+	})
+
+	clientApp = func(req *http.Request) (*http.Response, error) {
+		return app.Test(req, -1)
+	}
+}
+
+func AfterAll() {
+	shutdownServer()
+}
+
+func TestMain(m *testing.M) {
+	beforeAll()
+	code := m.Run()
+	AfterAll()
+
+	os.Exit(code)
+}
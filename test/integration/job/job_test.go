@@ -0,0 +1,435 @@
+package job_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pack-management/internal/domain/job"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/scheduler"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerJob(t *testing.T) {
+	t.Run("Shoud run the job and save it in the history", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodPost, "/admin/jobs/test_succeeded/run", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		respJSON := job.RunJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.NotEmpty(t, respJSON.ID)
+		assert.Equal(t, "test_succeeded", respJSON.Job)
+		assert.Equal(t, scheduler.TriggerManual, respJSON.Trigger)
+		assert.Equal(t, "integration-tests", respJSON.Instance)
+		assert.Equal(t, scheduler.StatusRunning, respJSON.Status)
+		assert.Nil(t, respJSON.ScheduledAt)
+
+		run := waitFinished(t, "test_succeeded", respJSON.ID)
+		assert.Equal(t, scheduler.StatusSucceeded, run.Status)
+		assert.Nil(t, run.Error)
+		assert.NotNil(t, run.FinishedAt)
+	})
+
+	t.Run("Shoud save the error of a failed job", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodPost, "/admin/jobs/test_failed/run", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		respJSON := job.RunJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		run := waitFinished(t, "test_failed", respJSON.ID)
+		assert.Equal(t, scheduler.StatusFailed, run.Status)
+		assert.NotNil(t, run.Error)
+		assert.Equal(t, "job error", *run.Error)
+	})
+
+	t.Run("Shoud fail when the job is already running", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodPost, "/admin/jobs/test_slow/run", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		runJSON := job.RunJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&runJSON)
+		assert.Nil(t, err)
+
+		resp, err = clientApp(httptest.NewRequest(http.MethodPost, "/admin/jobs/test_slow/run", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		respJSON := cerrors.Error{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, scheduler.ErrJobRunning.Code, respJSON.Code)
+
+		releaseSlow <- struct{}{}
+
+		run := waitFinished(t, "test_slow", runJSON.ID)
+		assert.Equal(t, scheduler.StatusSucceeded, run.Status)
+	})
+
+	t.Run("Shoud fail when the job doesn't exist", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodPost, "/admin/jobs/unknown/run", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		respJSON := cerrors.Error{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, scheduler.ErrJobNotFound.Code, respJSON.Code)
+	})
+}
+
+func TestListJobs(t *testing.T) {
+	t.Run("Shoud list the jobs with the next and last runs", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodPost, "/admin/jobs/test_succeeded/run", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		runJSON := job.RunJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&runJSON)
+		assert.Nil(t, err)
+		waitFinished(t, "test_succeeded", runJSON.ID)
+
+		resp, err = clientApp(httptest.NewRequest(http.MethodGet, "/admin/jobs", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON := job.ListJobsJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.Len(t, respJSON.Items, 3)
+		assert.Equal(t, "test_failed", respJSON.Items[0].Name)
+		assert.Equal(t, "test_slow", respJSON.Items[1].Name)
+		assert.Equal(t, "@every 24h", respJSON.Items[1].Schedule)
+		assert.Equal(t, "test_succeeded", respJSON.Items[2].Name)
+		assert.NotNil(t, respJSON.Items[2].LastRun)
+		assert.Equal(t, runJSON.ID, respJSON.Items[2].LastRun.ID)
+	})
+
+	t.Run("Shoud fail listing the runs of an unknown job", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/admin/jobs/unknown/runs", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Shoud fail listing the runs with an invalid limit", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/admin/jobs/test_succeeded/runs?limit=1000", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestScheduledJob(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Shoud run the job on the schedule ticks", func(t *testing.T) {
+		runs := atomic.Int64{}
+		jobScheduler := newScheduler(t, "replica-a", &scheduler.Job{
+			Name:     "test_scheduled",
+			Schedule: "@every 1s",
+			Run: func(ctx context.Context) error {
+				runs.Add(1)
+				return nil
+			},
+		})
+
+		jobScheduler.Start(ctx)
+
+		assert.Eventually(t, func() bool {
+			return runs.Load() >= 2
+		}, 5*time.Second, 50*time.Millisecond)
+
+		history := listRuns(t, "test_scheduled")
+		assert.GreaterOrEqual(t, len(history), 2)
+
+		for _, run := range history {
+			assert.Equal(t, scheduler.TriggerSchedule, run.Trigger)
+			assert.Equal(t, "replica-a", run.Instance)
+			assert.NotNil(t, run.ScheduledAt)
+			// the @every slots are aligned to the clock
+			assert.Equal(t, run.ScheduledAt.Truncate(time.Second), *run.ScheduledAt)
+		}
+	})
+
+	t.Run("Shoud skip the slots already run by another replica", func(t *testing.T) {
+		runs := atomic.Int64{}
+		jobScheduler := newScheduler(t, "replica-a", &scheduler.Job{
+			Name:     "test_slot_skip",
+			Schedule: "@every 1s",
+			Run: func(ctx context.Context) error {
+				runs.Add(1)
+				return nil
+			},
+		})
+
+		// the next slots are run by another replica
+		next := time.Now().Truncate(time.Second).Add(time.Second)
+		skipped := map[time.Time]bool{}
+
+		for i := range 3 {
+			scheduledAt := next.Add(time.Duration(i) * time.Second)
+			skipped[scheduledAt.UTC()] = true
+
+			err := jobStore.Create(ctx, &scheduler.Run{
+				Job:         "test_slot_skip",
+				Trigger:     scheduler.TriggerSchedule,
+				Instance:    "replica-b",
+				Status:      scheduler.StatusSucceeded,
+				ScheduledAt: &scheduledAt,
+				StartedAt:   time.Now(),
+			})
+			assert.Nil(t, err)
+		}
+
+		jobScheduler.Start(ctx)
+
+		assert.Eventually(t, func() bool {
+			return runs.Load() >= 1
+		}, 6*time.Second, 50*time.Millisecond)
+
+		slots := map[time.Time]int{}
+		for _, run := range listRuns(t, "test_slot_skip") {
+			slot := run.ScheduledAt.UTC()
+			slots[slot]++
+
+			if skipped[slot] {
+				assert.Equal(t, "replica-b", run.Instance)
+			} else {
+				assert.Equal(t, "replica-a", run.Instance)
+			}
+		}
+
+		for slot, count := range slots {
+			assert.Equal(t, 1, count, slot)
+		}
+	})
+
+	t.Run("Shoud run each slot once among the replicas", func(t *testing.T) {
+		runs := atomic.Int64{}
+		newJob := func() *scheduler.Job {
+			return &scheduler.Job{
+				Name:     "test_replicas",
+				Schedule: "@every 1s",
+				Run: func(ctx context.Context) error {
+					runs.Add(1)
+					return nil
+				},
+			}
+		}
+
+		replicaA := newScheduler(t, "replica-a", newJob())
+		replicaB := newScheduler(t, "replica-b", newJob())
+
+		replicaA.Start(ctx)
+		replicaB.Start(ctx)
+
+		time.Sleep(3500 * time.Millisecond)
+
+		replicaA.Shutdown(ctx)
+		replicaB.Shutdown(ctx)
+
+		history := listRuns(t, "test_replicas")
+		assert.GreaterOrEqual(t, len(history), 3)
+		assert.Equal(t, int64(len(history)), runs.Load())
+
+		slots := map[time.Time]int{}
+		for _, run := range history {
+			slots[run.ScheduledAt.UTC()]++
+		}
+
+		assert.Len(t, slots, len(history))
+	})
+
+	t.Run("Shoud skip the ticks while another replica holds the job lock", func(t *testing.T) {
+		runs := atomic.Int64{}
+		jobScheduler := newScheduler(t, "replica-a", &scheduler.Job{
+			Name:     "test_locked",
+			Schedule: "@every 1s",
+			Run: func(ctx context.Context) error {
+				runs.Add(1)
+				return nil
+			},
+		})
+
+		otherLocker := scheduler.NewMysqlLocker(&scheduler.LockerParams{
+			DB:     db,
+			Prefix: lockPrefix,
+		})
+
+		unlock, locked, err := otherLocker.TryLock(ctx, "test_locked")
+		assert.Nil(t, err)
+		assert.True(t, locked)
+
+		jobScheduler.Start(ctx)
+
+		time.Sleep(2500 * time.Millisecond)
+		assert.Equal(t, int64(0), runs.Load())
+		assert.Empty(t, listRuns(t, "test_locked"))
+
+		_, err = jobScheduler.Trigger(ctx, "test_locked")
+		assert.ErrorIs(t, err, scheduler.ErrJobRunning)
+
+		unlock()
+
+		assert.Eventually(t, func() bool {
+			return runs.Load() >= 1
+		}, 3*time.Second, 50*time.Millisecond)
+	})
+}
+
+func TestLocker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Shoud give the lock to a single locker until it is released", func(t *testing.T) {
+		lockerA := scheduler.NewMysqlLocker(&scheduler.LockerParams{DB: db, Prefix: lockPrefix})
+		lockerB := scheduler.NewMysqlLocker(&scheduler.LockerParams{DB: db, Prefix: lockPrefix})
+
+		unlockA, locked, err := lockerA.TryLock(ctx, "test_lock")
+		assert.Nil(t, err)
+		assert.True(t, locked)
+
+		_, locked, err = lockerB.TryLock(ctx, "test_lock")
+		assert.Nil(t, err)
+		assert.False(t, locked)
+
+		// the lock is held by the connection, not the locker
+		_, locked, err = lockerA.TryLock(ctx, "test_lock")
+		assert.Nil(t, err)
+		assert.False(t, locked)
+
+		unlockA()
+
+		unlockB, locked, err := lockerB.TryLock(ctx, "test_lock")
+		assert.Nil(t, err)
+		assert.True(t, locked)
+
+		unlockB()
+	})
+
+	t.Run("Shoud keep the locks of other prefixes apart", func(t *testing.T) {
+		lockerA := scheduler.NewMysqlLocker(&scheduler.LockerParams{DB: db, Prefix: lockPrefix})
+		lockerB := scheduler.NewMysqlLocker(&scheduler.LockerParams{DB: db, Prefix: "test_other:"})
+
+		unlockA, locked, err := lockerA.TryLock(ctx, "test_lock")
+		assert.Nil(t, err)
+		assert.True(t, locked)
+		defer unlockA()
+
+		unlockB, locked, err := lockerB.TryLock(ctx, "test_lock")
+		assert.Nil(t, err)
+		assert.True(t, locked)
+		defer unlockB()
+	})
+}
+
+func TestPurgeRuns(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Shoud delete the runs older than the retention", func(t *testing.T) {
+		now := time.Now()
+		oldStartedAt := now.Add(-31 * 24 * time.Hour)
+		oldFinishedAt := oldStartedAt.Add(time.Minute)
+
+		oldRun := &scheduler.Run{
+			Job:        "test_purge",
+			Trigger:    scheduler.TriggerManual,
+			Instance:   "integration-tests",
+			Status:     scheduler.StatusSucceeded,
+			StartedAt:  oldStartedAt,
+			FinishedAt: &oldFinishedAt,
+		}
+		recentRun := &scheduler.Run{
+			Job:       "test_purge",
+			Trigger:   scheduler.TriggerManual,
+			Instance:  "integration-tests",
+			Status:    scheduler.StatusRunning,
+			StartedAt: now,
+		}
+
+		for _, run := range []*scheduler.Run{oldRun, recentRun} {
+			err := jobStore.Create(ctx, run)
+			assert.Nil(t, err)
+		}
+
+		purged, err := scheduler.PurgeRuns(ctx, jobStore, 30*24*time.Hour)
+		assert.Nil(t, err)
+		assert.GreaterOrEqual(t, purged, 1)
+
+		history := listRuns(t, "test_purge")
+		assert.Len(t, history, 1)
+		assert.Equal(t, recentRun.ID, history[0].ID)
+	})
+}
+
+// newScheduler creates a replica sharing the locks and the history of the
+// tests scheduler, shut down at the end of the test.
+func newScheduler(t *testing.T, instance string, jobs ...*scheduler.Job) scheduler.Scheduler {
+	t.Helper()
+
+	replica := scheduler.NewScheduler(&scheduler.Params{
+		Locker: scheduler.NewMysqlLocker(&scheduler.LockerParams{
+			DB:     db,
+			Prefix: lockPrefix,
+		}),
+		Store:    jobStore,
+		Instance: instance,
+	})
+
+	for _, job := range jobs {
+		err := replica.Register(job)
+		assert.Nil(t, err)
+	}
+
+	t.Cleanup(func() {
+		replica.Shutdown(context.Background())
+	})
+
+	return replica
+}
+
+func listRuns(t *testing.T, name string) []*scheduler.Run {
+	t.Helper()
+
+	runs, err := jobStore.ListByJob(context.Background(), name, 100)
+	assert.Nil(t, err)
+
+	return runs
+}
+
+func waitFinished(t *testing.T, name, id string) *job.RunJSON {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/admin/jobs/"+name+"/runs?limit=10", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON := job.ListRunsJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		for _, run := range respJSON.Items {
+			if run.ID == id && run.Status != scheduler.StatusRunning {
+				return run
+			}
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("job run %s didn't finish", id)
+	return nil
+}
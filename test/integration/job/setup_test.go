@@ -0,0 +1,117 @@
+package job_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/domain/job"
+	"pack-management/internal/pkg/scheduler"
+	"pack-management/test/helpers"
+	"testing"
+
+	"github.com/uptrace/bun"
+)
+
+var (
+	shutdownServer func()
+	clientApp      func(req *http.Request) (*http.Response, error)
+
+	db           *bun.DB
+	jobStore     scheduler.Store
+	jobScheduler scheduler.Scheduler
+	releaseSlow  chan struct{}
+)
+
+const (
+	lockPrefix = "test_scheduler:"
+)
+
+func beforeAll() {
+	bunDB, app, shutdown := helpers.Setup()
+	shutdownServer = shutdown
+	db = bunDB
+
+	app.Use([]string{"/admin"}, auth.Middleware(&auth.MiddlewareParams{
+		Service: auth.NewService(&auth.ServiceParams{
+			Repo: auth.NewMysqlRepository(&auth.RepositoryParams{
+				DB: bunDB,
+			}),
+		}),
+	}))
+
+	jobStore = scheduler.NewMysqlStore(&scheduler.StoreParams{
+		DB: bunDB,
+	})
+
+	jobScheduler = scheduler.NewScheduler(&scheduler.Params{
+		Locker: scheduler.NewMysqlLocker(&scheduler.LockerParams{
+			DB:     bunDB,
+			Prefix: lockPrefix,
+		}),
+		Store:    jobStore,
+		Instance: "integration-tests",
+	})
+
+	releaseSlow = make(chan struct{})
+
+	jobs := []*scheduler.Job{
+		{
+			Name:     "test_succeeded",
+			Schedule: "0 0 1 1 *",
+			Run: func(ctx context.Context) error {
+				return nil
+			},
+		},
+		{
+			Name:     "test_failed",
+			Schedule: "0 0 1 1 *",
+			Run: func(ctx context.Context) error {
+				return errors.New("job error")
+			},
+		},
+		{
+			Name:     "test_slow",
+			Schedule: "@every 24h",
+			Run: func(ctx context.Context) error {
+				select {
+				case <-releaseSlow:
+				case <-ctx.Done():
+				}
+
+				return nil
+			},
+		},
+	}
+
+	for _, j := range jobs {
+		err := jobScheduler.Register(j)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	job.NewHTPPHandler(&job.HandlerParams{
+		App:       app,
+		Scheduler: jobScheduler,
+	})
+
+	clientApp = func(req *http.Request) (*http.Response, error) {
+		req.Header.Set("Content-Type", "application/json")
+		return app.Test(req, -1)
+	}
+}
+
+func AfterAll() {
+	jobScheduler.Shutdown(context.Background())
+	shutdownServer()
+}
+
+func TestMain(m *testing.M) {
+	beforeAll()
+	code := m.Run()
+	AfterAll()
+
+	os.Exit(code)
+}
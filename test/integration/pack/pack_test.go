@@ -2,15 +2,35 @@ package pack_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/domain/carrier"
 	"pack-management/internal/domain/pack"
+	"pack-management/internal/domain/packevent"
+	"pack-management/internal/domain/person"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/alert"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/idempotency"
+	"pack-management/internal/pkg/trackingcode"
+	"pack-management/internal/pkg/validator"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/h2non/gock"
+	"github.com/parquet-go/parquet-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/xuri/excelize/v2"
 )
 
 func TestCreatePack(t *testing.T) {
@@ -90,6 +110,49 @@ func TestCreatePack(t *testing.T) {
 		resp, err := clientApp(httptest.NewRequest(http.MethodPost, "/packs", bytes.NewBuffer(body)))
 		assert.Nil(t, err)
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, cerrors.ContentTypeProblem, resp.Header.Get("Content-Type"))
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "validation_failed", errJSON.Code)
+		assert.Equal(t, "urn:pack-management:error:validation_failed", errJSON.Type)
+		assert.Equal(t, http.StatusBadRequest, errJSON.Status)
+		assert.Equal(t, "/packs", errJSON.Instance)
+		assert.Equal(t, map[string]string{
+			"description":             "required",
+			"sender":                  "required",
+			"recipient":               "required",
+			"estimated_delivery_date": "required",
+		}, fieldRules(errJSON.Errors))
+
+		for _, fieldError := range errJSON.Errors {
+			assert.Equal(t, "is required", fieldError.Message)
+		}
+	})
+
+	t.Run("Shoud return error when the body is malformed", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodPost, "/packs", bytes.NewBuffer([]byte(`{`))))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "invalid_body", errJSON.Code)
+	})
+
+	t.Run("Shoud return a problem when the route is unknown", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, cerrors.ContentTypeProblem, resp.Header.Get("Content-Type"))
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "not_found", errJSON.Code)
+		assert.Equal(t, http.StatusNotFound, errJSON.Status)
 	})
 }
 
@@ -255,9 +318,77 @@ func TestListPacks(t *testing.T) {
 
 		assert.Len(t, respPage3JSON.Items, 1)
 		assert.Equal(t, 1, respPage3JSON.Metadata.PageSize)
-		assert.Equal(t, respPage1JSON.Metadata.NextCursor, respPage3JSON.Metadata.NextCursor)
+		assert.NotEmpty(t, respPage3JSON.Metadata.NextCursor)
 		assert.Empty(t, respPage3JSON.Metadata.PrevCursor)
 		assert.Equal(t, respPage1JSON.Items[0].ID, respPage3JSON.Items[0].ID)
+
+		respPage4, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?page_size=1&page_cursor="+(respPage3JSON.Metadata.NextCursor),
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, respPage4.StatusCode)
+
+		respPage4JSON := pack.ListPackJSON{}
+		err = json.NewDecoder(respPage4.Body).Decode(&respPage4JSON)
+		assert.Nil(t, err)
+
+		assert.Len(t, respPage4JSON.Items, 1)
+		assert.Equal(t, respPage2JSON.Items[0].ID, respPage4JSON.Items[0].ID)
+	})
+
+	t.Run("Shoud return error when page_cursor is tampered", func(t *testing.T) {
+		respPage1, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?page_size=1",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, respPage1.StatusCode)
+
+		respPage1JSON := pack.ListPackJSON{}
+		err = json.NewDecoder(respPage1.Body).Decode(&respPage1JSON)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, respPage1JSON.Metadata.NextCursor)
+
+		tamperedCursor := "x" + respPage1JSON.Metadata.NextCursor[1:]
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?page_size=1&page_cursor="+tamperedCursor,
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "invalid_cursor", errJSON.Code)
+	})
+
+	t.Run("Shoud return error when page_cursor is reused with other filters", func(t *testing.T) {
+		respPage1, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?page_size=1",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, respPage1.StatusCode)
+
+		respPage1JSON := pack.ListPackJSON{}
+		err = json.NewDecoder(respPage1.Body).Decode(&respPage1JSON)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, respPage1JSON.Metadata.NextCursor)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?page_size=1&sender_name=test_sender&page_cursor="+respPage1JSON.Metadata.NextCursor,
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
 	t.Run("Shoud list packs successfully with sender_name filter", func(t *testing.T) {
@@ -305,6 +436,331 @@ func TestListPacks(t *testing.T) {
 	})
 }
 
+func TestListPacksFilters(t *testing.T) {
+	t.Run("Shoud list packs successfully with status filter", func(t *testing.T) {
+		createdPack := createPack(t, &createPackParams{SenderName: "status_filter_sender"})
+		inTransitPack := createPack(t, &createPackParams{SenderName: "status_filter_sender"})
+		updatePackStatus(t, inTransitPack.ID, pack.StatusInTransit)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?sender_name=status_filter_sender&status=IN_TRANSIT",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON := pack.ListPackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.Len(t, respJSON.Items, 1)
+		assert.Equal(t, inTransitPack.ID, respJSON.Items[0].ID)
+
+		resp, err = clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?sender_name=status_filter_sender&status=CREATED,IN_TRANSIT",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON = pack.ListPackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.Len(t, respJSON.Items, 2)
+		assert.Equal(t, inTransitPack.ID, respJSON.Items[0].ID)
+		assert.Equal(t, createdPack.ID, respJSON.Items[1].ID)
+	})
+
+	t.Run("Shoud list packs successfully with estimated delivery range and sort", func(t *testing.T) {
+		laterPack := createPack(t, &createPackParams{SenderName: "range_filter_sender", EstimatedDeliveryDate: "2025-05-20"})
+		earlierPack := createPack(t, &createPackParams{SenderName: "range_filter_sender", EstimatedDeliveryDate: "2025-05-10"})
+		createPack(t, &createPackParams{SenderName: "range_filter_sender", EstimatedDeliveryDate: "2025-06-01"})
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?sender_name=range_filter_sender&estimated_delivery_from=2025-05-01&estimated_delivery_to=2025-05-20&sort=estimated_delivery_date&order=asc",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON := pack.ListPackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.Len(t, respJSON.Items, 2)
+		assert.Equal(t, earlierPack.ID, respJSON.Items[0].ID)
+		assert.Equal(t, laterPack.ID, respJSON.Items[1].ID)
+	})
+
+	t.Run("Shoud list packs successfully with overdue filter", func(t *testing.T) {
+		overduePack := createPack(t, &createPackParams{SenderName: "overdue_filter_sender", EstimatedDeliveryDate: "2020-01-10"})
+		createPack(t, &createPackParams{SenderName: "overdue_filter_sender", EstimatedDeliveryDate: time.Now().AddDate(1, 0, 0).Format(time.DateOnly)})
+
+		listOverdue := func(t *testing.T) pack.ListPackJSON {
+			resp, err := clientApp(httptest.NewRequest(
+				http.MethodGet,
+				"/packs?sender_name=overdue_filter_sender&overdue=true",
+				nil,
+			))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respJSON := pack.ListPackJSON{}
+			err = json.NewDecoder(resp.Body).Decode(&respJSON)
+			assert.Nil(t, err)
+
+			return respJSON
+		}
+
+		// only the packs marked by the overdue detection are listed
+		assert.Empty(t, listOverdue(t).Items)
+
+		_, err := packService.DetectOverdue(tenant.WithSystem(context.Background()))
+		assert.Nil(t, err)
+
+		respJSON := listOverdue(t)
+		assert.Len(t, respJSON.Items, 1)
+		assert.Equal(t, overduePack.ID, respJSON.Items[0].ID)
+	})
+
+	t.Run("Shoud list packs successfully with text filter", func(t *testing.T) {
+		textPack := createPack(t, &createPackParams{SenderName: "text_filter_sender", Description: "Cadeira de escritório"})
+		createPack(t, &createPackParams{SenderName: "text_filter_sender", Description: "Livros para entrega"})
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?sender_name=text_filter_sender&q=cadeira",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON := pack.ListPackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.Len(t, respJSON.Items, 1)
+		assert.Equal(t, textPack.ID, respJSON.Items[0].ID)
+	})
+
+	t.Run("Shoud return error when filters are invalid", func(t *testing.T) {
+		for _, query := range []string{
+			"status=LOST",
+			"created_from=2025-13-01",
+			"created_from=2025-02-01&created_to=2025-01-01",
+			"sort=description",
+			"order=up",
+		} {
+			resp, err := clientApp(httptest.NewRequest(
+				http.MethodGet,
+				"/packs?"+query,
+				nil,
+			))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		}
+	})
+}
+
+func TestListPacksIncludes(t *testing.T) {
+	t.Run("Shoud list packs successfully with total and facets", func(t *testing.T) {
+		createPack(t, &createPackParams{SenderName: "facets_sender_a", RecipientName: "facets_recipient"})
+		createPack(t, &createPackParams{SenderName: "facets_sender_a", RecipientName: "facets_recipient"})
+		inTransitPack := createPack(t, &createPackParams{SenderName: "facets_sender_b", RecipientName: "facets_recipient"})
+		updatePackStatus(t, inTransitPack.ID, pack.StatusInTransit)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?recipient_name=facets_recipient&page_size=1&include=total,facets",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON := pack.ListPackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.Len(t, respJSON.Items, 1)
+		assert.NotNil(t, respJSON.Metadata.Total)
+		assert.Equal(t, 3, *respJSON.Metadata.Total)
+		assert.NotNil(t, respJSON.Facets)
+		assert.Equal(t, 2, respJSON.Facets.Status[pack.StatusCreated])
+		assert.Equal(t, 1, respJSON.Facets.Status[pack.StatusInTransit])
+		assert.Equal(t, 0, respJSON.Facets.Status[pack.StatusDelivered])
+		assert.Len(t, respJSON.Facets.Sender, 2)
+		assert.Equal(t, "facets_sender_a", respJSON.Facets.Sender[0].Value)
+		assert.Equal(t, 2, respJSON.Facets.Sender[0].Count)
+		assert.Equal(t, "facets_sender_b", respJSON.Facets.Sender[1].Value)
+		assert.Equal(t, 1, respJSON.Facets.Sender[1].Count)
+	})
+
+	t.Run("Shoud list packs successfully with total only", func(t *testing.T) {
+		createPack(t, &createPackParams{SenderName: "total_sender"})
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?sender_name=total_sender&include=total",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON := pack.ListPackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.NotNil(t, respJSON.Metadata.Total)
+		assert.Equal(t, 1, *respJSON.Metadata.Total)
+		assert.Nil(t, respJSON.Facets)
+	})
+
+	t.Run("Shoud return error when include is invalid", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs?include=everything",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestDetectOverdue(t *testing.T) {
+	t.Run("Shoud mark overdue packs and fire alerts successfully", func(t *testing.T) {
+		overduePack := createPack(t, &createPackParams{SenderName: "overdue_sender", EstimatedDeliveryDate: "2020-02-10"})
+		onTimePack := createPack(t, &createPackParams{SenderName: "overdue_sender", EstimatedDeliveryDate: time.Now().AddDate(1, 0, 0).Format(time.DateOnly)})
+
+		detected, err := packService.DetectOverdue(tenant.WithSystem(context.Background()))
+		assert.Nil(t, err)
+		assert.GreaterOrEqual(t, detected, 1)
+
+		overdueJSON := getPack(t, overduePack.ID)
+		assert.NotNil(t, overdueJSON.OverdueSince)
+
+		onTimeJSON := getPack(t, onTimePack.ID)
+		assert.Nil(t, onTimeJSON.OverdueSince)
+
+		var overdueAlert *alert.Alert
+		for _, firedAlert := range alertSink.Alerts() {
+			if firedAlert.Labels["pack_id"] == overduePack.ID {
+				overdueAlert = firedAlert
+			}
+		}
+
+		assert.NotNil(t, overdueAlert)
+		assert.Equal(t, "pack_overdue", overdueAlert.Name)
+		assert.Equal(t, "overdue_sender", overdueAlert.Labels["sender"])
+		assert.Equal(t, "2020-02-10", overdueAlert.Labels["estimated_delivery_date"])
+	})
+
+	t.Run("Shoud fire the overdue alert only once", func(t *testing.T) {
+		overduePack := createPack(t, &createPackParams{SenderName: "overdue_once_sender", EstimatedDeliveryDate: "2020-02-10"})
+
+		_, err := packService.DetectOverdue(tenant.WithSystem(context.Background()))
+		assert.Nil(t, err)
+
+		_, err = packService.DetectOverdue(tenant.WithSystem(context.Background()))
+		assert.Nil(t, err)
+
+		fired := 0
+		for _, firedAlert := range alertSink.Alerts() {
+			if firedAlert.Labels["pack_id"] == overduePack.ID {
+				fired++
+			}
+		}
+
+		assert.Equal(t, 1, fired)
+	})
+
+	t.Run("Shoud fail the detection without the system context", func(t *testing.T) {
+		_, err := packService.DetectOverdue(context.Background())
+		assert.ErrorIs(t, err, tenant.ErrMissingTenant)
+	})
+
+	t.Run("Shoud keep the overdue mark on an update of the pack read before it", func(t *testing.T) {
+		overduePack := createPack(t, &createPackParams{SenderName: "overdue_update_sender", EstimatedDeliveryDate: "2020-02-10"})
+		ctx := tenant.WithContext(context.Background(), defaultTenant)
+
+		readPack, err := packService.GetPackByID(ctx, overduePack.ID, false)
+		assert.Nil(t, err)
+		assert.Nil(t, readPack.OverdueSince)
+
+		_, err = packService.DetectOverdue(tenant.WithSystem(context.Background()))
+		assert.Nil(t, err)
+
+		// the mark doesn't change the version, so the update isn't a conflict
+		readPack.Status = pack.StatusInTransit
+		err = packRepository.UpdateByID(ctx, overduePack.ID, readPack)
+		assert.Nil(t, err)
+
+		assert.NotNil(t, getPack(t, overduePack.ID).OverdueSince)
+
+		_, err = packService.DetectOverdue(tenant.WithSystem(context.Background()))
+		assert.Nil(t, err)
+
+		fired := 0
+		for _, firedAlert := range alertSink.Alerts() {
+			if firedAlert.Labels["pack_id"] == overduePack.ID {
+				fired++
+			}
+		}
+
+		assert.Equal(t, 1, fired)
+	})
+
+	t.Run("Shoud fire the overdue alert only once on concurrent detections", func(t *testing.T) {
+		overduePack := createPack(t, &createPackParams{SenderName: "overdue_concurrent_sender", EstimatedDeliveryDate: "2020-02-10"})
+
+		errs := make(chan error, 2)
+		for range 2 {
+			go func() {
+				_, err := packService.DetectOverdue(tenant.WithSystem(context.Background()))
+				errs <- err
+			}()
+		}
+
+		for range 2 {
+			assert.Nil(t, <-errs)
+		}
+
+		fired := 0
+		for _, firedAlert := range alertSink.Alerts() {
+			if firedAlert.Labels["pack_id"] == overduePack.ID {
+				fired++
+			}
+		}
+
+		assert.Equal(t, 1, fired)
+	})
+}
+
+func getPack(t *testing.T, packID string) pack.PackJSON {
+	resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/packs/"+packID, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	packJSON := pack.PackJSON{}
+	err = json.NewDecoder(resp.Body).Decode(&packJSON)
+	assert.Nil(t, err)
+
+	return packJSON
+}
+
+func updatePackStatus(t *testing.T, packID string, status pack.Status) {
+	resp, err := clientApp(httptest.NewRequest(
+		http.MethodPatch,
+		"/packs/"+packID,
+		bytes.NewBuffer([]byte(`{"status": "`+status.String()+`"}`)),
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
 func TestUpdatePackStatus(t *testing.T) {
 	t.Run("Shoud update a pack status from CREATED to IN_TRANSIT successfully", func(t *testing.T) {
 		createdPack := createPack(t, nil)
@@ -413,14 +869,16 @@ func TestUpdatePackStatus(t *testing.T) {
 	})
 }
 
-func TestCancelPack(t *testing.T) {
-	t.Run("Shoud cancel a pack successfully", func(t *testing.T) {
+func TestUpdatePackDetails(t *testing.T) {
+	t.Run("Shoud update the informed details successfully", func(t *testing.T) {
 		createdPack := createPack(t, nil)
 
 		resp, err := clientApp(httptest.NewRequest(
-			http.MethodPost,
-			"/packs/"+createdPack.ID+"/cancel",
-			nil,
+			http.MethodPatch,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{
+				"description": "Livros e revistas para entrega"
+			}`)),
 		))
 		assert.Nil(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
@@ -429,36 +887,1618 @@ func TestCancelPack(t *testing.T) {
 		err = json.NewDecoder(resp.Body).Decode(&packJSON)
 		assert.Nil(t, err)
 
-		assert.NotEmpty(t, packJSON.ID)
-		assert.Equal(t, createdPack.Description, packJSON.Description)
+		assert.Equal(t, createdPack.ID, packJSON.ID)
+		assert.Equal(t, "Livros e revistas para entrega", packJSON.Description)
 		assert.Equal(t, createdPack.SenderName, packJSON.SenderName)
 		assert.Equal(t, createdPack.ReceiverName, packJSON.ReceiverName)
-		assert.Equal(t, "CANCELED", packJSON.Status.String())
-		assert.NotEmpty(t, packJSON.CreatedAt)
-		assert.NotEmpty(t, packJSON.UpdateAt)
-		assert.Empty(t, packJSON.DeliveredAt)
-		assert.NotEmpty(t, packJSON.CanceledAt)
-		assert.Empty(t, packJSON.Events)
+		assert.Equal(t, createdPack.EstimatedDeliveryDate, packJSON.EstimatedDeliveryDate)
+		assert.Equal(t, "CREATED", packJSON.Status.String())
 	})
 
-	t.Run("Shoud return error when try to cancel a in transit pack", func(t *testing.T) {
-		createdPack := createPack(t, nil)
+	t.Run("Shoud replace the details and re-run the holiday check", func(t *testing.T) {
+		defer gock.Off()
 
-		resp, err := clientApp(httptest.NewRequest(
-			http.MethodPatch,
-			"/packs/"+createdPack.ID,
-			bytes.NewBuffer([]byte(`{
-				"status": "IN_TRANSIT"
-			}`)),
-		))
-		assert.Nil(t, err)
-		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		createdPack := createPack(t, nil)
+		nextYear := strconv.Itoa(time.Now().Year() + 1)
 
-		resp, err = clientApp(httptest.NewRequest(
-			http.MethodPost,
-			"/packs/"+createdPack.ID+"/cancel",
-			nil,
-		))
+		gock.New(negerDateAPIURL).
+			Get("/PublicHolidays/" + nextYear + "/BR").
+			Reply(http.StatusOK).
+			JSON(`[
+				{
+					"date": "` + nextYear + `-01-01",
+					"localName": "Confraternização Universal",
+					"name": "New Year's Day",
+					"countryCode": "BR",
+					"fixed": false,
+					"global": true,
+					"counties": null,
+					"launchYear": null,
+					"types": [
+						"Public"
+					]
+				}
+			]`)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPut,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{
+				"description": "Livros para entrega",
+				"recipient": "Maria Souza",
+				"estimated_delivery_date": "`+nextYear+`-01-01"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		packJSON := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+
+		assert.Equal(t, "Maria Souza", packJSON.ReceiverName)
+		assert.Equal(t, nextYear+"-01-01", packJSON.EstimatedDeliveryDate)
+		assert.Nil(t, packJSON.IsHoliday)
+
+		time.Sleep(100 * time.Millisecond) // wait for the holiday check
+		assert.True(t, gock.IsDone())
+
+		updatedPack := getPack(t, createdPack.ID)
+		assert.Equal(t, "Maria Souza", updatedPack.ReceiverName)
+		assert.NotNil(t, updatedPack.IsHoliday)
+		assert.True(t, *updatedPack.IsHoliday)
+	})
+
+	t.Run("Shoud keep the holiday check pending when the holidays API fails", func(t *testing.T) {
+		defer gock.Off()
+
+		createdPack := createPack(t, nil)
+		year := strconv.Itoa(time.Now().Year() + 2)
+
+		gock.New(negerDateAPIURL).
+			Get("/PublicHolidays/" + year + "/BR").
+			Persist().
+			Reply(http.StatusInternalServerError)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{
+				"estimated_delivery_date": "`+year+`-01-01"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		time.Sleep(100 * time.Millisecond) // wait for the holiday check
+
+		// is_holiday stays NULL, so the enrichment retry job checks it again
+		updatedPack := getPack(t, createdPack.ID)
+		assert.Equal(t, year+"-01-01", updatedPack.EstimatedDeliveryDate)
+		assert.Nil(t, updatedPack.IsHoliday)
+	})
+
+	t.Run("Shoud keep the holiday flag on an update of the pack read before the check", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		ctx := tenant.WithContext(context.Background(), defaultTenant)
+
+		readPack, err := packService.GetPackByID(ctx, createdPack.ID, false)
+		assert.Nil(t, err)
+
+		// the holiday check finishes after the pack was read
+		readPack.IsHoliday = nil
+		err = packRepository.UpdateIsHolidayByID(ctx, createdPack.ID, readPack.EstimatedDeliveryDate, true)
+		assert.Nil(t, err)
+
+		readPack.Status = pack.StatusInTransit
+		err = packRepository.UpdateByID(ctx, createdPack.ID, readPack)
+		assert.Nil(t, err)
+
+		updatedPack := getPack(t, createdPack.ID)
+		assert.Equal(t, pack.StatusInTransit, updatedPack.Status)
+		assert.NotNil(t, updatedPack.IsHoliday)
+		assert.True(t, *updatedPack.IsHoliday)
+	})
+
+	t.Run("Shoud drop the holiday check of a previous estimated delivery date", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		ctx := tenant.WithContext(context.Background(), defaultTenant)
+
+		err := packRepository.UpdateIsHolidayByID(ctx, createdPack.ID, createdPack.EstimatedDeliveryDate, false)
+		assert.Nil(t, err)
+
+		// the check of a date changed meanwhile finishes last
+		err = packRepository.UpdateIsHolidayByID(ctx, createdPack.ID, "2020-01-01", true)
+		assert.Nil(t, err)
+
+		updatedPack := getPack(t, createdPack.ID)
+		assert.NotNil(t, updatedPack.IsHoliday)
+		assert.False(t, *updatedPack.IsHoliday)
+	})
+
+	t.Run("Shoud update the estimated delivery date of a pack in transit", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		updatePackStatus(t, createdPack.ID, pack.StatusInTransit)
+
+		tomorrow := time.Now().AddDate(0, 0, 1).Format(time.DateOnly)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{
+				"estimated_delivery_date": "`+tomorrow+`"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		packJSON := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+
+		assert.Equal(t, tomorrow, packJSON.EstimatedDeliveryDate)
+		assert.Equal(t, "IN_TRANSIT", packJSON.Status.String())
+	})
+
+	t.Run("Shoud return error when the field is not editable in the status", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		updatePackStatus(t, createdPack.ID, pack.StatusInTransit)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{
+				"recipient": "Maria Souza"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, pack.ErrDetailsNotEditable.Code, errJSON.Code)
+	})
+
+	t.Run("Shoud return error when the pack is delivered", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		updatePackStatus(t, createdPack.ID, pack.StatusInTransit)
+		updatePackStatus(t, createdPack.ID, pack.StatusDelivered)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{
+				"description": "Livros e revistas para entrega"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, pack.ErrDetailsNotEditable.Code, errJSON.Code)
+	})
+
+	t.Run("Shoud return error when the estimated delivery date is in the past", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{
+				"estimated_delivery_date": "2020-01-01"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, pack.ErrEstimatedDeliveryDateInPast.Code, errJSON.Code)
+	})
+
+	t.Run("Shoud return the invalid fields", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{
+				"description": "",
+				"estimated_delivery_date": "02/04/2025"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "invalid_details", errJSON.Code)
+		assert.Equal(t, map[string]string{
+			"description":             "min",
+			"estimated_delivery_date": "datetime",
+		}, fieldRules(errJSON.Errors))
+	})
+
+	t.Run("Shoud return error when no field is informed", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Shoud return error when replacing without all fields", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPut,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{
+				"description": "Livros para entrega"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{
+			"recipient":               "required",
+			"estimated_delivery_date": "required",
+		}, fieldRules(errJSON.Errors))
+	})
+
+	t.Run("Shoud return error when pack not found", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/pack_not_found_1/details",
+			bytes.NewBuffer([]byte(`{
+				"description": "Livros para entrega"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestPackVersion(t *testing.T) {
+	t.Run("Shoud return the pack version as ETag", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		assert.Equal(t, 1, createdPack.Version)
+
+		time.Sleep(100 * time.Millisecond) // wait for the enrichment
+
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/packs/"+createdPack.ID, nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"1"`, resp.Header.Get("ETag"))
+
+		req := httptest.NewRequest(http.MethodGet, "/packs/"+createdPack.ID, nil)
+		req.Header.Set("If-None-Match", `"1"`)
+
+		resp, err = clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	})
+
+	t.Run("Shoud update the pack when If-Match is the current version", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		req := httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID,
+			bytes.NewBuffer([]byte(`{"status": "IN_TRANSIT"}`)),
+		)
+		req.Header.Set("If-Match", `"1"`)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+		packJSON := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, packJSON.Version)
+	})
+
+	t.Run("Shoud return error when If-Match is an old version", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		updatePackStatus(t, createdPack.ID, pack.StatusInTransit)
+
+		req := httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID,
+			bytes.NewBuffer([]byte(`{"status": "DELIVERED"}`)),
+		)
+		req.Header.Set("If-Match", `"1"`)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, pack.ErrVersionConflict.Code, errJSON.Code)
+		assert.Equal(t, map[string]any{"current_version": float64(2)}, errJSON.Metadata)
+
+		currentPack := getPack(t, createdPack.ID)
+		assert.Equal(t, pack.StatusInTransit, currentPack.Status)
+	})
+
+	t.Run("Shoud return error when canceling an old version", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		req := httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID+"/details",
+			bytes.NewBuffer([]byte(`{"description": "Livros e revistas para entrega"}`)),
+		)
+		req.Header.Set("If-Match", `"1"`)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		req = httptest.NewRequest(http.MethodPost, "/packs/"+createdPack.ID+"/cancel", nil)
+		req.Header.Set("If-Match", `"1"`)
+
+		resp, err = clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+		currentPack := getPack(t, createdPack.ID)
+		assert.Equal(t, pack.StatusCreated, currentPack.Status)
+	})
+
+	t.Run("Shoud return error when If-Match is invalid", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/packs/"+createdPack.ID+"/cancel", nil)
+		req.Header.Set("If-Match", "1")
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	})
+
+	t.Run("Shoud apply only one of the concurrent updates", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		updatePackStatus(t, createdPack.ID, pack.StatusInTransit)
+
+		statuses := make(chan int, 2)
+		for range 2 {
+			go func() {
+				req := httptest.NewRequest(
+					http.MethodPatch,
+					"/packs/"+createdPack.ID,
+					bytes.NewBuffer([]byte(`{"status": "DELIVERED"}`)),
+				)
+				req.Header.Set("If-Match", `"2"`)
+
+				resp, err := clientApp(req)
+				assert.Nil(t, err)
+				statuses <- resp.StatusCode
+			}()
+		}
+
+		codes := []int{<-statuses, <-statuses}
+		assert.ElementsMatch(t, []int{http.StatusOK, http.StatusPreconditionFailed}, codes)
+		assert.Equal(t, 3, getPack(t, createdPack.ID).Version)
+	})
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	t.Run("Shoud replay the pack creation with the same key", func(t *testing.T) {
+		defer gock.Off()
+
+		gock.New(dogApiURL).
+			Get("/facts").
+			MatchParam("limit", "1").
+			Reply(http.StatusOK).
+			JSON(`{"data": []}`)
+
+		body := `{
+			"description": "Livros para entrega",
+			"sender": "Loja Idempotente",
+			"recipient": "João Silva",
+			"estimated_delivery_date": "2025-04-02"
+		}`
+
+		req := httptest.NewRequest(http.MethodPost, "/packs", bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Idempotency-Key", "create-pack-key-1")
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Idempotent-Replayed"))
+
+		createdPack := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&createdPack)
+		assert.Nil(t, err)
+
+		req = httptest.NewRequest(http.MethodPost, "/packs", bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Idempotency-Key", "create-pack-key-1")
+
+		resp, err = clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+		assert.Equal(t, `"1"`, resp.Header.Get("ETag"))
+
+		replayedPack := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&replayedPack)
+		assert.Nil(t, err)
+		assert.Equal(t, createdPack.ID, replayedPack.ID)
+
+		senderName := "Loja Idempotente"
+		packs, _, err := packService.ListPacks(tenant.WithContext(context.Background(), defaultTenant), &pack.ListFilters{
+			SenderName: &senderName,
+		})
+		assert.Nil(t, err)
+		assert.Len(t, packs, 1)
+	})
+
+	t.Run("Shoud return error when the key is reused with another body", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/packs/"+createdPack.ID+"/cancel", nil)
+		req.Header.Set("Idempotency-Key", "cancel-pack-key-1")
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		otherPack := createPack(t, nil)
+
+		req = httptest.NewRequest(http.MethodPost, "/packs/"+otherPack.ID+"/cancel", nil)
+		req.Header.Set("Idempotency-Key", "cancel-pack-key-1")
+
+		resp, err = clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, idempotency.ErrKeyReused.Code, errJSON.Code)
+
+		currentPack := getPack(t, otherPack.ID)
+		assert.Equal(t, pack.StatusCreated, currentPack.Status)
+	})
+
+	t.Run("Shoud replay the pack cancel error with the same key", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		updatePackStatus(t, createdPack.ID, pack.StatusInTransit)
+
+		for range 2 {
+			req := httptest.NewRequest(http.MethodPost, "/packs/"+createdPack.ID+"/cancel", nil)
+			req.Header.Set("Idempotency-Key", "cancel-pack-key-2")
+
+			resp, err := clientApp(req)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		}
+	})
+
+	t.Run("Shoud replay the event creation with the same key", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		body := `{
+			"pack_id": "` + createdPack.ID + `",
+			"description": "Pacote chegou ao centro de distribuição",
+			"location": "Centro de Distribuição São Paulo",
+			"date": "2025-01-20T15:13:59Z"
+		}`
+
+		for range 2 {
+			req := httptest.NewRequest(http.MethodPost, "/pack_events", bytes.NewBuffer([]byte(body)))
+			req.Header.Set("Idempotency-Key", "create-event-key-1")
+
+			resp, err := clientApp(req)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		}
+
+		time.Sleep(10 * time.Millisecond) // wait for processing
+
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/packs/"+createdPack.ID+"?with_events=true", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		packJSON := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+		assert.Len(t, packJSON.Events, 1)
+	})
+
+	t.Run("Shoud return error when the key is too long", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/packs", bytes.NewBuffer([]byte(`{}`)))
+		req.Header.Set("Idempotency-Key", strings.Repeat("k", 256))
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Shoud reclaim an abandoned key only once on concurrent retries", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Now()
+		key := "abandoned-" + strconv.FormatInt(now.UnixNano(), 10)
+
+		err := idempotencyStore.Reserve(ctx, &idempotency.Record{
+			Scope:       defaultTenant,
+			Key:         key,
+			RequestHash: "abandoned",
+			Status:      idempotency.StatusProcessing,
+			CreatedAt:   now.Add(-time.Hour),
+			ExpiresAt:   now.Add(time.Hour),
+		})
+		assert.Nil(t, err)
+
+		reclaimed := make(chan bool, 5)
+		for range 5 {
+			go func() {
+				ok, err := idempotencyStore.Reclaim(ctx, &idempotency.Record{
+					Scope:       defaultTenant,
+					Key:         key,
+					RequestHash: "retry",
+					Status:      idempotency.StatusProcessing,
+					CreatedAt:   time.Now(),
+					ExpiresAt:   time.Now().Add(time.Hour),
+				}, time.Now(), now.Add(-time.Minute))
+				assert.Nil(t, err)
+				reclaimed <- ok
+			}()
+		}
+
+		taken := 0
+		for range 5 {
+			if <-reclaimed {
+				taken++
+			}
+		}
+
+		assert.Equal(t, 1, taken)
+
+		saved, err := idempotencyStore.Get(ctx, defaultTenant, key)
+		assert.Nil(t, err)
+		assert.Equal(t, "retry", saved.RequestHash)
+	})
+
+	t.Run("Shoud not reclaim a key in progress", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Now()
+		key := "in-progress-" + strconv.FormatInt(now.UnixNano(), 10)
+
+		err := idempotencyStore.Reserve(ctx, &idempotency.Record{
+			Scope:       defaultTenant,
+			Key:         key,
+			RequestHash: "in-progress",
+			Status:      idempotency.StatusProcessing,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(time.Hour),
+		})
+		assert.Nil(t, err)
+
+		ok, err := idempotencyStore.Reclaim(ctx, &idempotency.Record{
+			Scope:       defaultTenant,
+			Key:         key,
+			RequestHash: "retry",
+			Status:      idempotency.StatusProcessing,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(time.Hour),
+		}, now, now.Add(-time.Minute))
+		assert.Nil(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestCreatePacksBulk(t *testing.T) {
+	t.Run("Shoud create the valid packs and report the invalid ones", func(t *testing.T) {
+		gock.New(dogApiURL).
+			Get("/facts").
+			Times(2).
+			Reply(http.StatusOK).
+			JSON(`{"data": []}`)
+		defer gock.Off()
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPost,
+			"/packs/bulk",
+			bytes.NewBuffer([]byte(`[
+				{
+					"description": "Livros para entrega",
+					"sender": "Loja Bulk",
+					"recipient": "João Silva",
+					"estimated_delivery_date": "2025-04-02"
+				},
+				{
+					"description": "Revistas para entrega",
+					"sender": "Loja Bulk",
+					"estimated_delivery_date": "02/04/2025"
+				},
+				{
+					"description": "Cadernos para entrega",
+					"sender": "Loja Bulk",
+					"recipient": "Maria Souza",
+					"estimated_delivery_date": "2025-04-03"
+				}
+			]`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		report := pack.BulkReportJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&report)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 3, report.Total)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 1, report.Invalid)
+		assert.Len(t, report.Rows, 3)
+
+		assert.Equal(t, 1, report.Rows[0].Row)
+		assert.Equal(t, pack.BulkRowCreated, report.Rows[0].Status)
+		assert.NotEmpty(t, report.Rows[0].Pack.ID)
+		assert.Equal(t, "Loja Bulk", report.Rows[0].Pack.SenderName)
+		assert.Equal(t, "João Silva", report.Rows[0].Pack.ReceiverName)
+		assert.Equal(t, "CREATED", report.Rows[0].Pack.Status.String())
+
+		assert.Equal(t, 2, report.Rows[1].Row)
+		assert.Equal(t, pack.BulkRowInvalid, report.Rows[1].Status)
+		assert.Nil(t, report.Rows[1].Pack)
+		assert.Equal(t, map[string]string{
+			"recipient":               "required",
+			"estimated_delivery_date": "datetime",
+		}, fieldRules(report.Rows[1].Errors))
+
+		assert.Equal(t, 3, report.Rows[2].Row)
+		assert.Equal(t, pack.BulkRowCreated, report.Rows[2].Status)
+		assert.Equal(t, "Maria Souza", report.Rows[2].Pack.ReceiverName)
+
+		currentPack := getPack(t, report.Rows[2].Pack.ID)
+		assert.Equal(t, "Cadernos para entrega", currentPack.Description)
+	})
+
+	t.Run("Shoud return error when there are too many rows", func(t *testing.T) {
+		rows := make([]string, bulkMaxRows+1)
+		for i := range rows {
+			rows[i] = `{"description": "Livros", "sender": "Loja Bulk", "recipient": "João Silva", "estimated_delivery_date": "2025-04-02"}`
+		}
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPost,
+			"/packs/bulk",
+			bytes.NewBuffer([]byte("["+strings.Join(rows, ",")+"]")),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, pack.ErrTooManyRows.Code, errJSON.Code)
+	})
+
+	t.Run("Shoud return error when the body isn't an array", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodPost, "/packs/bulk", bytes.NewBuffer([]byte(`{}`))))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Shoud not create the persons when the packs fail", func(t *testing.T) {
+		ctx := tenant.WithContext(context.Background(), defaultTenant)
+
+		_, err := packService.CreatePacks(ctx, []*pack.Entity{
+			{
+				Description:           "Livros para entrega",
+				Sender:                &person.Entity{Name: "Loja Rollback"},
+				Receiver:              &person.Entity{Name: "Destinatário Rollback"},
+				EstimatedDeliveryDate: "2025-04-02",
+			},
+			{
+				// longer than the TEXT column, the insert fails
+				Description:           strings.Repeat("a", 70000),
+				Sender:                &person.Entity{Name: "Loja Rollback"},
+				Receiver:              &person.Entity{Name: "Destinatário Rollback"},
+				EstimatedDeliveryDate: "2025-04-02",
+			},
+		})
+		assert.NotNil(t, err)
+
+		count, err := db.NewSelect().
+			Model((*person.Model)(nil)).
+			Where("name IN (?)", bun.In([]string{"Loja Rollback", "Destinatário Rollback"})).
+			Count(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestImportPacks(t *testing.T) {
+	t.Run("Shoud import the packs of a CSV file", func(t *testing.T) {
+		gock.New(dogApiURL).
+			Get("/facts").
+			Times(2).
+			Reply(http.StatusOK).
+			JSON(`{"data": []}`)
+		defer gock.Off()
+
+		csv := "\uFEFFSender,Recipient,Description,Estimated_Delivery_Date\n" +
+			"Loja CSV,João Silva,Livros para entrega,2025-04-02\n" +
+			",,,\n" +
+			"Loja CSV,,Revistas para entrega,2025-04-02\n" +
+			"Loja CSV,Maria Souza,\"Cadernos, canetas\",2025-04-03\n"
+
+		resp, err := uploadImport(t, "packs.csv", []byte(csv))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		report := pack.BulkReportJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&report)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 3, report.Total)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 1, report.Invalid)
+
+		assert.Equal(t, 2, report.Rows[0].Row)
+		assert.Equal(t, pack.BulkRowCreated, report.Rows[0].Status)
+		assert.Equal(t, "Loja CSV", report.Rows[0].Pack.SenderName)
+
+		assert.Equal(t, 4, report.Rows[1].Row)
+		assert.Equal(t, pack.BulkRowInvalid, report.Rows[1].Status)
+		assert.Equal(t, map[string]string{"recipient": "required"}, fieldRules(report.Rows[1].Errors))
+
+		assert.Equal(t, 5, report.Rows[2].Row)
+		assert.Equal(t, "Cadernos, canetas", report.Rows[2].Pack.Description)
+	})
+
+	t.Run("Shoud import the packs of a XLSX file", func(t *testing.T) {
+		gock.New(dogApiURL).
+			Get("/facts").
+			Times(2).
+			Reply(http.StatusOK).
+			JSON(`{"data": []}`)
+		defer gock.Off()
+
+		file := excelize.NewFile()
+		defer file.Close()
+
+		sheet := file.GetSheetName(0)
+		file.SetSheetRow(sheet, "A1", &[]string{"description", "sender", "recipient", "estimated_delivery_date"})
+		file.SetSheetRow(sheet, "A2", &[]string{"Livros para entrega", "Loja XLSX", "João Silva", "2025-04-02"})
+
+		// a date typed in Excel is a serial number displayed as m/d/yy
+		shortDate, err := file.NewStyle(&excelize.Style{NumFmt: 14})
+		assert.Nil(t, err)
+
+		file.SetSheetRow(sheet, "A3", &[]any{"Revistas para entrega", "Loja XLSX", "Maria Souza", time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)})
+		file.SetCellStyle(sheet, "D3", "D3", shortDate)
+
+		content, err := file.WriteToBuffer()
+		assert.Nil(t, err)
+
+		resp, err := uploadImport(t, "packs.xlsx", content.Bytes())
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		report := pack.BulkReportJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&report)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, "Loja XLSX", report.Rows[0].Pack.SenderName)
+		assert.Equal(t, "2025-04-02", report.Rows[0].Pack.EstimatedDeliveryDate)
+		assert.Equal(t, pack.BulkRowCreated, report.Rows[1].Status)
+		assert.Equal(t, "2026-10-21", report.Rows[1].Pack.EstimatedDeliveryDate)
+	})
+
+	t.Run("Shoud return error when a column is missing", func(t *testing.T) {
+		resp, err := uploadImport(t, "packs.csv", []byte("description,sender\nLivros,Loja CSV\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, pack.ErrInvalidImportFile.Code, errJSON.Code)
+	})
+
+	t.Run("Shoud return error when the file format is unsupported", func(t *testing.T) {
+		resp, err := uploadImport(t, "packs.txt", []byte("description"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func uploadImport(t *testing.T, filename string, content []byte) (*http.Response, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", filename)
+	assert.Nil(t, err)
+
+	_, err = part.Write(content)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/packs/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return clientApp(req)
+}
+
+func TestExportPacks(t *testing.T) {
+	firstPack := createPack(t, &createPackParams{SenderName: "export_sender", RecipientName: "Maria Souza"})
+	secondPack := createPack(t, &createPackParams{SenderName: "export_sender", Description: `Cadeira, mesa e \"sofá\"`})
+	thirdPack := createPack(t, &createPackParams{SenderName: "export_sender"})
+	updatePackStatus(t, thirdPack.ID, pack.StatusInTransit)
+	createEvent(t, firstPack.ID)
+
+	t.Run("Shoud export packs as csv through more than one page", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs/export?format=csv&sender_name=export_sender&with_events=true",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="packs.csv"`, resp.Header.Get("Content-Disposition"))
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		assert.Nil(t, err)
+		assert.Len(t, records, 4)
+
+		assert.Equal(t, pack.PackExportRow{}.CSVHeader(), records[0])
+
+		recordsByID := map[string][]string{}
+		for _, record := range records[1:] {
+			recordsByID[record[0]] = record
+		}
+
+		assert.Len(t, recordsByID, 3)
+		assert.Equal(t, "Maria Souza", recordsByID[firstPack.ID][4])
+		assert.Contains(t, recordsByID[firstPack.ID][12], "Centro de Distribuição São Paulo")
+		assert.Equal(t, `Cadeira, mesa e "sofá"`, recordsByID[secondPack.ID][1])
+		assert.Equal(t, "[]", recordsByID[secondPack.ID][12])
+		assert.Equal(t, "IN_TRANSIT", recordsByID[thirdPack.ID][2])
+	})
+
+	t.Run("Shoud export packs as ndjson with the filters", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs/export?format=ndjson&sender_name=export_sender&status=CREATED",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		rows := []pack.PackExportRow{}
+		decoder := json.NewDecoder(resp.Body)
+
+		for decoder.More() {
+			row := pack.PackExportRow{}
+			err = decoder.Decode(&row)
+			assert.Nil(t, err)
+
+			rows = append(rows, row)
+		}
+
+		assert.Len(t, rows, 2)
+		assert.ElementsMatch(t, []string{firstPack.ID, secondPack.ID}, []string{rows[0].ID, rows[1].ID})
+		assert.Nil(t, rows[0].Events)
+		assert.Nil(t, rows[1].Events)
+	})
+
+	t.Run("Shoud export packs as parquet", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs/export?format=parquet&sender_name=export_sender&with_events=true",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.Nil(t, err)
+
+		rows, err := parquet.Read[pack.PackExportRow](bytes.NewReader(body), int64(len(body)))
+		assert.Nil(t, err)
+		assert.Len(t, rows, 3)
+
+		rowsByID := map[string]pack.PackExportRow{}
+		for _, row := range rows {
+			rowsByID[row.ID] = row
+		}
+
+		assert.Equal(t, "export_sender", rowsByID[firstPack.ID].SenderName)
+		assert.Len(t, rowsByID[firstPack.ID].Events, 1)
+		assert.Equal(t, pack.StatusInTransit, rowsByID[thirdPack.ID].Status)
+	})
+
+	t.Run("Shoud export only the header when no pack matches", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/packs/export?sender_name=export_nobody", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{pack.PackExportRow{}.CSVHeader()}, records)
+	})
+
+	t.Run("Shoud return error when the format is unsupported", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/packs/export?format=xml", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Shoud return error when the filters are invalid", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/packs/export?created_from=2025-02-01&created_to=2025-01-01",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, pack.ErrInvalidFilters.Code, errJSON.Code)
+	})
+}
+
+func TestTenantIsolation(t *testing.T) {
+	insertTenant(t, "acme", "US")
+	acmeKey := createAPIKey(t, "acme", auth.ScopePacksRead, auth.ScopePacksWrite)
+
+	createTenantPack := func(t *testing.T, apiKey string, sender string) pack.PackJSON {
+		defer gock.Off()
+
+		gock.New(dogApiURL).
+			Get("/facts").
+			MatchParam("limit", "1").
+			Reply(http.StatusOK).
+			JSON(`{"data": []}`)
+
+		req := httptest.NewRequest(http.MethodPost, "/packs", bytes.NewBuffer([]byte(`{
+			"description": "Livros para entrega",
+			"sender": "`+sender+`",
+			"recipient": "João Silva",
+			"estimated_delivery_date": "2025-04-02"
+		}`)))
+		req.Header.Set(auth.HeaderAPIKey, apiKey)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		packJSON := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+
+		time.Sleep(10 * time.Millisecond) // wait for the enrichment
+		return packJSON
+	}
+
+	t.Run("Shoud not find the pack of another tenant", func(t *testing.T) {
+		acmePack := createTenantPack(t, acmeKey, "Loja Acme")
+
+		req := httptest.NewRequest(http.MethodGet, "/packs/"+acmePack.ID, nil)
+		req.Header.Set(auth.HeaderAPIKey, acmeKey)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = clientApp(httptest.NewRequest(http.MethodGet, "/packs/"+acmePack.ID, nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		req = httptest.NewRequest(http.MethodPost, "/packs/"+acmePack.ID+"/cancel", nil)
+		req.Header.Set(tenant.HeaderTenantID, defaultTenant)
+
+		resp, err = clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Shoud list only the packs of the tenant", func(t *testing.T) {
+		acmePack := createTenantPack(t, acmeKey, "Loja Acme Lista")
+		defaultPack := createPack(t, &createPackParams{SenderName: "Loja Acme Lista"})
+
+		listIDs := func(req *http.Request) []string {
+			resp, err := clientApp(req)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			body := pack.ListPackJSON{}
+			err = json.NewDecoder(resp.Body).Decode(&body)
+			assert.Nil(t, err)
+
+			ids := []string{}
+			for _, p := range body.Items {
+				ids = append(ids, p.ID)
+			}
+
+			return ids
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/packs?sender_name=Loja%20Acme%20Lista", nil)
+		req.Header.Set(tenant.HeaderTenantID, "acme")
+		assert.Equal(t, []string{acmePack.ID}, listIDs(req))
+
+		req = httptest.NewRequest(http.MethodGet, "/packs?sender_name=Loja%20Acme%20Lista", nil)
+		assert.Equal(t, []string{defaultPack.ID}, listIDs(req))
+	})
+
+	t.Run("Shoud ignore the tenant header when the key is bound to a tenant", func(t *testing.T) {
+		acmePack := createTenantPack(t, acmeKey, "Loja Acme Header")
+
+		req := httptest.NewRequest(http.MethodGet, "/packs/"+acmePack.ID, nil)
+		req.Header.Set(auth.HeaderAPIKey, acmeKey)
+		req.Header.Set(tenant.HeaderTenantID, defaultTenant)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Shoud accept the tenant header of an admin token bound to no tenant", func(t *testing.T) {
+		acmePack := createTenantPack(t, acmeKey, "Loja Acme Admin")
+
+		token := signToken(t, jwt.MapClaims{
+			"sub":   "operator",
+			"scope": "admin",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}, jwtSecret)
+
+		req := httptest.NewRequest(http.MethodGet, "/packs/"+acmePack.ID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(tenant.HeaderTenantID, "acme")
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Shoud reject the tenant header of a token without the admin scope", func(t *testing.T) {
+		acmePack := createTenantPack(t, acmeKey, "Loja Acme Reader")
+
+		token := signToken(t, jwt.MapClaims{
+			"sub":   "reader",
+			"scope": "packs:read",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}, jwtSecret)
+
+		req := httptest.NewRequest(http.MethodGet, "/packs/"+acmePack.ID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(tenant.HeaderTenantID, "acme")
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, tenant.ErrTenantHeaderForbidden.Code, errJSON.Code)
+	})
+
+	t.Run("Shoud return error when the tenant does not exist", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/packs", nil)
+		req.Header.Set(tenant.HeaderTenantID, "unknown")
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "tenant_not_found", errJSON.Code)
+	})
+}
+
+func TestAuthorization(t *testing.T) {
+	readKey := createAPIKey(t, defaultTenant, auth.ScopePacksRead)
+	eventsKey := createAPIKey(t, defaultTenant, auth.ScopeEventsWrite)
+	metricsKey := createAPIKey(t, defaultTenant, auth.ScopeMetricsRead)
+
+	assertError := func(t *testing.T, req *http.Request, status int, code string) {
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, status, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, code, errJSON.Code)
+	}
+
+	t.Run("Shoud allow the routes of the key scopes", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/packs/"+createdPack.ID, nil)
+		req.Header.Set(auth.HeaderAPIKey, readKey)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		req = httptest.NewRequest(http.MethodPost, "/pack_events", bytes.NewBuffer([]byte(`{
+			"pack_id": "`+createdPack.ID+`",
+			"description": "Pacote chegou ao centro de distribuição",
+			"location": "Centro de Distribuição São Paulo",
+			"date": "2025-01-20T15:13:59Z"
+		}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(auth.HeaderAPIKey, eventsKey)
+
+		resp, err = clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("Shoud return error when the key lacks the scope", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/packs/"+createdPack.ID+"/cancel", nil)
+		req.Header.Set(auth.HeaderAPIKey, readKey)
+		assertError(t, req, http.StatusForbidden, "insufficient_scope")
+
+		req = httptest.NewRequest(http.MethodGet, "/packs/"+createdPack.ID, nil)
+		req.Header.Set(auth.HeaderAPIKey, eventsKey)
+		assertError(t, req, http.StatusForbidden, "insufficient_scope")
+
+		req = httptest.NewRequest(http.MethodGet, "/packs/"+createdPack.ID, nil)
+		req.Header.Set(auth.HeaderAPIKey, metricsKey)
+		assertError(t, req, http.StatusForbidden, "insufficient_scope")
+	})
+
+	t.Run("Shoud return error when the api key is invalid or revoked", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/packs", nil)
+		req.Header.Set(auth.HeaderAPIKey, "invalid-key")
+		assertError(t, req, http.StatusUnauthorized, "invalid_api_key")
+
+		apiKey, revokedKey, err := authService.CreateAPIKey(context.Background(), &auth.CreateAPIKeyParams{
+			TenantID: defaultTenant,
+			Name:     "integration tests revoked",
+			Scopes:   []auth.Scope{auth.ScopePacksRead},
+		})
+		assert.Nil(t, err)
+
+		req = httptest.NewRequest(http.MethodGet, "/packs", nil)
+		req.Header.Set(auth.HeaderAPIKey, revokedKey)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		err = authService.RevokeAPIKey(context.Background(), apiKey.ID)
+		assert.Nil(t, err)
+
+		req = httptest.NewRequest(http.MethodGet, "/packs", nil)
+		req.Header.Set(auth.HeaderAPIKey, revokedKey)
+		assertError(t, req, http.StatusUnauthorized, "invalid_api_key")
+	})
+
+	t.Run("Shoud authenticate the bearer token", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		token := signToken(t, jwt.MapClaims{
+			"sub":       "integration-tests",
+			"tenant_id": defaultTenant,
+			"scope":     "packs:read",
+			"exp":       time.Now().Add(time.Minute).Unix(),
+		}, jwtSecret)
+
+		req := httptest.NewRequest(http.MethodGet, "/packs/"+createdPack.ID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		req = httptest.NewRequest(http.MethodPost, "/packs/"+createdPack.ID+"/cancel", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		assertError(t, req, http.StatusForbidden, "insufficient_scope")
+	})
+
+	t.Run("Shoud return error when the bearer token is invalid", func(t *testing.T) {
+		tokens := map[string]string{
+			"expired": signToken(t, jwt.MapClaims{
+				"sub":   "integration-tests",
+				"scope": "packs:read",
+				"exp":   time.Now().Add(-time.Minute).Unix(),
+			}, jwtSecret),
+			"wrong secret": signToken(t, jwt.MapClaims{
+				"sub":   "integration-tests",
+				"scope": "packs:read",
+				"exp":   time.Now().Add(time.Minute).Unix(),
+			}, "another-secret-with-at-least-32-characters"),
+			"unknown scope": signToken(t, jwt.MapClaims{
+				"sub":   "integration-tests",
+				"scope": "packs:delete",
+				"exp":   time.Now().Add(time.Minute).Unix(),
+			}, jwtSecret),
+			"malformed": "not-a-token",
+		}
+
+		for name, token := range tokens {
+			req := httptest.NewRequest(http.MethodGet, "/packs", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := clientApp(req)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, name)
+		}
+	})
+}
+
+func TestCarrier(t *testing.T) {
+	assignCarrier := func(t *testing.T, packID string, carrierID string, trackingNumber string) *http.Response {
+		req := httptest.NewRequest(http.MethodPut, "/packs/"+packID+"/carrier", bytes.NewBuffer([]byte(`{
+			"carrier_id": "`+carrierID+`",
+			"tracking_number": "`+trackingNumber+`"
+		}`)))
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+
+		return resp
+	}
+
+	postEvent := func(t *testing.T, packID string, apiKey string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/pack_events", bytes.NewBuffer([]byte(`{
+			"pack_id": "`+packID+`",
+			"description": "Pacote saiu para entrega",
+			"location": "Centro de Distribuição São Paulo",
+			"date": "2025-01-20T15:13:59Z"
+		}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(auth.HeaderAPIKey, apiKey)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+
+		return resp
+	}
+
+	t.Run("Shoud create and list the carriers", func(t *testing.T) {
+		createdCarrier := createCarrier(t, "Transportadora Lista")
+
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/carriers/"+createdCarrier.ID, nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = clientApp(httptest.NewRequest(http.MethodGet, "/carriers", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		carriers := carrier.ListCarrierJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&carriers)
+		assert.Nil(t, err)
+		assert.Contains(t, carriers.Items, &createdCarrier)
+
+		resp, err = clientApp(httptest.NewRequest(http.MethodPost, "/carriers", bytes.NewBuffer([]byte(`{"name": "Transportadora Lista"}`))))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("Shoud assign the pack to the carrier", func(t *testing.T) {
+		createdCarrier := createCarrier(t, "Transportadora Atribuição")
+		createdPack := createPack(t, nil)
+
+		resp := assignCarrier(t, createdPack.ID, createdCarrier.ID, "TR-0001")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		packJSON := pack.PackJSON{}
+		err := json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, createdCarrier.ID, *packJSON.CarrierID)
+		assert.Equal(t, "TR-0001", *packJSON.TrackingNumber)
+		assert.Equal(t, createdPack.Version+1, packJSON.Version)
+
+		otherPack := createPack(t, nil)
+
+		resp = assignCarrier(t, otherPack.ID, createdCarrier.ID, "TR-0001")
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "tracking_number_in_use", errJSON.Code)
+		assert.NotContains(t, errJSON.Detail, "Duplicate entry")
+
+		resp = assignCarrier(t, otherPack.ID, "carrier_unknown", "TR-0002")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON = cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "carrier_not_found", errJSON.Code)
+
+		resp, err = clientApp(httptest.NewRequest(http.MethodPost, "/packs/"+otherPack.ID+"/cancel", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = assignCarrier(t, otherPack.ID, createdCarrier.ID, "TR-0002")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON = cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "carrier_not_assignable", errJSON.Code)
+	})
+
+	t.Run("Shoud only accept the carrier events of its packs", func(t *testing.T) {
+		createdCarrier := createCarrier(t, "Transportadora Eventos")
+		assignedPack := createPack(t, nil)
+		otherPack := createPack(t, nil)
+
+		resp := assignCarrier(t, assignedPack.ID, createdCarrier.ID, "TR-EVENTS-1")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, carrierKey, err := authService.CreateAPIKey(context.Background(), &auth.CreateAPIKeyParams{
+			TenantID:  defaultTenant,
+			CarrierID: createdCarrier.ID,
+			Name:      "Transportadora Eventos",
+			Scopes:    []auth.Scope{auth.ScopeEventsWrite},
+		})
+		assert.Nil(t, err)
+
+		resp = postEvent(t, assignedPack.ID, carrierKey)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		time.Sleep(10 * time.Millisecond) // wait for processing
+
+		resp = postEvent(t, otherPack.ID, carrierKey)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "pack_not_assigned", errJSON.Code)
+
+		resp = postEvent(t, "pack_unknown", carrierKey)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		req := httptest.NewRequest(http.MethodGet, "/packs/"+assignedPack.ID, nil)
+		req.Header.Set(auth.HeaderAPIKey, carrierKey)
+
+		resp, err = clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		req = httptest.NewRequest(http.MethodGet, "/packs/"+assignedPack.ID+"?with_events=true", nil)
+
+		resp, err = clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		packJSON := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+		assert.Len(t, packJSON.Events, 1)
+	})
+
+	t.Run("Shoud drop the queued carrier event of a pack reassigned", func(t *testing.T) {
+		firstCarrier := createCarrier(t, "Transportadora Anterior")
+		secondCarrier := createCarrier(t, "Transportadora Atual")
+		reassignedPack := createPack(t, nil)
+
+		resp := assignCarrier(t, reassignedPack.ID, firstCarrier.ID, "TR-REASSIGNED-1")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = assignCarrier(t, reassignedPack.ID, secondCarrier.ID, "TR-REASSIGNED-2")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// the event was authorized while the pack was assigned to the first
+		// carrier and is created after the reassignment
+		ctx := auth.WithPrincipal(tenant.WithContext(context.Background(), defaultTenant), &auth.Principal{
+			Subject:   "carrier",
+			TenantID:  defaultTenant,
+			CarrierID: firstCarrier.ID,
+			Scopes:    []auth.Scope{auth.ScopeEventsWrite},
+		})
+		packEventService.EnqueueEvent(ctx, &packevent.Entity{
+			PackID:      reassignedPack.ID,
+			Description: "Pacote coletado",
+			Location:    "Centro de Distribuição São Paulo",
+			Date:        time.Now(),
+		})
+
+		time.Sleep(10 * time.Millisecond) // wait for processing
+
+		req := httptest.NewRequest(http.MethodGet, "/packs/"+reassignedPack.ID+"?with_events=true", nil)
+
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		packJSON := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+		assert.Empty(t, packJSON.Events)
+	})
+
+	t.Run("Shoud return error when the carrier key has other scopes", func(t *testing.T) {
+		createdCarrier := createCarrier(t, "Transportadora Escopos")
+
+		_, _, err := authService.CreateAPIKey(context.Background(), &auth.CreateAPIKeyParams{
+			TenantID:  defaultTenant,
+			CarrierID: createdCarrier.ID,
+			Name:      "Transportadora Escopos",
+			Scopes:    []auth.Scope{auth.ScopeEventsWrite, auth.ScopePacksRead},
+		})
+		assert.ErrorIs(t, err, auth.ErrInvalidScope)
+	})
+}
+
+func TestTracking(t *testing.T) {
+	getTracking := func(t *testing.T, code string) *http.Response {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/track/"+code, nil))
+		assert.Nil(t, err)
+
+		return resp
+	}
+
+	t.Run("Shoud create the pack with a tracking code", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		assert.NotNil(t, createdPack.TrackingCode)
+		assert.True(t, strings.HasPrefix(*createdPack.TrackingCode, "BR"))
+		assert.True(t, trackingcode.Valid(*createdPack.TrackingCode))
+	})
+
+	t.Run("Shoud return the customer view of the pack", func(t *testing.T) {
+		createdCarrier := createCarrier(t, "Transportadora Rastreio")
+		createdPack := createPack(t, nil)
+		createEvent(t, createdPack.ID)
+
+		req := httptest.NewRequest(http.MethodPut, "/packs/"+createdPack.ID+"/carrier", bytes.NewBuffer([]byte(`{
+			"carrier_id": "`+createdCarrier.ID+`",
+			"tracking_number": "TR-TRACK-1"
+		}`)))
+		resp, err := clientApp(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// the customers may type the code in lower case with spaces
+		code := strings.ToLower((*createdPack.TrackingCode)[:4] + " " + (*createdPack.TrackingCode)[4:])
+
+		resp = getTracking(t, strings.ReplaceAll(code, " ", "%20"))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.NotContains(t, string(body), createdPack.ID)
+		assert.NotContains(t, string(body), "fun_fact")
+		assert.NotContains(t, string(body), "Loja ABC")
+
+		tracking := pack.TrackingJSON{}
+		err = json.Unmarshal(body, &tracking)
+		assert.Nil(t, err)
+		assert.Equal(t, *createdPack.TrackingCode, tracking.TrackingCode)
+		assert.Equal(t, createdPack.Status, tracking.Status)
+		assert.Equal(t, createdPack.EstimatedDeliveryDate, tracking.EstimatedDeliveryDate)
+		assert.Equal(t, &pack.TrackingCarrierJSON{Name: "Transportadora Rastreio", TrackingNumber: "TR-TRACK-1"}, tracking.Carrier)
+		assert.Len(t, tracking.Events, 1)
+		assert.Equal(t, "Centro de Distribuição São Paulo", tracking.Events[0].Location)
+	})
+
+	t.Run("Shoud return error when the check digit is wrong", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		code := *createdPack.TrackingCode
+
+		checkDigit := (code[len(code)-1]-'0'+1)%10 + '0'
+
+		resp := getTracking(t, code[:len(code)-1]+string(checkDigit))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err := json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "invalid_tracking_code", errJSON.Code)
+
+		resp = getTracking(t, "pack_123")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Shoud return not found when no pack has the code", func(t *testing.T) {
+		code, err := trackingcode.New("ZZ")
+		assert.Nil(t, err)
+
+		resp := getTracking(t, code)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Shoud backfill the tracking code of the older packs", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		_, err := db.ExecContext(context.Background(), "UPDATE pack SET tracking_code = NULL WHERE id = ?", createdPack.ID)
+		assert.Nil(t, err)
+
+		backfilled, err := packService.BackfillTrackingCodes(tenant.WithSystem(context.Background()))
+		assert.Nil(t, err)
+		assert.GreaterOrEqual(t, backfilled, 1)
+
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/packs/"+createdPack.ID, nil))
+		assert.Nil(t, err)
+
+		packJSON := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+		assert.NotNil(t, packJSON.TrackingCode)
+		assert.NotEqual(t, *createdPack.TrackingCode, *packJSON.TrackingCode)
+
+		resp = getTracking(t, *packJSON.TrackingCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestCancelPack(t *testing.T) {
+	t.Run("Shoud cancel a pack successfully", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPost,
+			"/packs/"+createdPack.ID+"/cancel",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		packJSON := pack.PackJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&packJSON)
+		assert.Nil(t, err)
+
+		assert.NotEmpty(t, packJSON.ID)
+		assert.Equal(t, createdPack.Description, packJSON.Description)
+		assert.Equal(t, createdPack.SenderName, packJSON.SenderName)
+		assert.Equal(t, createdPack.ReceiverName, packJSON.ReceiverName)
+		assert.Equal(t, "CANCELED", packJSON.Status.String())
+		assert.NotEmpty(t, packJSON.CreatedAt)
+		assert.NotEmpty(t, packJSON.UpdateAt)
+		assert.Empty(t, packJSON.DeliveredAt)
+		assert.NotEmpty(t, packJSON.CanceledAt)
+		assert.Empty(t, packJSON.Events)
+	})
+
+	t.Run("Shoud return error when try to cancel a in transit pack", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodPatch,
+			"/packs/"+createdPack.ID,
+			bytes.NewBuffer([]byte(`{
+				"status": "IN_TRANSIT"
+			}`)),
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = clientApp(httptest.NewRequest(
+			http.MethodPost,
+			"/packs/"+createdPack.ID+"/cancel",
+			nil,
+		))
 		assert.Nil(t, err)
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
@@ -507,8 +2547,10 @@ func TestCancelPack(t *testing.T) {
 }
 
 type createPackParams struct {
-	SenderName    string
-	RecipientName string
+	SenderName            string
+	RecipientName         string
+	Description           string
+	EstimatedDeliveryDate string
 }
 
 func createPack(t *testing.T, params *createPackParams) pack.PackJSON {
@@ -526,6 +2568,14 @@ func createPack(t *testing.T, params *createPackParams) pack.PackJSON {
 		params.RecipientName = "João Silva"
 	}
 
+	if params.Description == "" {
+		params.Description = "Livros para entrega"
+	}
+
+	if params.EstimatedDeliveryDate == "" {
+		params.EstimatedDeliveryDate = "2025-04-02"
+	}
+
 	gock.New(dogApiURL).
 		Get("/facts").
 		MatchParam("limit", "1").
@@ -546,10 +2596,10 @@ func createPack(t *testing.T, params *createPackParams) pack.PackJSON {
 		http.MethodPost,
 		"/packs",
 		bytes.NewBuffer([]byte(`{
-			"description": "Livros para entrega",
+			"description": "`+params.Description+`",
 			"sender": "`+params.SenderName+`",
 			"recipient": "`+params.RecipientName+`",
-			"estimated_delivery_date": "2025-04-02"
+			"estimated_delivery_date": "`+params.EstimatedDeliveryDate+`"
 		}`)),
 	))
 	assert.Nil(t, err)
@@ -565,6 +2615,49 @@ func createPack(t *testing.T, params *createPackParams) pack.PackJSON {
 	return packJSON
 }
 
+func insertTenant(t *testing.T, id string, holidayCountry string) {
+	_, err := db.ExecContext(
+		context.Background(),
+		"INSERT INTO tenant (id, name, holiday_country) VALUES (?, ?, ?)",
+		id, id, holidayCountry,
+	)
+	assert.Nil(t, err)
+}
+
+func createAPIKey(t *testing.T, tenantID string, scopes ...auth.Scope) string {
+	_, key, err := authService.CreateAPIKey(context.Background(), &auth.CreateAPIKeyParams{
+		TenantID: tenantID,
+		Name:     "integration tests",
+		Scopes:   scopes,
+	})
+	assert.Nil(t, err)
+
+	return key
+}
+
+func signToken(t *testing.T, claims jwt.MapClaims, secret string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	assert.Nil(t, err)
+
+	return token
+}
+
+func createCarrier(t *testing.T, name string) carrier.CarrierJSON {
+	resp, err := clientApp(httptest.NewRequest(
+		http.MethodPost,
+		"/carriers",
+		bytes.NewBuffer([]byte(`{"name": "`+name+`"}`)),
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	carrierJSON := carrier.CarrierJSON{}
+	err = json.NewDecoder(resp.Body).Decode(&carrierJSON)
+	assert.Nil(t, err)
+
+	return carrierJSON
+}
+
 func createEvent(t *testing.T, packID string) {
 	resp, err := clientApp(httptest.NewRequest(
 		http.MethodPost,
@@ -581,3 +2674,12 @@ func createEvent(t *testing.T, packID string) {
 
 	time.Sleep(10 * time.Millisecond) // wait for processing
 }
+
+func fieldRules(fieldErrors []*validator.FieldError) map[string]string {
+	rules := map[string]string{}
+	for _, fieldError := range fieldErrors {
+		rules[fieldError.Field] = fieldError.Rule
+	}
+
+	return rules
+}
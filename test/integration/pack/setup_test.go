@@ -4,17 +4,26 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/domain/carrier"
 	"pack-management/internal/domain/holiday"
 	"pack-management/internal/domain/pack"
 	"pack-management/internal/domain/packevent"
 	"pack-management/internal/domain/person"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/alert"
 	"pack-management/internal/pkg/http/client"
 	"pack-management/internal/pkg/http/dogapi"
 	"pack-management/internal/pkg/http/nagerdateapi"
+	"pack-management/internal/pkg/idempotency"
+	"pack-management/internal/pkg/pagination"
 	"pack-management/test/helpers"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/h2non/gock"
+	"github.com/uptrace/bun"
 )
 
 var (
@@ -22,12 +31,49 @@ var (
 	shutdownServer  func()
 	dogApiURL       = "http://dogapidog:1000"
 	negerDateAPIURL = "http://datenagerat:1000"
+	cursorSecret    = "integration-tests-cursor-secret-0123456789"
+	defaultTenant   = "default"
+	jwtSecret       = "integration-tests-jwt-secret-0123456789"
+
+	packService      pack.Service
+	packRepository   pack.Repository
+	packEventService packevent.Service
+	authService      auth.Service
+	idempotencyStore idempotency.Store
+	db               *bun.DB
+	alertSink        = &recorderSink{}
+	bulkMaxRows      = 10
+	// exportPageSize is small so the exports read more than one page
+	exportPageSize = 2
 )
 
+// recorderSink keeps the fired alerts to be asserted.
+type recorderSink struct {
+	mutex  sync.Mutex
+	alerts []*alert.Alert
+}
+
+func (s *recorderSink) Send(ctx context.Context, firedAlert *alert.Alert) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.alerts = append(s.alerts, firedAlert)
+
+	return nil
+}
+
+func (s *recorderSink) Alerts() []*alert.Alert {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]*alert.Alert{}, s.alerts...)
+}
+
 func beforeAll() {
 	ctx := context.Background()
 	bunDB, app, shutdown := helpers.Setup()
 	shutdownServer = shutdown
+	db = bunDB
 
 	baseClient := client.NewClient()
 	dogAPIClient := dogapi.NewDogAPIClient(baseClient, dogApiURL)
@@ -41,6 +87,37 @@ func beforeAll() {
 		Client: nagerDateAPIClient,
 	})
 
+	authService = auth.NewService(&auth.ServiceParams{
+		Repo: auth.NewMysqlRepository(&auth.RepositoryParams{
+			DB: bunDB,
+		}),
+		JWTSecret: jwtSecret,
+	})
+	app.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats"}, auth.Middleware(&auth.MiddlewareParams{
+		Service: authService,
+	}))
+
+	tenantSvc := tenant.NewService(&tenant.ServiceParams{
+		Repo: tenant.NewMysqlRepository(&tenant.RepositoryParams{
+			DB: bunDB,
+		}),
+	})
+	app.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats"}, tenant.Middleware(&tenant.MiddlewareParams{
+		Service:       tenantSvc,
+		DefaultTenant: defaultTenant,
+		HeaderEnabled: true,
+	}))
+
+	carrierSvc := carrier.NewService(&carrier.ServiceParams{
+		Repo: carrier.NewMysqlRepository(&carrier.RepositoryParams{
+			DB: bunDB,
+		}),
+	})
+	carrier.NewHTPPHandler(&carrier.HandlerParams{
+		Service: carrierSvc,
+		App:     app,
+	})
+
 	personRepo := person.NewMysqlRepository(&person.RepositoryParams{
 		DB: bunDB,
 	})
@@ -50,32 +127,58 @@ func beforeAll() {
 
 	packRepo := pack.NewMysqlRepository(&pack.RepositoryParams{
 		DB: bunDB,
+		CursorSigner: pagination.NewSigner(&pagination.SignerParams{
+			Secret: cursorSecret,
+			TTL:    time.Hour,
+		}),
 	})
 	packSvc := pack.NewService(&pack.ServiceParams{
 		Repo:           packRepo,
 		PersonService:  personSvc,
 		DogAPIClient:   dogAPIClient,
 		HolidayService: holidaySvc,
+		TenantService:  tenantSvc,
+		CarrierService: carrierSvc,
+		AlertSink:      alertSink,
+		ExportPageSize: exportPageSize,
+	})
+	packService = packSvc
+	packRepository = packRepo
+
+	idempotencyStore = idempotency.NewMysqlStore(&idempotency.StoreParams{
+		DB: bunDB,
 	})
+
+	idempotencyMiddleware := idempotency.Middleware(&idempotency.MiddlewareParams{
+		Store: idempotencyStore,
+		Scope: tenant.ScopeKey,
+	})
+
 	pack.NewHTPPHandler(&pack.HandlerParams{
-		Service: packSvc,
-		App:     app,
+		Service:     packSvc,
+		App:         app,
+		Idempotency: idempotencyMiddleware,
+		BulkMaxRows: bulkMaxRows,
 	})
 
 	packeventRepo := packevent.NewMysqlRepository(&packevent.RepositoryParams{
 		DB: bunDB,
 	})
-	packeventSvc := packevent.NewService(ctx, &packevent.ServiceParams{
+	packEventService = packevent.NewService(ctx, &packevent.ServiceParams{
 		Repo:        packeventRepo,
 		PackService: packSvc,
 	})
 	packevent.NewHTPPHandler(&packevent.HandlerParams{
-		Service: packeventSvc,
-		App:     app,
+		Service:     packEventService,
+		App:         app,
+		Idempotency: idempotencyMiddleware,
 	})
 
 	clientApp = func(req *http.Request) (*http.Response, error) {
-		req.Header.Set("Content-Type", "application/json")
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
 		return app.Test(req, -1)
 	}
 }
@@ -2,10 +2,13 @@ package packevent_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"pack-management/internal/domain/pack"
+	"pack-management/internal/domain/packevent"
+	"pack-management/internal/domain/tenant"
 	"testing"
 	"time"
 
@@ -40,6 +43,148 @@ func TestCreatePack(t *testing.T) {
 	})
 }
 
+func TestQueue(t *testing.T) {
+	tenantCtx := tenant.WithContext(context.Background(), "default")
+	systemCtx := tenant.WithSystem(context.Background())
+
+	newService := func() packevent.Service {
+		return packevent.NewService(context.Background(), &packevent.ServiceParams{
+			Repo:            packEventRepo,
+			PackService:     packService,
+			RestoreInterval: time.Hour,
+		})
+	}
+
+	newEvent := func(packID string) *packevent.Entity {
+		return &packevent.Entity{
+			PackID:      packID,
+			Description: "Pacote chegou ao centro de distribuição",
+			Location:    "Centro de Distribuição São Paulo",
+			Date:        time.Now(),
+		}
+	}
+
+	countEvents := func(t *testing.T, packID string) int {
+		eventsPack, err := packService.GetPackByID(tenantCtx, packID, true)
+		assert.Nil(t, err)
+
+		return len(eventsPack.Events)
+	}
+
+	countPending := func(t *testing.T, packID string) int {
+		count, err := db.NewSelect().
+			Model((*packevent.PendingModel)(nil)).
+			Where("pack_id = ?", packID).
+			Count(systemCtx)
+		assert.Nil(t, err)
+
+		return count
+	}
+
+	t.Run("Shoud drain the queued events on shutdown", func(t *testing.T) {
+		packID := createPack(t).ID
+		service := newService()
+
+		for range 20 {
+			service.EnqueueEvent(tenantCtx, newEvent(packID))
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := service.Shutdown(shutdownCtx)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 20, countEvents(t, packID))
+		assert.Equal(t, 0, countPending(t, packID))
+	})
+
+	t.Run("Shoud persist the events enqueued after the shutdown and restore them on the next start", func(t *testing.T) {
+		packID := createPack(t).ID
+		service := newService()
+
+		err := service.Shutdown(context.Background())
+		assert.Nil(t, err)
+
+		service.EnqueueEvent(tenantCtx, newEvent(packID))
+
+		assert.Equal(t, 0, countEvents(t, packID))
+		assert.Equal(t, 1, countPending(t, packID))
+
+		restarted := newService()
+		defer restarted.Shutdown(context.Background())
+
+		time.Sleep(50 * time.Millisecond) // wait for the restore
+
+		assert.Equal(t, 1, countEvents(t, packID))
+		assert.Equal(t, 0, countPending(t, packID))
+	})
+
+	t.Run("Shoud persist the events enqueued while the queue is full", func(t *testing.T) {
+		packID := createPack(t).ID
+		service := packevent.NewService(context.Background(), &packevent.ServiceParams{
+			Repo:            packEventRepo,
+			PackService:     packService,
+			QueueBuffer:     1,
+			RestoreInterval: 10 * time.Millisecond,
+		})
+		defer service.Shutdown(context.Background())
+
+		for range 10 {
+			service.EnqueueEvent(tenantCtx, newEvent(packID))
+		}
+
+		assert.Eventually(t, func() bool {
+			return countEvents(t, packID) == 10
+		}, 2*time.Second, 20*time.Millisecond)
+		assert.Equal(t, 0, countPending(t, packID))
+	})
+
+	t.Run("Shoud claim each pending event once on concurrent restores", func(t *testing.T) {
+		packID := createPack(t).ID
+
+		events := []*packevent.Entity{}
+		for range 10 {
+			event := newEvent(packID)
+			event.TenantID = "default"
+			events = append(events, event)
+		}
+
+		err := packEventRepo.CreatePending(systemCtx, events)
+		assert.Nil(t, err)
+
+		claimed := make(chan []*packevent.Entity, 2)
+		now := time.Now()
+
+		for _, claimID := range []string{"claim_test_a", "claim_test_b"} {
+			defer func() {
+				err := packEventRepo.DeletePendingByClaim(systemCtx, claimID)
+				assert.Nil(t, err)
+			}()
+
+			go func() {
+				events, err := packEventRepo.ClaimPending(systemCtx, claimID, now, now.Add(-time.Minute), 100)
+				assert.Nil(t, err)
+				claimed <- events
+			}()
+		}
+
+		IDs := map[string]int{}
+		for range 2 {
+			for _, event := range <-claimed {
+				if event.PackID == packID {
+					IDs[event.ID]++
+				}
+			}
+		}
+
+		assert.Len(t, IDs, 10)
+		for ID, count := range IDs {
+			assert.Equal(t, 1, count, ID)
+		}
+	})
+}
+
 func createPack(t *testing.T) pack.PackJSON {
 	defer gock.Off()
 
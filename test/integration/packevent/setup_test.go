@@ -4,29 +4,42 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/domain/carrier"
 	"pack-management/internal/domain/holiday"
 	"pack-management/internal/domain/pack"
 	"pack-management/internal/domain/packevent"
 	"pack-management/internal/domain/person"
+	"pack-management/internal/domain/tenant"
 	"pack-management/internal/pkg/http/client"
 	"pack-management/internal/pkg/http/dogapi"
 	"pack-management/internal/pkg/http/nagerdateapi"
+	"pack-management/internal/pkg/pagination"
 	"pack-management/test/helpers"
 	"testing"
+	"time"
+
+	"github.com/uptrace/bun"
 )
 
 var (
 	shutdownServer func()
 	clientApp      func(req *http.Request) (*http.Response, error)
 
+	db            *bun.DB
+	packService   pack.Service
+	packEventRepo packevent.Repository
+
 	dogApiURL       = "http://dogapidog:1000"
 	negerDateAPIURL = "http://datenagerat:1000"
+	cursorSecret    = "integration-tests-cursor-secret-0123456789"
 )
 
 func beforeAll() {
 	ctx := context.Background()
 	bunDB, app, shutdown := helpers.Setup()
 	shutdownServer = shutdown
+	db = bunDB
 
 	baseClient := client.NewClient()
 	dogAPIClient := dogapi.NewDogAPIClient(baseClient, dogApiURL)
@@ -40,6 +53,31 @@ func beforeAll() {
 		Client: nagerDateAPIClient,
 	})
 
+	app.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats"}, auth.Middleware(&auth.MiddlewareParams{
+		Service: auth.NewService(&auth.ServiceParams{
+			Repo: auth.NewMysqlRepository(&auth.RepositoryParams{
+				DB: bunDB,
+			}),
+		}),
+	}))
+
+	tenantSvc := tenant.NewService(&tenant.ServiceParams{
+		Repo: tenant.NewMysqlRepository(&tenant.RepositoryParams{
+			DB: bunDB,
+		}),
+	})
+	app.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats"}, tenant.Middleware(&tenant.MiddlewareParams{
+		Service:       tenantSvc,
+		DefaultTenant: "default",
+		HeaderEnabled: true,
+	}))
+
+	carrierSvc := carrier.NewService(&carrier.ServiceParams{
+		Repo: carrier.NewMysqlRepository(&carrier.RepositoryParams{
+			DB: bunDB,
+		}),
+	})
+
 	personRepo := person.NewMysqlRepository(&person.RepositoryParams{
 		DB: bunDB,
 	})
@@ -49,24 +87,33 @@ func beforeAll() {
 
 	packRepo := pack.NewMysqlRepository(&pack.RepositoryParams{
 		DB: bunDB,
+		CursorSigner: pagination.NewSigner(&pagination.SignerParams{
+			Secret: cursorSecret,
+			TTL:    time.Hour,
+		}),
 	})
 	packSvc := pack.NewService(&pack.ServiceParams{
 		Repo:           packRepo,
 		PersonService:  personSvc,
 		DogAPIClient:   dogAPIClient,
 		HolidayService: holidaySvc,
+		TenantService:  tenantSvc,
+		CarrierService: carrierSvc,
 	})
 	pack.NewHTPPHandler(&pack.HandlerParams{
 		Service: packSvc,
 		App:     app,
 	})
+	packService = packSvc
 
-	packeventRepo := packevent.NewMysqlRepository(&packevent.RepositoryParams{
+	packEventRepo = packevent.NewMysqlRepository(&packevent.RepositoryParams{
 		DB: bunDB,
 	})
+	// the pending events are restored by the tests only
 	packeventSvc := packevent.NewService(ctx, &packevent.ServiceParams{
-		Repo:        packeventRepo,
-		PackService: packSvc,
+		Repo:            packEventRepo,
+		PackService:     packSvc,
+		RestoreInterval: time.Hour,
 	})
 	packevent.NewHTPPHandler(&packevent.HandlerParams{
 		Service: packeventSvc,
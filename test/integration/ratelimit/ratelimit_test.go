@@ -0,0 +1,197 @@
+package ratelimit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pack-management/internal/pkg/cerrors"
+	"pack-management/internal/pkg/ratelimit"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func request(t *testing.T, method string, path string, client string) *http.Response {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(headerClient, client)
+
+	resp, err := clientApp(req)
+	assert.Nil(t, err)
+
+	return resp
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Run("Shoud limit the requests of the client", func(t *testing.T) {
+		for i := range limit.Requests {
+			resp := request(t, http.MethodGet, "/replica_a/resource", "limited")
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+			assert.Equal(t, "3", resp.Header.Get(ratelimit.HeaderLimit))
+			assert.Equal(t, limit.Requests-i-1, atoi(t, resp.Header.Get(ratelimit.HeaderRemaining)))
+			assert.Equal(t, "3;w=60", resp.Header.Get(ratelimit.HeaderPolicy))
+		}
+
+		resp := request(t, http.MethodGet, "/replica_a/resource", "limited")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "0", resp.Header.Get(ratelimit.HeaderRemaining))
+		assert.InDelta(t, 20, atoi(t, resp.Header.Get("Retry-After")), 1)
+		assert.InDelta(t, 60, atoi(t, resp.Header.Get(ratelimit.HeaderReset)), 1)
+
+		errJSON := cerrors.Problem{}
+		err := json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "rate_limited", errJSON.Code)
+		assert.InDelta(t, 20, errJSON.Metadata["retry_after"], 1)
+
+		resp = request(t, http.MethodGet, "/replica_a/resource", "other")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("Shoud limit the route by its rule", func(t *testing.T) {
+		resp := request(t, http.MethodPost, "/replica_a/expensive", "rule")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "1", resp.Header.Get(ratelimit.HeaderLimit))
+
+		resp = request(t, http.MethodPost, "/replica_a/expensive", "rule")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+		// the other methods and routes use the default limit
+		resp = request(t, http.MethodGet, "/replica_a/expensive", "rule")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "3", resp.Header.Get(ratelimit.HeaderLimit))
+	})
+
+	t.Run("Shoud share the limits by the replicas", func(t *testing.T) {
+		for range limit.Requests - 1 {
+			resp := request(t, http.MethodGet, "/replica_a/resource", "shared")
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		}
+
+		resp := request(t, http.MethodGet, "/replica_b/resource", "shared")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "0", resp.Header.Get(ratelimit.HeaderRemaining))
+
+		resp = request(t, http.MethodGet, "/replica_b/resource", "shared")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	})
+
+	t.Run("Shoud keep the buckets of the named limiter apart", func(t *testing.T) {
+		resp := request(t, http.MethodGet, "/named/resource", "named")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// the named bucket is empty, the default one isn't
+		resp = request(t, http.MethodGet, "/named/resource", "named")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "1", resp.Header.Get(ratelimit.HeaderLimit))
+
+		resp = request(t, http.MethodGet, "/replica_a/resource", "named")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, strconv.Itoa(limit.Requests-2), resp.Header.Get(ratelimit.HeaderRemaining))
+	})
+
+	t.Run("Shoud limit the requests with the memory store", func(t *testing.T) {
+		for range limit.Requests {
+			resp := request(t, http.MethodGet, "/memory/resource", "memory")
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		}
+
+		resp := request(t, http.MethodGet, "/memory/resource", "memory")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	})
+
+	t.Run("Shoud refill the bucket over time", func(t *testing.T) {
+		ctx := context.Background()
+		fast := ratelimit.Limit{Requests: 10, Period: time.Second}
+		now := time.Now()
+
+		for range fast.Requests {
+			result, err := mysqlStore.Take(ctx, "test:refill", fast, now)
+			assert.Nil(t, err)
+			assert.True(t, result.Allowed)
+		}
+
+		result, err := mysqlStore.Take(ctx, "test:refill", fast, now)
+		assert.Nil(t, err)
+		assert.False(t, result.Allowed)
+
+		result, err = mysqlStore.Take(ctx, "test:refill", fast, now.Add(250*time.Millisecond))
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+	})
+
+	t.Run("Shoud take each token once on concurrent requests", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Now()
+
+		var (
+			wg      sync.WaitGroup
+			allowed atomic.Int32
+		)
+
+		for range 10 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				result, err := mysqlStore.Take(ctx, "test:concurrent", limit, now)
+				assert.Nil(t, err)
+
+				if err == nil && result.Allowed {
+					allowed.Add(1)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, int32(limit.Requests), allowed.Load())
+	})
+
+	t.Run("Shoud purge the full buckets", func(t *testing.T) {
+		ctx := context.Background()
+		fast := ratelimit.Limit{Requests: 1, Period: time.Second}
+
+		_, err := mysqlStore.Take(ctx, "test:purge", fast, time.Now().Add(-time.Minute))
+		assert.Nil(t, err)
+
+		purged, err := ratelimit.PurgeExpired(ctx, mysqlStore)
+		assert.Nil(t, err)
+		assert.GreaterOrEqual(t, purged, 1)
+	})
+
+	t.Run("Shoud sweep the full buckets of the memory store on take", func(t *testing.T) {
+		ctx := context.Background()
+		fast := ratelimit.Limit{Requests: 1, Period: time.Second}
+		memoryStore := ratelimit.NewMemoryStore()
+		now := time.Now()
+
+		_, err := memoryStore.Take(ctx, "test:full", fast, now.Add(-2*time.Minute))
+		assert.Nil(t, err)
+
+		_, err = memoryStore.Take(ctx, "test:sweep", fast, now)
+		assert.Nil(t, err)
+
+		// the full bucket was swept, the taken one is kept
+		purged, err := ratelimit.PurgeExpired(ctx, memoryStore)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, purged)
+
+		purged, err = memoryStore.DeleteExpired(ctx, now.Add(time.Minute), 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, purged)
+	})
+}
+
+func atoi(t *testing.T, value string) int {
+	number, err := strconv.Atoi(value)
+	assert.Nil(t, err)
+
+	return number
+}
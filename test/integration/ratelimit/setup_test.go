@@ -0,0 +1,98 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"os"
+	"pack-management/internal/pkg/ratelimit"
+	"pack-management/test/helpers"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	shutdownServer func()
+	clientApp      func(req *http.Request) (*http.Response, error)
+
+	mysqlStore ratelimit.Store
+	limit      = ratelimit.Limit{Requests: 3, Period: time.Minute}
+	ruleLimit  = ratelimit.Limit{Requests: 1, Period: time.Hour}
+)
+
+const (
+	headerClient = "X-Client"
+)
+
+func beforeAll() {
+	bunDB, app, shutdown := helpers.Setup()
+	shutdownServer = shutdown
+
+	mysqlStore = ratelimit.NewMysqlStore(&ratelimit.StoreParams{
+		DB: bunDB,
+	})
+
+	// each replica has its own store, they share the buckets in the table
+	otherReplicaStore := ratelimit.NewMysqlStore(&ratelimit.StoreParams{
+		DB: bunDB,
+	})
+
+	rules := []*ratelimit.Rule{
+		{Name: "expensive", Method: fiber.MethodPost, Prefix: "/replica_a/expensive", Limit: ruleLimit},
+	}
+
+	app.Use("/replica_a", ratelimit.Middleware(&ratelimit.MiddlewareParams{
+		Store:   mysqlStore,
+		Default: limit,
+		Rules:   rules,
+		Key:     clientKey,
+	}))
+	app.Use("/replica_b", ratelimit.Middleware(&ratelimit.MiddlewareParams{
+		Store:   otherReplicaStore,
+		Default: limit,
+		Key:     clientKey,
+	}))
+	// a limiter checked before the other ones, e.g.: the IP limit before
+	// the authentication, on the same store
+	app.Use("/named", ratelimit.Middleware(&ratelimit.MiddlewareParams{
+		Store:   mysqlStore,
+		Name:    "named",
+		Default: ruleLimit,
+		Key:     clientKey,
+	}))
+	app.Use("/named", ratelimit.Middleware(&ratelimit.MiddlewareParams{
+		Store:   mysqlStore,
+		Default: limit,
+		Key:     clientKey,
+	}))
+	app.Use("/memory", ratelimit.Middleware(&ratelimit.MiddlewareParams{
+		Store:   ratelimit.NewMemoryStore(),
+		Default: limit,
+		Key:     clientKey,
+	}))
+
+	app.All("/*", func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusNoContent)
+	})
+
+	clientApp = func(req *http.Request) (*http.Response, error) {
+		return app.Test(req, -1)
+	}
+}
+
+// clientKey identifies the client by a header, so each test has its buckets.
+func clientKey(ctx *fiber.Ctx) string {
+	return "client:" + ctx.Get(headerClient)
+}
+
+func AfterAll() {
+	shutdownServer()
+}
+
+func TestMain(m *testing.M) {
+	beforeAll()
+	code := m.Run()
+	AfterAll()
+
+	os.Exit(code)
+}
@@ -0,0 +1,171 @@
+package search_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pack-management/internal/domain/pack"
+	"pack-management/internal/domain/search"
+	"pack-management/internal/pkg/cerrors"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	t.Run("Shoud search packs by description successfully", func(t *testing.T) {
+		createdPack := createPack(t, &createPackParams{Description: "Bicicleta aro 29"})
+
+		respJSON := searchPacks(t, "bicicleta")
+
+		assert.Len(t, respJSON.Items, 1)
+		assert.Equal(t, search.ResultTypePack, respJSON.Items[0].Type)
+		assert.Equal(t, createdPack.ID, respJSON.Items[0].ID)
+		assert.Equal(t, createdPack.ID, respJSON.Items[0].PackID)
+		assert.Greater(t, respJSON.Items[0].Score, float64(0))
+		assert.Equal(t, "<mark>Bicicleta</mark> aro 29", respJSON.Items[0].Highlights[search.FieldDescription])
+	})
+
+	t.Run("Shoud search packs by person name successfully", func(t *testing.T) {
+		createdPack := createPack(t, &createPackParams{SenderName: "Papelaria Girassol"})
+
+		respJSON := searchPacks(t, "girassol")
+
+		assert.Len(t, respJSON.Items, 1)
+		assert.Equal(t, createdPack.ID, respJSON.Items[0].ID)
+		assert.Equal(t, "Papelaria <mark>Girassol</mark>", respJSON.Items[0].Highlights[search.FieldSender])
+		assert.NotContains(t, respJSON.Items[0].Highlights, search.FieldDescription)
+	})
+
+	t.Run("Shoud search events by location successfully", func(t *testing.T) {
+		createdPack := createPack(t, nil)
+		createEvent(t, createdPack.ID, "Centro de Distribuição Florianópolis")
+
+		respJSON := searchPacks(t, "florianópolis")
+
+		assert.Len(t, respJSON.Items, 1)
+		assert.Equal(t, search.ResultTypeEvent, respJSON.Items[0].Type)
+		assert.Equal(t, createdPack.ID, respJSON.Items[0].PackID)
+		assert.Equal(t, "Centro de Distribuição <mark>Florianópolis</mark>", respJSON.Items[0].Highlights[search.FieldLocation])
+	})
+
+	t.Run("Shoud rank the packs matching more fields first", func(t *testing.T) {
+		bothPack := createPack(t, &createPackParams{SenderName: "Tucano Store", Description: "Tucano de pelúcia"})
+		descriptionPack := createPack(t, &createPackParams{Description: "Almofada tucano"})
+
+		respJSON := searchPacks(t, "tucano")
+
+		assert.Len(t, respJSON.Items, 2)
+		assert.Equal(t, bothPack.ID, respJSON.Items[0].ID)
+		assert.Equal(t, descriptionPack.ID, respJSON.Items[1].ID)
+	})
+
+	t.Run("Shoud return error when query has no searchable term", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/search?q=a+b", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errJSON := cerrors.Problem{}
+		err = json.NewDecoder(resp.Body).Decode(&errJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, "invalid_search_query", errJSON.Code)
+	})
+
+	t.Run("Shoud return error when query is missing", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/search", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func searchPacks(t *testing.T, query string) search.SearchJSON {
+	resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape(query), nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respJSON := search.SearchJSON{}
+	err = json.NewDecoder(resp.Body).Decode(&respJSON)
+	assert.Nil(t, err)
+
+	return respJSON
+}
+
+type createPackParams struct {
+	SenderName  string
+	Description string
+}
+
+func createPack(t *testing.T, params *createPackParams) pack.PackJSON {
+	defer gock.Off()
+
+	if params == nil {
+		params = &createPackParams{}
+	}
+
+	if params.SenderName == "" {
+		params.SenderName = "Loja ABC"
+	}
+
+	if params.Description == "" {
+		params.Description = "Livros para entrega"
+	}
+
+	gock.New(dogApiURL).
+		Get("/facts").
+		MatchParam("limit", "1").
+		Reply(http.StatusOK).
+		JSON(`{
+			"data": [
+				{
+					"id": "cb382e94-d7e2-415b-b943-085960f3819a",
+					"type": "fact",
+					"attributes": {
+						"body": "Toto in The Wizard of Oz was played by a female Cairn Terrier named Terry."
+					}
+				}
+			]
+		}`)
+
+	resp, err := clientApp(httptest.NewRequest(
+		http.MethodPost,
+		"/packs",
+		bytes.NewBuffer([]byte(`{
+			"description": "`+params.Description+`",
+			"sender": "`+params.SenderName+`",
+			"recipient": "João Silva",
+			"estimated_delivery_date": "2025-04-02"
+		}`)),
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	packJSON := pack.PackJSON{}
+	err = json.NewDecoder(resp.Body).Decode(&packJSON)
+	assert.Nil(t, err)
+
+	time.Sleep(1 * time.Millisecond) // wait for the gock to finish
+	assert.True(t, gock.IsDone())
+
+	return packJSON
+}
+
+func createEvent(t *testing.T, packID string, location string) {
+	resp, err := clientApp(httptest.NewRequest(
+		http.MethodPost,
+		"/pack_events",
+		bytes.NewBuffer([]byte(`{
+			"pack_id": "`+packID+`",
+			"description": "Pacote chegou ao centro de distribuição",
+			"location": "`+location+`",
+			"date": "2025-01-20T15:13:59Z"
+		}`)),
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	time.Sleep(10 * time.Millisecond) // wait for processing
+}
@@ -0,0 +1,124 @@
+package stats_test
+
+import (
+	"net/http"
+	"os"
+	"pack-management/internal/domain/auth"
+	"pack-management/internal/domain/carrier"
+	"pack-management/internal/domain/holiday"
+	"pack-management/internal/domain/pack"
+	"pack-management/internal/domain/person"
+	"pack-management/internal/domain/stats"
+	"pack-management/internal/domain/tenant"
+	"pack-management/internal/pkg/http/client"
+	"pack-management/internal/pkg/http/dogapi"
+	"pack-management/internal/pkg/http/nagerdateapi"
+	"pack-management/internal/pkg/pagination"
+	"pack-management/test/helpers"
+	"testing"
+	"time"
+)
+
+var (
+	shutdownServer func()
+	clientApp      func(req *http.Request) (*http.Response, error)
+
+	dogApiURL       = "http://dogapidog:1000"
+	negerDateAPIURL = "http://datenagerat:1000"
+	cursorSecret    = "integration-tests-cursor-secret-0123456789"
+)
+
+func beforeAll() {
+	bunDB, app, shutdown := helpers.Setup()
+	shutdownServer = shutdown
+
+	baseClient := client.NewClient()
+	dogAPIClient := dogapi.NewDogAPIClient(baseClient, dogApiURL)
+	nagerDateAPIClient := nagerdateapi.NewHolidayAPIClient(baseClient, negerDateAPIURL)
+
+	holidayRepo := holiday.NewMysqlRepository(&holiday.RepositoryParams{
+		DB: bunDB,
+	})
+	holidaySvc := holiday.NewService(&holiday.ServiceParams{
+		Repo:   holidayRepo,
+		Client: nagerDateAPIClient,
+	})
+
+	app.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats"}, auth.Middleware(&auth.MiddlewareParams{
+		Service: auth.NewService(&auth.ServiceParams{
+			Repo: auth.NewMysqlRepository(&auth.RepositoryParams{
+				DB: bunDB,
+			}),
+		}),
+	}))
+
+	tenantSvc := tenant.NewService(&tenant.ServiceParams{
+		Repo: tenant.NewMysqlRepository(&tenant.RepositoryParams{
+			DB: bunDB,
+		}),
+	})
+	app.Use([]string{"/packs", "/pack_events", "/carriers", "/search", "/stats"}, tenant.Middleware(&tenant.MiddlewareParams{
+		Service:       tenantSvc,
+		DefaultTenant: "default",
+		HeaderEnabled: true,
+	}))
+
+	carrierSvc := carrier.NewService(&carrier.ServiceParams{
+		Repo: carrier.NewMysqlRepository(&carrier.RepositoryParams{
+			DB: bunDB,
+		}),
+	})
+
+	personRepo := person.NewMysqlRepository(&person.RepositoryParams{
+		DB: bunDB,
+	})
+	personSvc := person.NewService(&person.ServiceParams{
+		Repo: personRepo,
+	})
+
+	packRepo := pack.NewMysqlRepository(&pack.RepositoryParams{
+		DB: bunDB,
+		CursorSigner: pagination.NewSigner(&pagination.SignerParams{
+			Secret: cursorSecret,
+			TTL:    time.Hour,
+		}),
+	})
+	packSvc := pack.NewService(&pack.ServiceParams{
+		Repo:           packRepo,
+		PersonService:  personSvc,
+		DogAPIClient:   dogAPIClient,
+		HolidayService: holidaySvc,
+		TenantService:  tenantSvc,
+		CarrierService: carrierSvc,
+	})
+	pack.NewHTPPHandler(&pack.HandlerParams{
+		Service: packSvc,
+		App:     app,
+	})
+
+	stats.NewHTPPHandler(&stats.HandlerParams{
+		App: app,
+		Service: stats.NewService(&stats.ServiceParams{
+			Repo: stats.NewMysqlRepository(&stats.RepositoryParams{
+				DB: bunDB,
+			}),
+		}),
+	})
+
+	clientApp = func(req *http.Request) (*http.Response, error) {
+		req.Header.Set("Content-Type", "application/json")
+		return app.Test(req, -1)
+	}
+}
+
+func AfterAll() {
+	shutdownServer()
+}
+
+func TestMain(m *testing.M) {
+	beforeAll()
+	code := m.Run()
+	AfterAll()
+
+	os.Exit(code)
+}
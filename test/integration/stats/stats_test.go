@@ -0,0 +1,165 @@
+package stats_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pack-management/internal/domain/pack"
+	"pack-management/internal/domain/stats"
+	"pack-management/internal/pkg/cerrors"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDeliveryStats(t *testing.T) {
+	t.Run("Shoud get the delivery stats successfully", func(t *testing.T) {
+		today := time.Now().UTC().Format(time.DateOnly)
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+
+		lateDelivery := createPack(t, "stats_sender", "2025-04-02")
+		updatePackStatus(t, lateDelivery.ID, pack.StatusInTransit)
+		updatePackStatus(t, lateDelivery.ID, pack.StatusDelivered)
+
+		onTimeDelivery := createPack(t, "stats_sender", tomorrow)
+		updatePackStatus(t, onTimeDelivery.ID, pack.StatusInTransit)
+		updatePackStatus(t, onTimeDelivery.ID, pack.StatusDelivered)
+
+		canceledPack := createPack(t, "stats_sender", tomorrow)
+		cancelPack(t, canceledPack.ID)
+
+		createPack(t, "stats_sender", tomorrow)
+		createPack(t, "other_stats_sender", tomorrow)
+
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/stats/deliveries?sender_name=stats_sender&from="+today+"&to="+today,
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON := stats.DeliveryStatsJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.Equal(t, today, respJSON.From)
+		assert.Equal(t, today, respJSON.To)
+		assert.Equal(t, 4, respJSON.Created)
+		assert.Equal(t, 2, respJSON.Delivered)
+		assert.Equal(t, 1, respJSON.Canceled)
+		assert.NotNil(t, respJSON.AverageTransitSeconds)
+		assert.NotNil(t, respJSON.P95TransitSeconds)
+		assert.NotNil(t, respJSON.OnTimeRate)
+		assert.Equal(t, 0.5, *respJSON.OnTimeRate)
+		assert.Len(t, respJSON.Series, 1)
+		assert.Equal(t, today, respJSON.Series[0].Date)
+		assert.Equal(t, 4, respJSON.Series[0].Created)
+		assert.Equal(t, 2, respJSON.Series[0].Delivered)
+		assert.Equal(t, 1, respJSON.Series[0].Canceled)
+	})
+
+	t.Run("Shoud return the empty days of the range", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(
+			http.MethodGet,
+			"/stats/deliveries?sender_name=stats_sender&from=2020-01-01&to=2020-01-07",
+			nil,
+		))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respJSON := stats.DeliveryStatsJSON{}
+		err = json.NewDecoder(resp.Body).Decode(&respJSON)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 0, respJSON.Created)
+		assert.Nil(t, respJSON.AverageTransitSeconds)
+		assert.Nil(t, respJSON.OnTimeRate)
+		assert.Len(t, respJSON.Series, 7)
+		assert.Equal(t, "2020-01-01", respJSON.Series[0].Date)
+		assert.Equal(t, "2020-01-07", respJSON.Series[6].Date)
+	})
+
+	t.Run("Shoud return error when range is invalid", func(t *testing.T) {
+		for _, query := range []string{
+			"from=2020-02-01&to=2020-01-01",
+			"from=2020-01-01&to=2022-01-01",
+		} {
+			resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/stats/deliveries?"+query, nil))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+
+			errJSON := cerrors.Problem{}
+			err = json.NewDecoder(resp.Body).Decode(&errJSON)
+			assert.Nil(t, err)
+			assert.Equal(t, "invalid_range", errJSON.Code)
+		}
+	})
+
+	t.Run("Shoud return error when date is invalid", func(t *testing.T) {
+		resp, err := clientApp(httptest.NewRequest(http.MethodGet, "/stats/deliveries?from=01-01-2020", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func createPack(t *testing.T, senderName string, estimatedDeliveryDate string) pack.PackJSON {
+	defer gock.Off()
+
+	gock.New(dogApiURL).
+		Get("/facts").
+		MatchParam("limit", "1").
+		Reply(http.StatusOK).
+		JSON(`{
+			"data": [
+				{
+					"id": "cb382e94-d7e2-415b-b943-085960f3819a",
+					"type": "fact",
+					"attributes": {
+						"body": "Toto in The Wizard of Oz was played by a female Cairn Terrier named Terry."
+					}
+				}
+			]
+		}`)
+
+	resp, err := clientApp(httptest.NewRequest(
+		http.MethodPost,
+		"/packs",
+		bytes.NewBuffer([]byte(`{
+			"description": "Livros para entrega",
+			"sender": "`+senderName+`",
+			"recipient": "João Silva",
+			"estimated_delivery_date": "`+estimatedDeliveryDate+`"
+		}`)),
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	packJSON := pack.PackJSON{}
+	err = json.NewDecoder(resp.Body).Decode(&packJSON)
+	assert.Nil(t, err)
+
+	time.Sleep(1 * time.Millisecond) // wait for the gock to finish
+	assert.True(t, gock.IsDone())
+
+	return packJSON
+}
+
+func updatePackStatus(t *testing.T, packID string, status pack.Status) {
+	resp, err := clientApp(httptest.NewRequest(
+		http.MethodPatch,
+		"/packs/"+packID,
+		bytes.NewBuffer([]byte(`{"status": "`+status.String()+`"}`)),
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func cancelPack(t *testing.T, packID string) {
+	resp, err := clientApp(httptest.NewRequest(http.MethodPost, "/packs/"+packID+"/cancel", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}